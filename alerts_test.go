@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	alertcontroller "github.com/SmMistry/triumph-project/controllers/alerts"
+	"github.com/SmMistry/triumph-project/services/alerts"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver is a local webhook answering with the queued statuses,
+// then 200s, and recording every delivery
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	statuses   []int
+	deliveries []*http.Request
+	bodies     [][]byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	receiver := &webhookReceiver{statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		receiver.deliveries = append(receiver.deliveries, r)
+		receiver.bodies = append(receiver.bodies, body)
+		status := http.StatusOK
+		if len(receiver.statuses) > 0 {
+			status, receiver.statuses = receiver.statuses[0], receiver.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+// count returns how many deliveries were received
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.deliveries)
+}
+
+func openAlertStore(t *testing.T) *alerts.Store {
+	store, err := alerts.Open(filepath.Join(t.TempDir(), "alerts.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestAlertEngine(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 61000, SellPrice: 60990}
+	store := openAlertStore(t)
+	notifier := alerts.NewNotifier(store, 3, time.Millisecond, time.Second)
+	notifier.SetAllowPrivateNetworks(true)
+	engine := alerts.NewEngine(order.NewOrderService(coinbase), store, notifier, time.Hour)
+
+	alert, secret, err := store.Create("", alerts.Condition{
+		Symbol: "BTC", Metric: alerts.MetricBestAsk, Operator: alerts.OperatorBelow, Threshold: 60000,
+	}, receiver.URL)
+	require.NoError(t, err)
+
+	// Nothing is sent while the condition doesn't hold
+	engine.Evaluate(context.Background())
+	notifier.Wait()
+	assert.Equal(t, 0, receiver.count())
+
+	coinbase.BuyPrice = 59000
+	engine.Evaluate(context.Background())
+	notifier.Wait()
+	require.Equal(t, 1, receiver.count())
+
+	// Deliveries are signed with the alert's secret
+	delivery, body := receiver.deliveries[0], receiver.bodies[0]
+	assert.Equal(t, "application/json", delivery.Header.Get("Content-Type"))
+	assert.Equal(t, alerts.Sign(secret, delivery.Header.Get(alerts.TimestampHeader), body), delivery.Header.Get(alerts.SignatureHeader))
+	assert.NotEqual(t, alerts.Sign("wrong", delivery.Header.Get(alerts.TimestampHeader), body), delivery.Header.Get(alerts.SignatureHeader))
+
+	var event alerts.Event
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, delivery.Header.Get(alerts.EventIDHeader), event.ID)
+	assert.Equal(t, alerts.EventTriggered, event.Type)
+	assert.Equal(t, alert.ID, event.AlertID)
+	assert.Equal(t, 59000.0, event.Value)
+	assert.Equal(t, 59000.0, event.Market.BestAsk.Price)
+
+	// The alert doesn't trigger again until the condition stops holding
+	engine.Evaluate(context.Background())
+	notifier.Wait()
+	assert.Equal(t, 1, receiver.count())
+
+	alert, err = store.Get("", alert.ID)
+	require.NoError(t, err)
+	assert.True(t, alert.Holding)
+	assert.NotNil(t, alert.TriggeredAt)
+
+	coinbase.BuyPrice = 61000
+	engine.Evaluate(context.Background())
+	coinbase.BuyPrice = 59500
+	engine.Evaluate(context.Background())
+	notifier.Wait()
+	assert.Equal(t, 2, receiver.count())
+}
+
+func TestAlertArbitrageCondition(t *testing.T) {
+	market := &order.Market{Venues: []order.VenueMarket{
+		{Exchange: "coinbase", Bid: 9990, Ask: 10000},
+		{Exchange: "kraken", Bid: 10040, Ask: 10050},
+		{Exchange: "binance", Error: "timeout"},
+	}}
+	condition := alerts.Condition{
+		Symbol: "BTC", Metric: alerts.MetricArbitrageBps, Operator: alerts.OperatorAbove, Threshold: 30,
+		Venues: []string{"kraken", "coinbase"},
+	}
+	assert.NoError(t, condition.Validate())
+
+	// Buying on Coinbase and selling on Kraken earns 40 bps
+	value, holding := condition.Evaluate(market)
+	assert.InDelta(t, 40, value, 1e-9)
+	assert.True(t, holding)
+
+	// Venues that didn't answer are left out
+	condition.Venues = []string{"coinbase", "binance"}
+	_, holding = condition.Evaluate(market)
+	assert.False(t, holding)
+
+	for _, invalid := range []alerts.Condition{
+		{Metric: alerts.MetricBestAsk, Operator: alerts.OperatorBelow},
+		{Symbol: "BTC", Metric: "volume", Operator: alerts.OperatorBelow},
+		{Symbol: "BTC", Metric: alerts.MetricBestAsk, Operator: "equals"},
+		{Symbol: "BTC", Metric: alerts.MetricBestAsk, Operator: alerts.OperatorBelow, Venues: []string{"kraken", "coinbase"}},
+		{Symbol: "BTC", Metric: alerts.MetricArbitrageBps, Operator: alerts.OperatorAbove, Venues: []string{"kraken"}},
+	} {
+		assert.ErrorIs(t, invalid.Validate(), alerts.ErrInvalidCondition)
+	}
+}
+
+func TestAlertDeliveryRetries(t *testing.T) {
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 59000, SellPrice: 58990}
+	condition := alerts.Condition{Symbol: "BTC", Metric: alerts.MetricBestAsk, Operator: alerts.OperatorBelow, Threshold: 60000}
+
+	tests := []struct {
+		name               string
+		statuses           []int
+		expectedDeliveries int
+		expectedDeadLetter bool
+	}{
+		{"Retried until accepted", []int{500, 429}, 3, false},
+		{"Dead lettered after every attempt fails", []int{500, 502, 503}, 3, true},
+		{"Dead lettered without retrying client errors", []int{404}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newWebhookReceiver(t, tt.statuses...)
+			store := openAlertStore(t)
+			notifier := alerts.NewNotifier(store, 3, time.Millisecond, time.Second)
+			notifier.SetAllowPrivateNetworks(true)
+			engine := alerts.NewEngine(order.NewOrderService(coinbase), store, notifier, time.Hour)
+
+			_, _, err := store.Create("", condition, receiver.URL)
+			require.NoError(t, err)
+			engine.Evaluate(context.Background())
+			notifier.Wait()
+			assert.Equal(t, tt.expectedDeliveries, receiver.count())
+
+			letters, err := store.DeadLetters("")
+			require.NoError(t, err)
+			if !tt.expectedDeadLetter {
+				assert.Empty(t, letters)
+				return
+			}
+			require.Len(t, letters, 1)
+			assert.Equal(t, tt.expectedDeliveries, letters[0].Attempts)
+			assert.Contains(t, letters[0].Error, "webhook responded")
+
+			// Every attempt carries the same event ID
+			assert.Equal(t, letters[0].ID, receiver.deliveries[0].Header.Get(alerts.EventIDHeader))
+
+			// Redelivering once the webhook recovers clears the dead letter
+			_, err = notifier.Redeliver(context.Background(), "", letters[0].ID)
+			assert.NoError(t, err)
+			letters, err = store.DeadLetters("")
+			require.NoError(t, err)
+			assert.Empty(t, letters)
+		})
+	}
+}
+
+func TestAlertDeliveryPrivateAddress(t *testing.T) {
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 59000, SellPrice: 58990}
+	condition := alerts.Condition{Symbol: "BTC", Metric: alerts.MetricBestAsk, Operator: alerts.OperatorBelow, Threshold: 60000}
+	receiver := newWebhookReceiver(t)
+	store := openAlertStore(t)
+	notifier := alerts.NewNotifier(store, 3, time.Millisecond, time.Second)
+	engine := alerts.NewEngine(order.NewOrderService(coinbase), store, notifier, time.Hour)
+
+	// Loopback and link local webhooks are dead lettered without retrying
+	for _, url := range []string{receiver.URL, "http://169.254.169.254/latest/meta-data"} {
+		_, _, err := store.Create("", condition, url)
+		require.NoError(t, err)
+	}
+	engine.Evaluate(context.Background())
+	notifier.Wait()
+	assert.Zero(t, receiver.count())
+
+	letters, err := store.DeadLetters("")
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	for _, letter := range letters {
+		assert.Equal(t, 1, letter.Attempts)
+		assert.Contains(t, letter.Error, alerts.ErrPrivateAddress.Error())
+	}
+}
+
+func TestAlertHandlers(t *testing.T) {
+	store := openAlertStore(t)
+	store.SetOrderService(order.NewOrderService(&MockExchange{Name: "coinbase"}))
+	alertController := alertcontroller.NewAlertController(store, alerts.NewNotifier(store, 1, 0, time.Second))
+
+	app := fiber.New()
+	app.Post("/v1/alerts", alertController.CreateHandler)
+	app.Get("/v1/alerts", alertController.ListHandler)
+	app.Get("/v1/alerts/dead-letters", alertController.DeadLettersHandler)
+	app.Get("/v1/alerts/:id", alertController.GetHandler)
+	app.Put("/v1/alerts/:id", alertController.UpdateHandler)
+	app.Delete("/v1/alerts/:id", alertController.DeleteHandler)
+
+	send := func(method, path, body string) (int, map[string]json.RawMessage) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		response := map[string]json.RawMessage{}
+		data, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(data, &response)
+		return resp.StatusCode, response
+	}
+
+	status, response := send(http.MethodPost, "/v1/alerts", `{"symbol":"eth-usd","metric":"spreadBps","operator":"above","threshold":20,"webhookUrl":"https://example.com/hook"}`)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Contains(t, string(response["secret"]), "whsec_")
+
+	var alert alerts.Alert
+	require.NoError(t, json.Unmarshal(response["alert"], &alert))
+	assert.Equal(t, "ETH", alert.Symbol)
+	assert.Equal(t, "https://example.com/hook", alert.WebhookURL)
+
+	// The secret is never returned again
+	status, response = send(http.MethodGet, "/v1/alerts/"+alert.ID, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotContains(t, string(response["alert"]), "secret")
+
+	status, response = send(http.MethodGet, "/v1/alerts", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, string(response["alerts"]), alert.ID)
+
+	status, response = send(http.MethodPut, "/v1/alerts/"+alert.ID, `{"symbol":"ETH","metric":"spreadBps","operator":"above","threshold":25,"webhookUrl":"https://example.com/hook"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, string(response["alert"]), `"threshold":25`)
+
+	// Invalid conditions and webhooks are rejected
+	for _, body := range []string{
+		`{"symbol":"ETH","metric":"volume","operator":"above","webhookUrl":"https://example.com/hook"}`,
+		`{"symbol":"ETH","metric":"spreadBps","operator":"above","webhookUrl":"ftp://example.com/hook"}`,
+		`{"symbol":"ETH","metric":"spreadBps","operator":"above"}`,
+		`{"symbol":"E TH!","metric":"spreadBps","operator":"above","webhookUrl":"https://example.com/hook"}`,
+	} {
+		status, _ = send(http.MethodPost, "/v1/alerts", body)
+		assert.Equal(t, http.StatusBadRequest, status, body)
+	}
+
+	status, response = send(http.MethodGet, "/v1/alerts/dead-letters", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `[]`, string(response["deadLetters"]))
+
+	status, _ = send(http.MethodDelete, "/v1/alerts/"+alert.ID, "")
+	assert.Equal(t, http.StatusNoContent, status)
+	status, _ = send(http.MethodGet, "/v1/alerts/"+alert.ID, "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/arbitrage"
+	arbitrageservice "github.com/SmMistry/triumph-project/services/arbitrage"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockFeeExchange is a mock book exchange that also charges a taker fee
+type MockFeeExchange struct {
+	MockBookExchange
+	Fee float64
+}
+
+func (m *MockFeeExchange) GetTakerFee() float64 {
+	return m.Fee
+}
+
+func TestArbitrageScanner(t *testing.T) {
+	// Kraken's bid is above Coinbase's ask by 100 bps, enough to cover 0.2%
+	// fees on each side for one and a half coins
+	coinbase := &MockFeeExchange{Fee: 0.002, MockBookExchange: MockBookExchange{
+		Name: "coinbase",
+		Book: exchange.OrderBook{
+			Bids: []exchange.Level{{Price: 9990, Size: 1}},
+			Asks: []exchange.Level{{Price: 10000, Size: 1}, {Price: 10050, Size: 1}, {Price: 10100, Size: 5}},
+		},
+	}}
+	kraken := &MockFeeExchange{Fee: 0.002, MockBookExchange: MockBookExchange{
+		Name: "kraken",
+		Book: exchange.OrderBook{
+			Bids: []exchange.Level{{Price: 10100, Size: 1.5}, {Price: 10080, Size: 5}},
+			Asks: []exchange.Level{{Price: 10110, Size: 1}},
+		},
+	}}
+
+	scanner := arbitrageservice.NewScanner(order.NewOrderService(coinbase, kraken), []string{"BTC"}, 0, 0)
+	events, unsubscribe := scanner.Subscribe()
+	defer unsubscribe()
+
+	scanner.Scan(context.Background())
+
+	opps := scanner.Opportunities()
+	if assert.Len(t, opps, 1) {
+		opp := opps[0]
+		assert.Equal(t, "coinbase", opp.BuyExchange)
+		assert.Equal(t, "kraken", opp.SellExchange)
+		assert.InDelta(t, 59.68, opp.EdgeBps, 0.01)
+		assert.InDelta(t, 1.5, opp.Size, 1e-9)
+		assert.Greater(t, opp.ProfitUSD, 0.0)
+	}
+	assert.Equal(t, arbitrageservice.EventOpened, (<-events).Type)
+
+	// The edge closes once Kraken's bid drops
+	kraken.Book.Bids = []exchange.Level{{Price: 10000, Size: 1}}
+	scanner.Scan(context.Background())
+	assert.Empty(t, scanner.Opportunities())
+	assert.Equal(t, arbitrageservice.EventClosed, (<-events).Type)
+}
+
+func TestArbitrageListHandler(t *testing.T) {
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990}
+	kraken := &MockExchange{Name: "kraken", BuyPrice: 10110, SellPrice: 10100}
+
+	scanner := arbitrageservice.NewScanner(order.NewOrderService(coinbase, kraken), []string{"BTC"}, 0, 0)
+	scanner.Scan(context.Background())
+
+	app := fiber.New()
+	arbitrageController := arbitrage.NewArbitrageController(scanner)
+	app.Get("/v1/arbitrage", arbitrageController.ListHandler)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/arbitrage", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	var response struct {
+		Opportunities []arbitrageservice.Opportunity `json:"opportunities"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &response))
+	if assert.Len(t, response.Opportunities, 1) {
+		// Without fees or depth the edge is the raw price difference and
+		// the executable size is unknown
+		assert.Equal(t, "BTC", response.Opportunities[0].Symbol)
+		assert.Equal(t, 100.0, response.Opportunities[0].EdgeBps)
+		assert.Equal(t, 0.0, response.Opportunities[0].Size)
+	}
+}
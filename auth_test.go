@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/config"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	store, err := auth.Open(filepath.Join(t.TempDir(), "keys.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, quoteToken, err := store.Create("quotes", []auth.Scope{auth.ScopeQuote}, 2, 0)
+	require.NoError(t, err)
+	_, quotaToken, err := store.Create("quota", []auth.Scope{auth.ScopeQuote}, 0, 1)
+	require.NoError(t, err)
+	adminKey, adminToken, err := store.Create("admin", []auth.Scope{auth.ScopeAdmin}, 0, 0)
+	require.NoError(t, err)
+
+	orderService := order.NewOrderService(&MockExchange{Name: "kraken", BuyPrice: 10005, SellPrice: 9985})
+	orderController := orders.NewOrderController(orderService)
+	authenticator := auth.NewAuthenticator(store)
+
+	app := fiber.New()
+	app.Get("/buy", authenticator.Require(auth.ScopeQuote), orderController.BuyHandler)
+	app.Get("/v1/orders/:id", authenticator.Require(auth.ScopeTrade), orderController.GetOrderHandler)
+	app.Get("/v1/stream", authenticator.RequireStream(auth.ScopeQuote), func(c *fiber.Ctx) error {
+		return c.SendString("streaming")
+	})
+
+	request := func(path, token string) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := request("/buy?symbol=BTC&amount=1", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.JSONEq(t, `{"error":"missing API key","code":"missing_api_key"}`, body)
+
+	resp, body = request("/buy?symbol=BTC&amount=1", quoteToken+"x")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.JSONEq(t, `{"error":"invalid API key","code":"invalid_api_key"}`, body)
+
+	// Scopes are enforced before the controller runs
+	resp, body = request("/v1/orders/123", quoteToken)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.JSONEq(t, `{"error":"API key lacks the trade scope","code":"insufficient_scope"}`, body)
+
+	// The rate limit allows two requests per minute
+	resp, _ = request("/buy?symbol=BTC&amount=1", quoteToken)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", resp.Header.Get("X-RateLimit-Remaining"))
+	resp, _ = request("/buy?symbol=BTC&amount=1", quoteToken)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+
+	resp, body = request("/buy?symbol=BTC&amount=1", quoteToken)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.JSONEq(t, `{"error":"rate limit exceeded","code":"rate_limited"}`, body)
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Truncate(time.Minute).Add(time.Minute), time.Unix(reset, 0), time.Second)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	// The daily quota allows a single request, sent as X-API-Key this time
+	req := httptest.NewRequest(http.MethodGet, "/buy?symbol=BTC&amount=1", nil)
+	req.Header.Set(auth.KeyHeader, quotaToken)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("X-Quota-Remaining"))
+
+	resp, body = request("/buy?symbol=BTC&amount=1", quotaToken)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.JSONEq(t, `{"error":"daily quota exceeded","code":"quota_exceeded"}`, body)
+	assert.Equal(t, "1", resp.Header.Get("X-Quota-Limit"))
+	now := time.Now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, strconv.FormatInt(midnight.Unix(), 10), resp.Header.Get("X-Quota-Reset"))
+
+	// Streams take the key as a query parameter too, other endpoints don't
+	resp, body = request("/v1/stream?"+auth.KeyQuery+"="+adminToken, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "streaming", body)
+	resp, _ = request("/buy?symbol=BTC&amount=1&"+auth.KeyQuery+"="+adminToken, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Admin keys have every scope, until revoked
+	resp, _ = request("/v1/orders/123", adminToken)
+	assert.NotEqual(t, http.StatusForbidden, resp.StatusCode)
+
+	_, err = store.Revoke(adminKey.ID)
+	require.NoError(t, err)
+	resp, _ = request("/v1/orders/123", adminToken)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestQuotaUsage(t *testing.T) {
+	store, err := auth.Open(filepath.Join(t.TempDir(), "keys.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	unlimited, _, err := store.Create("unlimited", []auth.Scope{auth.ScopeQuote}, 0, 0)
+	require.NoError(t, err)
+	limited, _, err := store.Create("limited", []auth.Scope{auth.ScopeQuote}, 0, 1)
+	require.NoError(t, err)
+
+	// Keys without a quota aren't counted
+	day := time.Date(2024, 11, 8, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		usage, err := store.Consume(unlimited, day)
+		require.NoError(t, err)
+		assert.Equal(t, 0, usage.Used)
+	}
+
+	// The quota starts over every day
+	usage, err := store.Consume(limited, day)
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Used)
+	_, err = store.Consume(limited, day)
+	assert.ErrorIs(t, err, auth.ErrQuotaExceeded)
+
+	next := day.Add(24 * time.Hour)
+	usage, err = store.Consume(limited, next)
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Used)
+	assert.Equal(t, time.Date(2024, 11, 10, 0, 0, 0, 0, time.UTC), usage.Reset)
+
+	// Counts of previous days are dropped, so going back starts from zero
+	usage, err = store.Consume(limited, day)
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Used)
+}
+
+func TestKeysCommand(t *testing.T) {
+	cfg := config.Default()
+	cfg.Storage.Keys = filepath.Join(t.TempDir(), "keys.db")
+
+	var out bytes.Buffer
+	require.NoError(t, runKeys(cfg, []string{"create", "--name", "acme", "--scopes", "quote,trade", "--rate-limit", "60"}, &out))
+	token := regexp.MustCompile(`tri_\S+`).FindString(out.String())
+	id := regexp.MustCompile(`created key (\w+) for acme`).FindStringSubmatch(out.String())
+	require.NotEmpty(t, token)
+	require.Len(t, id, 2)
+
+	out.Reset()
+	require.NoError(t, runKeys(cfg, []string{"list"}, &out))
+	assert.Regexp(t, id[1]+`\s+acme\s+quote,trade\s+60\s+unlimited\s+.*active`, out.String())
+
+	out.Reset()
+	require.NoError(t, runKeys(cfg, []string{"revoke", id[1]}, &out))
+	assert.Equal(t, "revoked key "+id[1]+" for acme\n", out.String())
+
+	assert.EqualError(t, runKeys(cfg, []string{"create", "--name", "x", "--scopes", "root"}, io.Discard), `invalid scope "root"`)
+
+	// Revoked keys no longer authenticate
+	store, err := auth.Open(cfg.Storage.Keys)
+	require.NoError(t, err)
+	defer store.Close()
+	_, err = store.Authenticate(token)
+	assert.ErrorIs(t, err, auth.ErrInvalidKey)
+}
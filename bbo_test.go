@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/stream"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBBOPoller(t *testing.T) {
+	coinbase := &MockCountingExchange{MockExchange: MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990}}
+	kraken := &MockCountingExchange{MockExchange: MockExchange{Name: "kraken", BuyPrice: 10010, SellPrice: 9995}}
+	poller := bbo.NewPoller(order.NewOrderService(coinbase, kraken), time.Second)
+
+	// Nothing is polled until someone subscribes
+	poller.Poll(context.Background())
+	assert.Equal(t, 0, coinbase.Calls)
+
+	first, unsubscribeFirst := poller.Subscribe("BTC")
+	second, unsubscribeSecond := poller.Subscribe("BTC")
+	assert.Equal(t, []string{"BTC"}, poller.Symbols())
+
+	// Both subscribers share a single poll
+	poller.Poll(context.Background())
+	assert.Equal(t, 1, coinbase.Calls)
+	for _, events := range []<-chan bbo.Event{first, second} {
+		event := <-events
+		assert.Equal(t, uint64(1), event.Sequence)
+		assert.Equal(t, "BTC", event.Symbol)
+		assert.Equal(t, order.BestPrice{Price: 10000, Exchanges: []string{"coinbase"}}, event.BestAsk)
+		assert.Equal(t, order.BestPrice{Price: 9995, Exchanges: []string{"kraken"}}, event.BestBid)
+		assert.Len(t, event.Venues, 2)
+	}
+
+	// Unchanged prices aren't published again
+	poller.Poll(context.Background())
+	assert.Empty(t, first)
+
+	// A moved best ask is
+	coinbase.BuyPrice = 10005
+	poller.Poll(context.Background())
+	event := <-first
+	assert.Equal(t, uint64(2), event.Sequence)
+	assert.Equal(t, 10005.0, event.BestAsk.Price)
+
+	// Late subscribers get the latest event straight away
+	late, unsubscribeLate := poller.Subscribe("BTC")
+	assert.Equal(t, uint64(2), (<-late).Sequence)
+
+	// Symbols stop being polled once nobody listens
+	unsubscribeFirst()
+	unsubscribeSecond()
+	unsubscribeLate()
+	assert.Empty(t, poller.Symbols())
+}
+
+func TestBBOStreamHandler(t *testing.T) {
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990}
+	orderService := order.NewOrderService(coinbase)
+	poller := bbo.NewPoller(orderService, time.Hour)
+
+	app := fiber.New()
+	streamController := stream.NewStreamController(orderService, poller, 2)
+	app.Get("/v1/stream/bbo", streamController.BBOHandler)
+
+	for query, code := range map[string]string{
+		"":                     "invalid_symbol",
+		"?symbols=,":           "invalid_symbol",
+		"?symbols=BTC,B%20T!C": "invalid_symbol",
+		"?symbols=BTC,ETH,SOL": "too_many_symbols",
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/stream/bbo"+query, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `"code":"`+code+`"`, query)
+	}
+
+	// Keep BTC polled so the stream starts with its latest event, then stop
+	// the poller to end the stream
+	_, unsubscribe := poller.Subscribe("BTC")
+	defer unsubscribe()
+	poller.Poll(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	go poller.Run(ctx)
+
+	// Symbols are normalized, so these are all BTC
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/stream/bbo?symbols=btc,BTC-USD,%20BTC", nil), 2000)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(body), "event: bbo\nid: BTC:1\ndata: {\"sequence\":1,"), string(body))
+}
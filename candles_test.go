@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	candlecontroller "github.com/SmMistry/triumph-project/controllers/candles"
+	"github.com/SmMistry/triumph-project/services/candles"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCandles(t *testing.T) {
+	store, err := candles.Open(filepath.Join(t.TempDir(), "candles.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	coinbase := &MockExchange{Name: "coinbase"}
+	kraken := &MockExchange{Name: "kraken"}
+	orderService := order.NewOrderService(coinbase, kraken)
+	recorder := candles.NewRecorder(orderService, store, []string{"BTC"}, time.Minute)
+
+	// Four samples over two minutes, Kraken always quotes 10 higher
+	start := time.Date(2024, 11, 8, 15, 0, 0, 0, time.UTC)
+	for i, mid := range []float64{100, 120, 90, 110} {
+		coinbase.BuyPrice, coinbase.SellPrice = mid+1, mid-1
+		kraken.BuyPrice, kraken.SellPrice = mid+11, mid+9
+		recorder.Sample(context.Background(), start.Add(time.Duration(i)*30*time.Second))
+	}
+
+	app := fiber.New()
+	candleController := candlecontroller.NewCandleController(orderService, store)
+	app.Get("/v1/candles/:symbol", candleController.CandlesHandler)
+
+	get := func(url string) (int, string) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, url, nil))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	// The aggregated mid is between Kraken's bid and Coinbase's ask, symbols
+	// are normalized
+	status, body := get("/v1/candles/btc-usd")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"symbol":"BTC","venue":"all","interval":"1m","candles":[
+		{"time":"2024-11-08T15:00:00Z","open":105,"high":125,"low":105,"close":125,"samples":2},
+		{"time":"2024-11-08T15:01:00Z","open":95,"high":115,"low":95,"close":115,"samples":2}
+	]}`, body)
+
+	status, body = get("/v1/candles/BTC?venue=kraken&interval=5m")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"symbol":"BTC","venue":"kraken","interval":"5m","candles":[
+		{"time":"2024-11-08T15:00:00Z","open":110,"high":130,"low":100,"close":120,"samples":4}
+	]}`, body)
+
+	status, body = get("/v1/candles/BTC?venue=coinbase&from=2024-11-08T15:01:00Z")
+	assert.Equal(t, http.StatusOK, status)
+	var response struct {
+		Candles []candles.Candle `json:"candles"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &response))
+	if assert.Len(t, response.Candles, 1) {
+		assert.Equal(t, 90.0, response.Candles[0].Open)
+	}
+
+	status, body = get("/v1/candles/BTC?interval=2m")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `"code":"invalid_interval"`)
+
+	status, body = get("/v1/candles/BTC?to=tomorrow")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `"code":"invalid_time"`)
+
+	status, body = get("/v1/candles/B%21TC")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `"code":"invalid_symbol"`)
+
+	// Samples past their retention are pruned, candles are kept
+	recorder.SetRetention(time.Hour)
+	deleted, err := store.Prune(start.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	recorder.Prune(context.Background(), start.Add(time.Hour+2*time.Minute))
+	deleted, err = store.Prune(start.Add(2 * time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted, "the recorder pruned the rest")
+
+	status, body = get("/v1/candles/BTC")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"samples":2`)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/markets"
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/cli"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while a command writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// runCLI runs the triumph command line and returns its exit code and output
+func runCLI(ctx context.Context, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := cli.Run(ctx, args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLILocal(t *testing.T) {
+	// Coinbase answers, Kraken fails every request
+	venues := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/products/BTC-USD/book":
+			w.Write([]byte(`{"bids":[["9990","1"]],"asks":[["10000","2"]]}`))
+		case "/products/BTC-USD":
+			w.Write([]byte(`{"id":"BTC-USD","base_increment":"0.01"}`))
+		default:
+			w.Write([]byte(`{"error":["EService:Unavailable"]}`))
+		}
+	}))
+	defer venues.Close()
+
+	t.Setenv("COINBASE_MARKET_URL", venues.URL)
+	t.Setenv("KRAKEN_MARKET_URL", venues.URL)
+	t.Setenv("STREAM_INTERVAL", "10ms")
+	ctx := context.Background()
+
+	code, stdout, stderr := runCLI(ctx, "-o", "json", "quote", "buy", "btc", "1.234")
+	require.Equal(t, 0, code, stderr)
+	var quote cli.Quote
+	require.NoError(t, json.Unmarshal([]byte(stdout), &quote))
+	assert.Equal(t, cli.Quote{Side: order.SideBuy, Symbol: "BTC", Amount: 1.23, Price: 10000, USDAmount: 12300, Exchanges: []string{"coinbase"}}, quote)
+
+	code, stdout, stderr = runCLI(ctx, "market", "BTC")
+	require.Equal(t, 0, code, stderr)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"EXCHANGE", "BID", "ASK", "MID", "SPREAD", "BPS", "ERROR"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"coinbase", "9990", "10000", "9995", "10.005002501250624"}, strings.Fields(lines[1]))
+	assert.Equal(t, "kraken", strings.Fields(lines[2])[0])
+	assert.Equal(t, []string{"best", "9990", "10000", "9995", "10.005002501250624"}, strings.Fields(lines[3]))
+
+	// A venue being down fails the command so scripts can check it
+	code, stdout, stderr = runCLI(ctx, "-o", "csv", "venues", "status")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "EXCHANGE,STATUS,ERROR\ncoinbase,up,\nkraken,down,Kraken price fetch failed with errors: EService:Unavailable\n", stdout)
+	assert.Equal(t, "triumph: venues down: 1 of 2\n", stderr)
+
+	// Watching prints a row per change until interrupted
+	ctx, cancel := context.WithCancel(ctx)
+	var out syncBuffer
+	done := make(chan int)
+	go func() { done <- cli.Run(ctx, []string{"-o", "csv", "watch", "BTC"}, &out, &out) }()
+	require.Eventually(t, func() bool { return strings.Count(out.String(), "\n") >= 2 }, 2*time.Second, 10*time.Millisecond)
+	cancel()
+	assert.Equal(t, 0, <-done)
+
+	lines = strings.Split(out.String(), "\n")
+	assert.Equal(t, "TIME,SYMBOL,SEQUENCE,BID,BID EXCHANGES,ASK,ASK EXCHANGES,SPREAD BPS", lines[0])
+	assert.Contains(t, lines[1], ",BTC,1,9990,coinbase,10000,coinbase,")
+}
+
+func TestCLIRemote(t *testing.T) {
+	orderService := order.NewOrderService(
+		&MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990},
+		&MockExchange{Name: "kraken", BuyPrice: 10010, SellPrice: 9995},
+	)
+	orderController := orders.NewOrderController(orderService)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get("X-API-Key") != "secret" {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "invalid API key"})
+		}
+		return c.Next()
+	})
+	app.Get("/buy", orderController.BuyHandler)
+	app.Get("/sell", orderController.SellHandler)
+	app.Get("/v1/markets/:symbol", markets.NewMarketController(orderService).MarketHandler)
+
+	server := httptest.NewServer(adaptor.FiberApp(app))
+	defer server.Close()
+	ctx := context.Background()
+
+	code, stdout, stderr := runCLI(ctx, "-server", server.URL, "-api-key", "secret", "quote", "sell", "BTC", "2")
+	require.Equal(t, 0, code, stderr)
+	fields := strings.Fields(strings.Split(stdout, "\n")[1])
+	assert.Equal(t, []string{"sell", "BTC", "2", "9995", "19990", "kraken"}, fields)
+
+	code, stdout, stderr = runCLI(ctx, "-server", server.URL, "-api-key", "secret", "-o", "json", "market", "eth")
+	require.Equal(t, 0, code, stderr)
+	var market order.Market
+	require.NoError(t, json.Unmarshal([]byte(stdout), &market))
+	assert.Equal(t, "ETH", market.Symbol)
+	assert.Equal(t, 9995.0, market.BestBid.Price)
+
+	// Server errors are reported with their code
+	code, _, stderr = runCLI(ctx, "-server", server.URL, "-api-key", "secret", "quote", "buy", "B.C", "1")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "triumph: invalid symbol: \"B.C\" must be 1 to 16 letters or digits (invalid_symbol)\n", stderr)
+	code, _, stderr = runCLI(ctx, "-server", server.URL, "market", "BTC")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "triumph: invalid API key\n", stderr)
+}
+
+func TestCLIRemoteWatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "BTC,ETH", r.URL.Query().Get("symbols"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i, symbol := range []string{"BTC", "ETH"} {
+			fmt.Fprintf(w, ": ping\n\nevent: bbo\nid: %s:1\ndata: {\"sequence\":%d,\"time\":\"2024-11-08T12:00:01Z\",\"symbol\":%q,\"bestBid\":{\"price\":99,\"exchanges\":[\"kraken\"]},\"bestAsk\":{\"price\":100,\"exchanges\":[\"coinbase\"]}}\n\n", symbol, i+1, symbol)
+		}
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(context.Background(), "-server", server.URL, "-o", "json", "watch", "BTC", "ETH")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "triumph: stream closed by the server\n", stderr)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"symbol":"BTC"`)
+	assert.Contains(t, lines[1], `"sequence":2`)
+}
+
+func TestCLIUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"trade", "BTC"},
+		{"quote", "hold", "BTC", "1"},
+		{"quote", "buy", "BTC", "lots"},
+		{"venues"},
+		{"-o", "xml", "market", "BTC"},
+	} {
+		code, stdout, stderr := runCLI(context.Background(), args...)
+		assert.Equal(t, 2, code, args)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "Usage: triumph", args)
+	}
+}
@@ -0,0 +1,20 @@
+// Command triumph quotes and watches markets from the command line, either
+// straight from the exchanges or through a running server. Run it with -h
+// for the commands and flags
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/SmMistry/triumph-project/services/cli"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,104 @@
+package accounts
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/SmMistry/triumph-project/services/history"
+	"github.com/SmMistry/triumph-project/services/ledger"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/gofiber/fiber/v2"
+)
+
+// validID matches the account IDs the ledger accepts
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// AccountController handles HTTP requests for paper trading accounts
+type AccountController struct {
+	ledger *ledger.Ledger
+}
+
+// NewAccountController creates a new AccountController with the given Ledger
+func NewAccountController(ledger *ledger.Ledger) *AccountController {
+	return &AccountController{ledger: ledger}
+}
+
+// AccountHandler handles the GET /v1/accounts/:id endpoint
+func (ac *AccountController) AccountHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validID.MatchString(id) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid account id"})
+	}
+
+	account, err := ac.ledger.Account(c.UserContext(), id)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(account)
+}
+
+// DepositHandler handles the POST /v1/accounts/:id/deposits endpoint
+func (ac *AccountController) DepositHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validID.MatchString(id) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid account id"})
+	}
+
+	var body struct {
+		Asset  string  `json:"asset"`
+		Amount float64 `json:"amount"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Asset == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid deposit"})
+	}
+
+	txn, err := ac.ledger.Deposit(id, strings.ToUpper(body.Asset), body.Amount)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(http.StatusCreated).JSON(txn)
+}
+
+// FillHandler handles the POST /v1/accounts/:id/fills endpoint, accepting a
+// quote and simulating its fill
+func (ac *AccountController) FillHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validID.MatchString(id) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid account id"})
+	}
+
+	var body struct {
+		QuoteID string `json:"quoteId"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.QuoteID == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid quoteId"})
+	}
+
+	fill, err := ac.ledger.Accept(c.UserContext(), id, body.QuoteID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fill)
+}
+
+// errorStatus maps a Ledger error to an HTTP status code
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ledger.ErrInvalidAmount):
+		return http.StatusBadRequest
+	case errors.Is(err, history.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ledger.ErrQuoteExpired), errors.Is(err, ledger.ErrQuoteAccepted):
+		return http.StatusConflict
+	case errors.Is(err, ledger.ErrInsufficientFunds), errors.Is(err, ledger.ErrQuoteFailed),
+		errors.Is(err, order.ErrInsufficientLiquidity):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
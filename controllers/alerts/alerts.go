@@ -0,0 +1,145 @@
+package alerts
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SmMistry/triumph-project/services/alerts"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AlertController handles HTTP requests for managing price alerts. Alerts
+// belong to the API key that created them
+type AlertController struct {
+	store    *alerts.Store
+	notifier *alerts.Notifier
+}
+
+// NewAlertController creates a new AlertController with the given Store,
+// redelivering dead letters through notifier
+func NewAlertController(store *alerts.Store, notifier *alerts.Notifier) *AlertController {
+	return &AlertController{store: store, notifier: notifier}
+}
+
+// alertBody is the body of the create and update endpoints
+type alertBody struct {
+	alerts.Condition
+	WebhookURL string `json:"webhookUrl"`
+}
+
+// CreateHandler handles the POST /v1/alerts endpoint, the webhook secret is
+// only ever returned here
+func (ac *AlertController) CreateHandler(c *fiber.Ctx) error {
+	var body alertBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid alert"})
+	}
+
+	alert, secret, err := ac.store.Create(owner(c), body.Condition, body.WebhookURL)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"alert": alert, "secret": secret})
+}
+
+// ListHandler handles the GET /v1/alerts endpoint
+func (ac *AlertController) ListHandler(c *fiber.Ctx) error {
+	list, err := ac.store.List(owner(c))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"alerts": list})
+}
+
+// GetHandler handles the GET /v1/alerts/:id endpoint
+func (ac *AlertController) GetHandler(c *fiber.Ctx) error {
+	alert, err := ac.store.Get(owner(c), c.Params("id"))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"alert": alert})
+}
+
+// UpdateHandler handles the PUT /v1/alerts/:id endpoint
+func (ac *AlertController) UpdateHandler(c *fiber.Ctx) error {
+	var body alertBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid alert"})
+	}
+
+	alert, err := ac.store.Update(owner(c), c.Params("id"), body.Condition, body.WebhookURL)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"alert": alert})
+}
+
+// DeleteHandler handles the DELETE /v1/alerts/:id endpoint
+func (ac *AlertController) DeleteHandler(c *fiber.Ctx) error {
+	if err := ac.store.Delete(owner(c), c.Params("id")); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// DeadLettersHandler handles the GET /v1/alerts/dead-letters endpoint
+func (ac *AlertController) DeadLettersHandler(c *fiber.Ctx) error {
+	letters, err := ac.store.DeadLetters(owner(c))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"deadLetters": letters})
+}
+
+// RedeliverHandler handles the POST /v1/alerts/dead-letters/:id/redeliver
+// endpoint, making one more attempt at a failed delivery
+func (ac *AlertController) RedeliverHandler(c *fiber.Ctx) error {
+	letter, err := ac.notifier.Redeliver(c.UserContext(), owner(c), c.Params("id"))
+	if letter != nil && err != nil {
+		// The webhook still fails, the dead letter is kept
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": err.Error(), "deadLetter": letter})
+	}
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"delivered": letter})
+}
+
+// DeleteDeadLetterHandler handles the DELETE /v1/alerts/dead-letters/:id
+// endpoint
+func (ac *AlertController) DeleteDeadLetterHandler(c *fiber.Ctx) error {
+	if err := ac.store.DeleteDeadLetter(owner(c), c.Params("id")); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// owner returns the ID of the key the request was authenticated with, or
+// nothing when authentication is disabled
+func owner(c *fiber.Ctx) string {
+	if key := auth.Key(c); key != nil {
+		return key.ID
+	}
+	return ""
+}
+
+// errorStatus maps a Store error to an HTTP status code
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, alerts.ErrAlertNotFound), errors.Is(err, alerts.ErrDeadLetterNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, alerts.ErrInvalidCondition), errors.Is(err, alerts.ErrInvalidWebhook):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,70 @@
+package arbitrage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/arbitrage"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// ArbitrageController handles HTTP requests for arbitrage opportunities
+type ArbitrageController struct {
+	scanner *arbitrage.Scanner
+}
+
+// NewArbitrageController creates a new ArbitrageController with the given Scanner
+func NewArbitrageController(scanner *arbitrage.Scanner) *ArbitrageController {
+	return &ArbitrageController{scanner: scanner}
+}
+
+// ListHandler handles the /v1/arbitrage endpoint
+func (ac *ArbitrageController) ListHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"opportunities": ac.scanner.Opportunities()})
+}
+
+// StreamHandler handles the /v1/arbitrage/stream endpoint, sending every
+// opened and closed opportunity as a Server-Sent Event
+func (ac *ArbitrageController) StreamHandler(c *fiber.Ctx) error {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	events, unsubscribe := ac.scanner.Subscribe()
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		// Send a comment on an interval so dead clients are noticed even
+		// when there are no events
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				// The scanner ends subscriptions when it stops
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// Flush fails once the client has gone away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}))
+
+	return nil
+}
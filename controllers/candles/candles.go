@@ -0,0 +1,68 @@
+package candles
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/controllers/params"
+	"github.com/SmMistry/triumph-project/services/candles"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Paging limits for candles
+const (
+	defaultLimit = 500
+	maxLimit     = 5000
+)
+
+// CandleController handles HTTP requests for candles
+type CandleController struct {
+	orderService *order.OrderService
+	store        *candles.Store
+}
+
+// NewCandleController creates a new CandleController serving candles from
+// store, with symbols normalized by orderService
+func NewCandleController(orderService *order.OrderService, store *candles.Store) *CandleController {
+	return &CandleController{orderService: orderService, store: store}
+}
+
+// CandlesHandler handles the /v1/candles/:symbol endpoint
+func (cc *CandleController) CandlesHandler(c *fiber.Ctx) error {
+	// Parse the request parameters
+	symbol, err := cc.orderService.NormalizeSymbol(c.Params("symbol"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": orders.ErrorCode(err)})
+	}
+	interval := c.Query("interval", "1m")
+	venue := c.Query("venue", candles.Aggregate)
+	from, err := params.Time(c, "from")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": params.ErrorCode(err)})
+	}
+	to, err := params.Time(c, "to")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": params.ErrorCode(err)})
+	}
+	limit, err := params.Limit(c, defaultLimit)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": params.ErrorCode(err)})
+	}
+
+	result, err := cc.store.Candles(symbol, venue, interval, from, to, min(limit, maxLimit))
+	if errors.Is(err, candles.ErrUnknownInterval) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": "invalid_interval"})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error(), "code": "internal_error"})
+	}
+
+	return c.JSON(fiber.Map{
+		"symbol":   symbol,
+		"venue":    venue,
+		"interval": interval,
+		"candles":  result,
+	})
+}
@@ -0,0 +1,43 @@
+package docs
+
+import (
+	"github.com/SmMistry/triumph-project/services/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+// page renders the OpenAPI document with Redoc
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>triumph-project API</title>
+</head>
+<body>
+<redoc spec-url="/openapi.json"></redoc>
+<script src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+// DocsController handles HTTP requests for the API documentation
+type DocsController struct {
+	spec *openapi.Spec
+}
+
+// NewDocsController creates a new DocsController with the given Spec
+func NewDocsController(spec *openapi.Spec) *DocsController {
+	return &DocsController{spec: spec}
+}
+
+// SpecHandler handles the /openapi.json endpoint
+func (dc *DocsController) SpecHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(dc.spec.JSON())
+}
+
+// UIHandler handles the /docs endpoint
+func (dc *DocsController) UIHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(page)
+}
@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Venue probe limits
+const (
+	// probeTimeout bounds the venue probe made by a readiness check
+	probeTimeout = 5 * time.Second
+	// defaultProbeInterval is the least time between two probes unless
+	// overridden
+	defaultProbeInterval = 10 * time.Second
+)
+
+// HealthController handles the liveness and readiness probes
+type HealthController struct {
+	orderService *order.OrderService
+	minVenues    int
+	probeSymbol  string
+
+	mu            sync.Mutex
+	probeInterval time.Duration
+	lastProbe     time.Time
+}
+
+// NewHealthController creates a new HealthController, the service is ready
+// while minVenues enabled venues have answered recently. Otherwise readiness
+// checks probe the venues for probeSymbol
+func NewHealthController(orderService *order.OrderService, minVenues int, probeSymbol string) *HealthController {
+	return &HealthController{
+		orderService:  orderService,
+		minVenues:     minVenues,
+		probeSymbol:   probeSymbol,
+		probeInterval: defaultProbeInterval,
+	}
+}
+
+// SetProbeInterval sets the least time between two venue probes, ten
+// seconds by default. The endpoint is public, so without it every check
+// made while not ready would call every venue
+func (hc *HealthController) SetProbeInterval(interval time.Duration) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	hc.probeInterval = interval
+}
+
+// LivenessHandler handles the /healthz endpoint
+func (hc *HealthController) LivenessHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// ReadinessHandler handles the /readyz endpoint
+func (hc *HealthController) ReadinessHandler(c *fiber.Ctx) error {
+	venues := hc.orderService.AnsweredVenues()
+	if len(venues) < hc.minVenues && hc.startProbe() {
+		ctx, cancel := context.WithTimeout(c.UserContext(), probeTimeout)
+		defer cancel()
+		venues = hc.orderService.Probe(ctx, hc.probeSymbol)
+	}
+
+	response := fiber.Map{"status": "ready", "venues": venues, "minVenues": hc.minVenues}
+	if len(venues) < hc.minVenues {
+		response["status"] = "not ready"
+		return c.Status(http.StatusServiceUnavailable).JSON(response)
+	}
+	return c.JSON(response)
+}
+
+// startProbe reports whether a check may probe the venues, claiming the
+// probe when it may so concurrent checks don't probe too
+func (hc *HealthController) startProbe() bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	now := time.Now()
+	if !hc.lastProbe.IsZero() && now.Sub(hc.lastProbe) < hc.probeInterval {
+		return false
+	}
+	hc.lastProbe = now
+	return true
+}
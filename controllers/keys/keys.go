@@ -0,0 +1,75 @@
+package keys
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// KeyController handles HTTP requests for managing API keys
+type KeyController struct {
+	store *auth.Store
+}
+
+// NewKeyController creates a new KeyController with the given Store
+func NewKeyController(store *auth.Store) *KeyController {
+	return &KeyController{store: store}
+}
+
+// CreateHandler handles the POST /v1/admin/keys endpoint, the token is only
+// ever returned here
+func (kc *KeyController) CreateHandler(c *fiber.Ctx) error {
+	var body struct {
+		Name       string       `json:"name"`
+		Scopes     []auth.Scope `json:"scopes"`
+		RateLimit  int          `json:"rateLimit"`
+		DailyQuota int          `json:"dailyQuota"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid key"})
+	}
+	if body.Name == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid name"})
+	}
+
+	key, token, err := kc.store.Create(body.Name, body.Scopes, body.RateLimit, body.DailyQuota)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"key": key, "token": token})
+}
+
+// ListHandler handles the GET /v1/admin/keys endpoint
+func (kc *KeyController) ListHandler(c *fiber.Ctx) error {
+	keys, err := kc.store.List()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"keys": keys})
+}
+
+// RevokeHandler handles the DELETE /v1/admin/keys/:id endpoint
+func (kc *KeyController) RevokeHandler(c *fiber.Ctx) error {
+	key, err := kc.store.Revoke(c.Params("id"))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"key": key})
+}
+
+// errorStatus maps a Store error to an HTTP status code
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, auth.ErrInvalidScope), errors.Is(err, auth.ErrInvalidLimit):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,29 @@
+package markets
+
+import (
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MarketController handles HTTP requests for market data
+type MarketController struct {
+	orderService *order.OrderService
+}
+
+// NewMarketController creates a new MarketController with the given OrderService
+func NewMarketController(orderService *order.OrderService) *MarketController {
+	return &MarketController{orderService: orderService}
+}
+
+// MarketHandler handles the /v1/markets/:symbol endpoint
+func (mc *MarketController) MarketHandler(c *fiber.Ctx) error {
+	symbol := c.Params("symbol")
+
+	market, err := mc.orderService.Market(c.UserContext(), symbol)
+	if err != nil {
+		return c.Status(orders.ErrorStatus(err)).JSON(fiber.Map{"error": err.Error(), "code": orders.ErrorCode(err)})
+	}
+
+	return c.JSON(market)
+}
@@ -1,8 +1,12 @@
 package orders
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
+	"strings"
 
+	"github.com/SmMistry/triumph-project/services/logging"
 	"github.com/SmMistry/triumph-project/services/order"
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,57 +14,226 @@ import (
 // OrderController handles HTTP requests for orders
 type OrderController struct {
 	orderService *order.OrderService
+	logger       *slog.Logger
 }
 
 // NewOrderController creates a new OrderController with the given OrderService
 func NewOrderController(orderService *order.OrderService) *OrderController {
-	return &OrderController{orderService: orderService}
+	return &OrderController{orderService: orderService, logger: logging.Discard()}
+}
+
+// SetLogger sets the logger quotes and failed requests are reported to,
+// nothing is logged by default
+func (oc *OrderController) SetLogger(logger *slog.Logger) {
+	oc.logger = logging.OrDiscard(logger)
 }
 
 // BuyHandler handles the /buy endpoint
 func (oc *OrderController) BuyHandler(c *fiber.Ctx) error {
-	// Parse the request parameters
+	return oc.quote(c, order.SideBuy)
+}
+
+// SellHandler handles the /sell endpoint
+func (oc *OrderController) SellHandler(c *fiber.Ctx) error {
+	return oc.quote(c, order.SideSell)
+}
+
+// quote parses the request parameters shared by /buy and /sell and returns
+// the quote for the given side
+func (oc *OrderController) quote(c *fiber.Ctx, side order.Side) error {
+	// Parse the request parameters, the amount is checked by the service
 	amount := c.QueryFloat("amount", 0)
-	if amount == 0{
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid amount"})
-	}
 	symbol := c.Query("symbol")
+	maxSlippageBps := c.QueryFloat("maxSlippageBps", 0)
+	if maxSlippageBps < 0 {
+		return invalid(c, "invalid maxSlippageBps", "invalid_max_slippage")
+	}
+	priceImprovementBps := c.QueryFloat("priceImprovementBps", 0)
+	if priceImprovementBps < 0 {
+		return invalid(c, "invalid priceImprovementBps", "invalid_price_improvement")
+	}
 
-	// Execute the buy order
-	usdAmount, exchanges, err := oc.orderService.Buy(c.Context(), amount, symbol)
+	// Execute the quote
+	quote, err := oc.orderService.Quote(c.UserContext(), order.QuoteRequest{
+		Side:                side,
+		Symbol:              symbol,
+		Amount:              amount,
+		MaxSlippageBps:      maxSlippageBps,
+		AllowPartial:        c.QueryBool("allowPartial", false),
+		Venues:              splitList(c.Query("venues")),
+		ExcludeVenues:       splitList(c.Query("excludeVenues")),
+		PreferredVenue:      c.Query("preferredVenue"),
+		PriceImprovementBps: priceImprovementBps,
+	})
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return oc.fail(c, err)
 	}
 
-	// Return the response
-	return c.JSON(fiber.Map{
-		"coin": symbol,
-		"amount": amount,
-		"usdAmount": usdAmount,
-		"exchange":  exchanges,
+	oc.logger.InfoContext(c.UserContext(), "quote",
+		"side", side, "symbol", quote.Symbol, "amount", quote.Amount,
+		"price", quote.Price, "exchanges", quote.Exchanges)
+
+	// Return the response, with the symbol normalized and the amount rounded
+	// to the lot size
+	response := fiber.Map{
+		"coin":      quote.Symbol,
+		"amount":    quote.Amount,
+		"usdAmount": quote.USDAmount,
+		"exchange":  quote.Exchanges,
+	}
+	if quote.ID != "" {
+		response["quoteId"] = quote.ID
+	}
+	if quote.Fill != nil {
+		response["fill"] = fiber.Map{
+			"avgPrice":       quote.Fill.AvgPrice,
+			"filledAmount":   quote.Fill.FilledAmount,
+			"usdAmount":      quote.Fill.USDAmount,
+			"slippageBps":    quote.Fill.SlippageBps,
+			"priceImpactBps": quote.Fill.PriceImpactBps,
+			"midPrice":       quote.MidPrice,
+			"partial":        quote.Fill.Partial,
+		}
+	}
+	if quote.Route != nil {
+		response["route"] = quote.Route
+	}
+
+	return c.JSON(response)
+}
+
+// PlaceHandler handles the POST /v1/orders endpoint
+func (oc *OrderController) PlaceHandler(c *fiber.Ctx) error {
+	var body struct {
+		Side          order.Side `json:"side"`
+		Symbol        string     `json:"symbol"`
+		Amount        float64    `json:"amount"`
+		ClientOrderID string     `json:"clientOrderId"`
+		Venues        []string   `json:"venues"`
+		ExcludeVenues []string   `json:"excludeVenues"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return invalid(c, "invalid order", "invalid_order")
+	}
+	if body.Side != order.SideBuy && body.Side != order.SideSell {
+		return invalid(c, "invalid side", "invalid_side")
+	}
+
+	parent, children, err := oc.orderService.PlaceOrder(c.UserContext(), order.PlaceRequest{
+		QuoteRequest: order.QuoteRequest{
+			Side:          body.Side,
+			Symbol:        body.Symbol,
+			Amount:        body.Amount,
+			Venues:        body.Venues,
+			ExcludeVenues: body.ExcludeVenues,
+		},
+		ClientOrderID: body.ClientOrderID,
 	})
+	if err != nil {
+		return oc.fail(c, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"order": parent, "childOrders": children})
 }
 
-// SellHandler
-func (oc *OrderController) SellHandler(c *fiber.Ctx) error {
-	// Parse the request parameters
-	amount := c.QueryFloat("amount", 0)
-	if amount == 0 {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid amount"})
+// GetOrderHandler handles the GET /v1/orders/:id endpoint
+func (oc *OrderController) GetOrderHandler(c *fiber.Ctx) error {
+	parent, children, err := oc.orderService.GetOrder(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return oc.fail(c, err)
 	}
-	symbol := c.Query("symbol")
 
-	// Execute the sell order
-	usdAmount, exchanges, err := oc.orderService.Sell(c.Context(), amount, symbol)
+	return c.JSON(fiber.Map{"order": parent, "childOrders": children})
+}
+
+// CancelHandler handles the DELETE /v1/orders/:id endpoint
+func (oc *OrderController) CancelHandler(c *fiber.Ctx) error {
+	parent, children, err := oc.orderService.CancelOrder(c.UserContext(), c.Params("id"))
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return oc.fail(c, err)
 	}
 
-	// Return the response
-	return c.JSON(fiber.Map{
-		"coin": symbol,
-		"amount": amount,
-		"usdAmount": usdAmount,
-		"exchange":  exchanges,
-	})
-}
\ No newline at end of file
+	return c.JSON(fiber.Map{"order": parent, "childOrders": children})
+}
+
+// fail logs an OrderService error and responds with its status
+func (oc *OrderController) fail(c *fiber.Ctx, err error) error {
+	status := ErrorStatus(err)
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	oc.logger.Log(c.UserContext(), level, "request failed", "status", status, "error", err)
+
+	return c.Status(status).JSON(fiber.Map{"error": err.Error(), "code": ErrorCode(err)})
+}
+
+// invalid responds to a malformed request with a 400
+func invalid(c *fiber.Ctx, message, code string) error {
+	return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": message, "code": code})
+}
+
+// ErrorStatus maps an OrderService error to an HTTP status code
+func ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, order.ErrUnknownVenue), errors.Is(err, order.ErrNoVenues):
+		return http.StatusBadRequest
+	case errors.Is(err, order.ErrInvalidAmount), errors.Is(err, order.ErrAmountTooSmall),
+		errors.Is(err, order.ErrAmountTooLarge), errors.Is(err, order.ErrInvalidSymbol),
+		errors.Is(err, order.ErrUnknownSymbol):
+		return http.StatusBadRequest
+	case errors.Is(err, order.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, order.ErrInvalidTransition):
+		return http.StatusConflict
+	case errors.Is(err, order.ErrTradingDisabled):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, order.ErrInsufficientLiquidity):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ErrorCode maps an OrderService error to the machine readable code sent
+// alongside its message
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, order.ErrInvalidAmount):
+		return "invalid_amount"
+	case errors.Is(err, order.ErrAmountTooSmall):
+		return "amount_too_small"
+	case errors.Is(err, order.ErrAmountTooLarge):
+		return "amount_too_large"
+	case errors.Is(err, order.ErrInvalidSymbol):
+		return "invalid_symbol"
+	case errors.Is(err, order.ErrUnknownSymbol):
+		return "unknown_symbol"
+	case errors.Is(err, order.ErrUnknownVenue):
+		return "unknown_venue"
+	case errors.Is(err, order.ErrNoVenues):
+		return "no_venues"
+	case errors.Is(err, order.ErrOrderNotFound):
+		return "order_not_found"
+	case errors.Is(err, order.ErrInvalidTransition):
+		return "invalid_transition"
+	case errors.Is(err, order.ErrTradingDisabled):
+		return "trading_disabled"
+	case errors.Is(err, order.ErrInsufficientLiquidity):
+		return "insufficient_liquidity"
+	default:
+		return "internal_error"
+	}
+}
+
+// splitList splits a comma separated query parameter, dropping empty entries
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,58 @@
+package params
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Errors returned for query parameters that don't parse
+var (
+	// ErrInvalidTime is returned for times that are neither an RFC 3339
+	// timestamp nor unix seconds
+	ErrInvalidTime = errors.New("invalid time")
+	// ErrInvalidLimit is returned for page sizes that aren't a number
+	// greater than 0
+	ErrInvalidLimit = errors.New("invalid limit")
+)
+
+// Time parses the named query parameter as either an RFC 3339 timestamp or
+// unix seconds, a missing value is the zero time
+func Time(c *fiber.Ctx, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be an RFC 3339 timestamp or unix seconds", ErrInvalidTime, name)
+	}
+	return parsed, nil
+}
+
+// Limit parses the limit query parameter, a missing value is fallback
+func Limit(c *fiber.Ctx, fallback int) (int, error) {
+	limit := c.QueryInt("limit", fallback)
+	if limit <= 0 {
+		return 0, fmt.Errorf("%w: must be a number greater than 0", ErrInvalidLimit)
+	}
+	return limit, nil
+}
+
+// ErrorCode returns the machine readable code sent along with err
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidTime):
+		return "invalid_time"
+	case errors.Is(err, ErrInvalidLimit):
+		return "invalid_limit"
+	default:
+		return "internal_error"
+	}
+}
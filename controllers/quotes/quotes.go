@@ -0,0 +1,79 @@
+package quotes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/controllers/params"
+	"github.com/SmMistry/triumph-project/services/history"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/gofiber/fiber/v2"
+)
+
+// QuoteController handles HTTP requests for the quote history
+type QuoteController struct {
+	orderService *order.OrderService
+	store        *history.Store
+}
+
+// NewQuoteController creates a new QuoteController serving quotes from
+// store, with symbols normalized by orderService
+func NewQuoteController(orderService *order.OrderService, store *history.Store) *QuoteController {
+	return &QuoteController{orderService: orderService, store: store}
+}
+
+// ListHandler handles the /v1/quotes endpoint
+func (qc *QuoteController) ListHandler(c *fiber.Ctx) error {
+	// Parse the request parameters, quotes are recorded under the
+	// normalized symbol
+	symbol := c.Query("symbol")
+	if symbol != "" {
+		normalized, err := qc.orderService.NormalizeSymbol(symbol)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": orders.ErrorCode(err)})
+		}
+		symbol = normalized
+	}
+	from, err := params.Time(c, "from")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": params.ErrorCode(err)})
+	}
+	to, err := params.Time(c, "to")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": params.ErrorCode(err)})
+	}
+	limit, err := params.Limit(c, history.DefaultLimit)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": params.ErrorCode(err)})
+	}
+
+	page, err := qc.store.Query(history.Query{
+		Symbol: symbol,
+		From:   from,
+		To:     to,
+		Limit:  limit,
+		Cursor: c.Query("cursor"),
+	})
+	if errors.Is(err, history.ErrInvalidCursor) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": "invalid_cursor"})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error(), "code": "internal_error"})
+	}
+
+	return c.JSON(page)
+}
+
+// GetHandler handles the /v1/quotes/:id endpoint
+func (qc *QuoteController) GetHandler(c *fiber.Ctx) error {
+	record, err := qc.store.Get(c.Params("id"))
+	if errors.Is(err, history.ErrNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": err.Error(), "code": "quote_not_found"})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error(), "code": "internal_error"})
+	}
+
+	return c.JSON(record)
+}
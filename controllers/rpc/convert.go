@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	quotev1 "github.com/SmMistry/triumph-project/proto/quote/v1"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/order"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sides maps the protobuf sides to the service's
+var sides = map[quotev1.Side]order.Side{
+	quotev1.Side_SIDE_BUY:  order.SideBuy,
+	quotev1.Side_SIDE_SELL: order.SideSell,
+}
+
+// toQuote converts a quote to its protobuf message
+func toQuote(quote *order.Quote) *quotev1.Quote {
+	message := &quotev1.Quote{
+		QuoteId:   quote.ID,
+		Symbol:    quote.Symbol,
+		Amount:    quote.Amount,
+		Price:     quote.Price,
+		UsdAmount: quote.USDAmount,
+		Exchanges: quote.Exchanges,
+		MidPrice:  quote.MidPrice,
+		Route:     toRoute(quote.Route),
+		Fee:       quote.Fee,
+	}
+	for side, s := range sides {
+		if s == quote.Side {
+			message.Side = side
+		}
+	}
+	if quote.Fill != nil {
+		message.Fill = &quotev1.Fill{
+			AvgPrice:       quote.Fill.AvgPrice,
+			FilledAmount:   quote.Fill.FilledAmount,
+			UsdAmount:      quote.Fill.USDAmount,
+			SlippageBps:    quote.Fill.SlippageBps,
+			PriceImpactBps: quote.Fill.PriceImpactBps,
+			Partial:        quote.Fill.Partial,
+		}
+	}
+	return message
+}
+
+// toMarket converts a market to its protobuf message
+func toMarket(market *order.Market) *quotev1.Market {
+	message := &quotev1.Market{
+		Symbol:    market.Symbol,
+		Venues:    make([]*quotev1.VenueMarket, 0, len(market.Venues)),
+		BestBid:   &quotev1.BestPrice{Price: market.BestBid.Price, Exchanges: market.BestBid.Exchanges},
+		BestAsk:   &quotev1.BestPrice{Price: market.BestAsk.Price, Exchanges: market.BestAsk.Exchanges},
+		Spread:    market.Spread,
+		SpreadBps: market.SpreadBps,
+		MidPrice:  market.MidPrice,
+	}
+	for _, venue := range market.Venues {
+		message.Venues = append(message.Venues, &quotev1.VenueMarket{
+			Exchange:  venue.Exchange,
+			Bid:       venue.Bid,
+			Ask:       venue.Ask,
+			Mid:       venue.Mid,
+			Spread:    venue.Spread,
+			SpreadBps: venue.SpreadBps,
+			BidSize:   venue.BidSize,
+			AskSize:   venue.AskSize,
+			Route:     toRoute(venue.Route),
+			Error:     venue.Error,
+		})
+	}
+	return message
+}
+
+// toRoute converts a synthetic route to its protobuf message
+func toRoute(route *order.Route) *quotev1.Route {
+	if route == nil {
+		return nil
+	}
+	message := &quotev1.Route{Synthetic: route.Synthetic, Via: route.Via}
+	for _, leg := range route.Legs {
+		message.Legs = append(message.Legs, &quotev1.Leg{Pair: leg.Pair, Bid: leg.Bid, Ask: leg.Ask})
+	}
+	return message
+}
+
+// toBBOEvent converts a bbo event to its protobuf message
+func toBBOEvent(event bbo.Event) *quotev1.BBOEvent {
+	return &quotev1.BBOEvent{
+		Sequence: event.Sequence,
+		Time:     timestamppb.New(event.Time),
+		Market:   toMarket(&event.Market),
+	}
+}
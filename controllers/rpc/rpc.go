@@ -0,0 +1,351 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	quotev1 "github.com/SmMistry/triumph-project/proto/quote/v1"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the domain of the ErrorInfo attached to failed calls
+const errorDomain = "triumph-project"
+
+// QuoteServer serves the gRPC QuoteService from the same OrderService and
+// Poller as the REST endpoints
+type QuoteServer struct {
+	quotev1.UnimplementedQuoteServiceServer
+
+	orderService  *order.OrderService
+	poller        *bbo.Poller
+	maxSymbols    int
+	authenticator *auth.Authenticator
+	logger        *slog.Logger
+}
+
+// NewQuoteServer creates a new QuoteServer, allowing up to maxSymbols symbols
+// per WatchBBO stream
+func NewQuoteServer(orderService *order.OrderService, poller *bbo.Poller, maxSymbols int) *QuoteServer {
+	return &QuoteServer{
+		orderService: orderService,
+		poller:       poller,
+		maxSymbols:   maxSymbols,
+		logger:       logging.Discard(),
+	}
+}
+
+// SetAuthenticator requires an API key with the quote scope on every call,
+// sent as "authorization: Bearer <key>" or "x-api-key" metadata. Calls are
+// not authenticated by default
+func (s *QuoteServer) SetAuthenticator(authenticator *auth.Authenticator) {
+	s.authenticator = authenticator
+}
+
+// SetLogger sets the logger calls are reported to, nothing is logged by
+// default
+func (s *QuoteServer) SetLogger(logger *slog.Logger) {
+	s.logger = logging.OrDiscard(logger)
+}
+
+// NewServer returns a gRPC server serving the QuoteService along with the
+// standard health and reflection services, and the health server to mark it
+// as not serving at shutdown
+func NewServer(quoteServer *QuoteServer) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(quoteServer.unaryInterceptor),
+		grpc.ChainStreamInterceptor(quoteServer.streamInterceptor),
+	)
+
+	quotev1.RegisterQuoteServiceServer(server, quoteServer)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(quotev1.QuoteService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+	return server, healthServer
+}
+
+// GetQuote returns the best price to buy or sell an amount of a symbol
+func (s *QuoteServer) GetQuote(ctx context.Context, req *quotev1.GetQuoteRequest) (*quotev1.GetQuoteResponse, error) {
+	side, ok := sides[req.GetSide()]
+	if !ok {
+		return nil, invalidArgument("invalid side", "invalid_side")
+	}
+	if req.GetAmount() <= 0 {
+		return nil, orderError(ctx, order.ErrInvalidAmount)
+	}
+	if req.GetMaxSlippageBps() < 0 {
+		return nil, invalidArgument("invalid maxSlippageBps", "invalid_max_slippage")
+	}
+	if req.GetPriceImprovementBps() < 0 {
+		return nil, invalidArgument("invalid priceImprovementBps", "invalid_price_improvement")
+	}
+
+	quote, err := s.orderService.Quote(ctx, order.QuoteRequest{
+		Side:                side,
+		Symbol:              req.GetSymbol(),
+		Amount:              req.GetAmount(),
+		MaxSlippageBps:      req.GetMaxSlippageBps(),
+		AllowPartial:        req.GetAllowPartial(),
+		Venues:              req.GetVenues(),
+		ExcludeVenues:       req.GetExcludeVenues(),
+		PreferredVenue:      req.GetPreferredVenue(),
+		PriceImprovementBps: req.GetPriceImprovementBps(),
+	})
+	if err != nil {
+		return nil, orderError(ctx, err)
+	}
+
+	return &quotev1.GetQuoteResponse{Quote: toQuote(quote)}, nil
+}
+
+// GetMarket returns the top of book of a symbol on every exchange
+func (s *QuoteServer) GetMarket(ctx context.Context, req *quotev1.GetMarketRequest) (*quotev1.GetMarketResponse, error) {
+	market, err := s.orderService.Market(ctx, req.GetSymbol())
+	if err != nil {
+		return nil, orderError(ctx, err)
+	}
+
+	return &quotev1.GetMarketResponse{Market: toMarket(market)}, nil
+}
+
+// WatchBBO streams best bid and offer changes of the requested symbols
+func (s *QuoteServer) WatchBBO(req *quotev1.WatchBBORequest, stream quotev1.QuoteService_WatchBBOServer) error {
+	symbols := []string{}
+	for _, symbol := range req.GetSymbols() {
+		if strings.TrimSpace(symbol) == "" {
+			continue
+		}
+		symbol, err := s.orderService.NormalizeSymbol(symbol)
+		if err != nil {
+			return orderError(stream.Context(), err)
+		}
+		if !contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return invalidArgument("invalid symbols", "invalid_symbol")
+	}
+	if len(symbols) > s.maxSymbols {
+		return invalidArgument(fmt.Sprintf("at most %d symbols per stream", s.maxSymbols), "too_many_symbols")
+	}
+
+	events, unsubscribe := s.poller.Subscribe(symbols...)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			// The poller ends subscriptions when it stops
+			if !ok {
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			if err := stream.Send(toBBOEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// unaryInterceptor authenticates and logs QuoteService calls
+func (s *QuoteServer) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !isQuoteService(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	start := time.Now()
+	ctx = withRequestID(ctx)
+	if err := s.authorize(ctx); err != nil {
+		s.log(ctx, info.FullMethod, start, err)
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	s.log(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+// streamInterceptor authenticates and logs QuoteService streams
+func (s *QuoteServer) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !isQuoteService(info.FullMethod) {
+		return handler(srv, stream)
+	}
+
+	start := time.Now()
+	ctx := withRequestID(stream.Context())
+	err := s.authorize(ctx)
+	if err == nil {
+		err = handler(srv, stream)
+	}
+	s.log(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// authorize checks the call's API key, scope, rate limit and quota the same
+// way the REST endpoints do
+func (s *QuoteServer) authorize(ctx context.Context) error {
+	if s.authenticator == nil {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := first(md.Get(strings.ToLower(auth.KeyHeader)))
+	if bearer, ok := strings.CutPrefix(first(md.Get("authorization")), "Bearer "); ok {
+		token = bearer
+	}
+	if token == "" {
+		return authError(codes.Unauthenticated, auth.ErrMissingKey)
+	}
+
+	key, err := s.authenticator.Authenticate(token)
+	if errors.Is(err, auth.ErrInvalidKey) {
+		return authError(codes.Unauthenticated, err)
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if err := key.CheckScope(auth.ScopeQuote); err != nil {
+		return authError(codes.PermissionDenied, err)
+	}
+
+	err = s.authenticator.Admit(key, time.Now())
+	if errors.Is(err, auth.ErrRateLimited) || errors.Is(err, auth.ErrQuotaExceeded) {
+		return authError(codes.ResourceExhausted, err)
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// authError returns the status of a failed authentication, with its REST
+// error code as the reason
+func authError(code codes.Code, err error) error {
+	return withReason(status.New(code, err.Error()), auth.ErrorCode(err))
+}
+
+// log reports a finished call
+func (s *QuoteServer) log(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown {
+		level = slog.LevelError
+	}
+	s.logger.Log(ctx, level, "rpc", "method", method, "code", code.String(),
+		"duration", time.Since(start).String())
+}
+
+// statusError converts a failure to the gRPC status matching the REST
+// status it would get, unless the call itself was cancelled or timed out
+func statusError(ctx context.Context, httpStatus int, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codeFor(httpStatus), err.Error())
+}
+
+// orderError converts an OrderService failure like statusError, attaching
+// the REST error code as the reason of an ErrorInfo detail
+func orderError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	return withReason(status.New(codeFor(orders.ErrorStatus(err)), err.Error()), orders.ErrorCode(err))
+}
+
+// invalidArgument returns the status of a request the REST endpoints reject
+// with a 400, with its REST error code as the reason
+func invalidArgument(message, reason string) error {
+	return withReason(status.New(codeFor(http.StatusBadRequest), message), reason)
+}
+
+// withReason attaches an ErrorInfo detail with the given reason to a status
+func withReason(st *status.Status, reason string) error {
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// codeFor maps an HTTP status to the equivalent gRPC code
+func codeFor(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// withRequestID tags the call's context with the request ID sent in its
+// metadata, or a new one
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := first(md.Get(strings.ToLower(logging.RequestIDHeader)))
+	if id == "" || len(id) > 128 {
+		id = uuid.NewString()
+	}
+	return logging.WithRequestID(ctx, id)
+}
+
+// isQuoteService reports whether a method belongs to the QuoteService rather
+// than the health or reflection services
+func isQuoteService(method string) bool {
+	return strings.HasPrefix(method, "/"+quotev1.QuoteService_ServiceDesc.ServiceName+"/")
+}
+
+// first returns the first of a metadata key's values
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// contains reports whether list contains value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
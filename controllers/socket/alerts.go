@@ -0,0 +1,19 @@
+package socket
+
+import (
+	"github.com/SmMistry/triumph-project/services/alerts"
+)
+
+// Alert triggers when its condition starts to hold for a symbol's best bid
+// and offer. It triggers again only once the condition has stopped holding
+type Alert struct {
+	// ID is assigned by the server when left empty
+	ID string `json:"id"`
+	alerts.Condition
+}
+
+// alert is an Alert and whether its condition held on the last event
+type alert struct {
+	Alert
+	holding bool
+}
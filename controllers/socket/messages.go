@@ -0,0 +1,113 @@
+package socket
+
+import (
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+// Version is the version of the message schema. Every message the server
+// sends carries it, and client messages with a different non-zero version
+// are rejected
+const Version = 1
+
+// Message types sent by clients
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypeQuote       = "quote"
+	TypeAddAlert    = "alert.add"
+	TypeRemoveAlert = "alert.remove"
+	TypePing        = "ping"
+)
+
+// Message types sent by the server, replies to a client message echo its id
+const (
+	TypeWelcome        = "welcome"
+	TypeSubscribed     = "subscribed"
+	TypeUnsubscribed   = "unsubscribed"
+	TypeBBO            = "bbo"
+	TypeAlertAdded     = "alert.added"
+	TypeAlertRemoved   = "alert.removed"
+	TypeAlertTriggered = "alert.triggered"
+	TypePong           = "pong"
+	TypeError          = "error"
+)
+
+// Request is a message sent by a client
+type Request struct {
+	V    int    `json:"v"`
+	Type string `json:"type"`
+	// ID is chosen by the client and echoed in the reply
+	ID string `json:"id,omitempty"`
+	// Symbols are set for subscribe and unsubscribe
+	Symbols []string `json:"symbols,omitempty"`
+	// Quote is set for quote
+	Quote *order.QuoteRequest `json:"quote,omitempty"`
+	// Alert is set for alert.add, AlertID for alert.remove
+	Alert   *Alert `json:"alert,omitempty"`
+	AlertID string `json:"alertId,omitempty"`
+}
+
+// Response is a message sent by the server, either a reply to a Request or
+// an event
+type Response struct {
+	V     int    `json:"v"`
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Data  any    `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Code is the machine readable code of a failed quote or invalid
+	// symbol, as sent by the REST API
+	Code string `json:"code,omitempty"`
+}
+
+// Welcome is the data of the first message on every connection
+type Welcome struct {
+	Version int `json:"version"`
+	// MaxSymbols caps how many symbols a connection can subscribe to
+	MaxSymbols int `json:"maxSymbols"`
+	// PingInterval is how often, in seconds, the server pings the client,
+	// connections that don't answer within twice that are closed
+	PingInterval int `json:"pingInterval"`
+}
+
+// Subscriptions is the data of subscribed and unsubscribed replies, listing
+// every symbol the connection is subscribed to
+type Subscriptions struct {
+	Symbols []string `json:"symbols"`
+}
+
+// QuoteResult is the data of a quote reply
+type QuoteResult struct {
+	QuoteID   string       `json:"quoteId,omitempty"`
+	Side      order.Side   `json:"side"`
+	Symbol    string       `json:"symbol"`
+	Amount    float64      `json:"amount"`
+	Price     float64      `json:"price"`
+	USDAmount float64      `json:"usdAmount"`
+	Exchanges []string     `json:"exchange"`
+	MidPrice  float64      `json:"midPrice,omitempty"`
+	Route     *order.Route `json:"route,omitempty"`
+}
+
+// newQuoteResult converts a quote to its message data
+func newQuoteResult(quote *order.Quote) QuoteResult {
+	return QuoteResult{
+		QuoteID:   quote.ID,
+		Side:      quote.Side,
+		Symbol:    quote.Symbol,
+		Amount:    quote.Amount,
+		Price:     quote.Price,
+		USDAmount: quote.USDAmount,
+		Exchanges: quote.Exchanges,
+		MidPrice:  quote.MidPrice,
+		Route:     quote.Route,
+	}
+}
+
+// AlertTrigger is the data of an alert.triggered event
+type AlertTrigger struct {
+	Alert Alert   `json:"alert"`
+	Value float64 `json:"value"`
+	// Sequence is the bbo event sequence that triggered the alert
+	Sequence uint64 `json:"sequence"`
+}
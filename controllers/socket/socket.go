@@ -0,0 +1,629 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Connection limits
+const (
+	// pingInterval is how often clients are pinged, they are disconnected
+	// when nothing is heard from them for twice as long
+	pingInterval = 15 * time.Second
+	writeTimeout = 10 * time.Second
+	// sendBuffer is how many messages can wait for a slow client. Market
+	// data beyond that is dropped, any other message closes the connection
+	sendBuffer = 64
+	// maxQuotes is how many quotes a connection can have in flight
+	maxQuotes = 4
+	// maxAlerts is how many alerts a connection can register
+	maxAlerts       = 50
+	maxMessageBytes = 4096
+)
+
+// errSlowConsumer closes connections that can't keep up with their replies
+var errSlowConsumer = errors.New("slow consumer")
+
+// SocketController handles WebSocket connections streaming quotes and alerts
+type SocketController struct {
+	orderService *order.OrderService
+	poller       *bbo.Poller
+	maxSymbols   int
+	logger       *slog.Logger
+	upgrader     websocket.FastHTTPUpgrader
+	// authenticator counts quotes against the rate limit and quota of the
+	// connection's key, nil when authentication is off
+	authenticator *auth.Authenticator
+
+	// conns are the open connections, closed with a going away frame on
+	// shutdown. Connections opened once closing is set are closed at once
+	mu      sync.Mutex
+	conns   map[*connection]struct{}
+	closing bool
+	open    sync.WaitGroup
+}
+
+// NewSocketController creates a new SocketController quoting with the given
+// OrderService and streaming prices from the given Poller, allowing up to
+// maxSymbols subscriptions per connection
+func NewSocketController(orderService *order.OrderService, poller *bbo.Poller, maxSymbols int) *SocketController {
+	return &SocketController{
+		orderService: orderService,
+		poller:       poller,
+		maxSymbols:   maxSymbols,
+		logger:       logging.Discard(),
+		conns:        map[*connection]struct{}{},
+		upgrader: websocket.FastHTTPUpgrader{
+			// Clients authenticate with API keys rather than cookies, so
+			// connections from any origin are safe
+			CheckOrigin: func(*fasthttp.RequestCtx) bool { return true },
+		},
+	}
+}
+
+// SetLogger sets the logger connections are reported to, nothing is logged
+// by default
+func (sc *SocketController) SetLogger(logger *slog.Logger) {
+	sc.logger = logging.OrDiscard(logger)
+}
+
+// SetAuthenticator sets the Authenticator every quote is admitted by, the
+// same way each REST request is. Quotes are not limited without one
+func (sc *SocketController) SetAuthenticator(authenticator *auth.Authenticator) {
+	sc.authenticator = authenticator
+}
+
+// Handler handles the /v1/ws endpoint, upgrading the request to a WebSocket
+func (sc *SocketController) Handler(c *fiber.Ctx) error {
+	if !websocket.FastHTTPIsWebSocketUpgrade(c.Context()) {
+		return fiber.ErrUpgradeRequired
+	}
+
+	// The request context ends with the handler, the connection outlives it
+	requestID := logging.RequestID(c.UserContext())
+	key := auth.Key(c)
+
+	return sc.upgrader.Upgrade(c.Context(), func(ws *websocket.Conn) {
+		ctx, cancel := context.WithCancel(logging.WithRequestID(context.Background(), requestID))
+		defer cancel()
+
+		conn := &connection{
+			controller:    sc,
+			authenticator: sc.authenticator,
+			key:           key,
+			ws:            ws,
+			ctx:           ctx,
+			cancel:        cancel,
+			send:          make(chan []byte, sendBuffer),
+			quotes:        make(chan struct{}, maxQuotes),
+			watches:       map[string]*watch{},
+			alerts:        map[string]*alert{},
+		}
+		if !sc.track(conn) {
+			conn.close(websocket.CloseGoingAway, "server shutting down")
+			return
+		}
+		defer sc.untrack(conn)
+		conn.serve()
+	})
+}
+
+// Shutdown closes every connection with a going away close frame, then waits
+// for them to end until ctx is done
+func (sc *SocketController) Shutdown(ctx context.Context) error {
+	sc.mu.Lock()
+	sc.closing = true
+	conns := make([]*connection, 0, len(sc.conns))
+	for conn := range sc.conns {
+		conns = append(conns, conn)
+	}
+	sc.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.close(websocket.CloseGoingAway, "server shutting down")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		sc.open.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// track adds a connection to the open ones, unless shutting down
+func (sc *SocketController) track(conn *connection) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.closing {
+		return false
+	}
+	sc.conns[conn] = struct{}{}
+	sc.open.Add(1)
+	return true
+}
+
+// untrack removes a connection that ended
+func (sc *SocketController) untrack(conn *connection) {
+	sc.mu.Lock()
+	delete(sc.conns, conn)
+	sc.mu.Unlock()
+	sc.open.Done()
+}
+
+// connection is the state of one WebSocket client
+type connection struct {
+	controller *SocketController
+	// authenticator admits quotes against key, the API key the connection
+	// was opened with. Both are nil when authentication is off
+	authenticator *auth.Authenticator
+	key           *auth.APIKey
+	ws            *websocket.Conn
+	ctx           context.Context
+	cancel        context.CancelFunc
+	send          chan []byte
+	quotes        chan struct{}
+	closeOnce     sync.Once
+
+	mu        sync.Mutex
+	watches   map[string]*watch
+	alerts    map[string]*alert
+	nextAlert int
+}
+
+// watch is a poller subscription for one symbol, kept while the client is
+// subscribed to the symbol or has alerts on it
+type watch struct {
+	unsubscribe func()
+	subscribed  bool
+}
+
+// serve reads client messages until the connection closes
+func (conn *connection) serve() {
+	logger := conn.controller.logger
+	logger.InfoContext(conn.ctx, "websocket connected")
+	defer logger.InfoContext(conn.ctx, "websocket disconnected")
+
+	defer conn.stopWatches()
+	go conn.write()
+
+	conn.ws.SetReadLimit(maxMessageBytes)
+	conn.ws.SetReadDeadline(time.Now().Add(2 * pingInterval))
+	conn.ws.SetPongHandler(func(string) error {
+		return conn.ws.SetReadDeadline(time.Now().Add(2 * pingInterval))
+	})
+
+	conn.reply(Response{Type: TypeWelcome, Data: Welcome{
+		Version:      Version,
+		MaxSymbols:   conn.controller.maxSymbols,
+		PingInterval: int(pingInterval / time.Second),
+	}})
+
+	for {
+		_, data, err := conn.ws.ReadMessage()
+		if err != nil {
+			conn.close(websocket.CloseNormalClosure, "")
+			return
+		}
+		// Any message shows the client is alive
+		conn.ws.SetReadDeadline(time.Now().Add(2 * pingInterval))
+
+		var request Request
+		if err := json.Unmarshal(data, &request); err != nil {
+			conn.reply(Response{Type: TypeError, Error: "invalid message"})
+			continue
+		}
+		conn.handle(request)
+	}
+}
+
+// handle answers a client message
+func (conn *connection) handle(request Request) {
+	if request.V != 0 && request.V != Version {
+		conn.fail(request, fmt.Errorf("unsupported version %d, the server speaks version %d", request.V, Version))
+		return
+	}
+
+	switch request.Type {
+	case TypePing:
+		conn.reply(Response{Type: TypePong, ID: request.ID})
+	case TypeSubscribe:
+		conn.subscribe(request)
+	case TypeUnsubscribe:
+		conn.unsubscribe(request)
+	case TypeQuote:
+		conn.quote(request)
+	case TypeAddAlert:
+		conn.addAlert(request)
+	case TypeRemoveAlert:
+		conn.removeAlert(request)
+	default:
+		conn.fail(request, fmt.Errorf("unknown message type %q", request.Type))
+	}
+}
+
+// subscribe starts streaming bbo events of the requested symbols
+func (conn *connection) subscribe(request Request) {
+	if len(request.Symbols) == 0 {
+		conn.failCode(request, fmt.Errorf("%w: symbols are required", order.ErrInvalidSymbol))
+		return
+	}
+	symbols, err := conn.normalizeSymbols(request.Symbols)
+	if err != nil {
+		conn.failCode(request, err)
+		return
+	}
+
+	conn.mu.Lock()
+	subscribed := conn.subscribedLocked()
+	for _, symbol := range symbols {
+		if !slices.Contains(subscribed, symbol) {
+			subscribed = append(subscribed, symbol)
+		}
+	}
+	if len(subscribed) > conn.controller.maxSymbols {
+		conn.mu.Unlock()
+		conn.fail(request, fmt.Errorf("at most %d symbols per connection", conn.controller.maxSymbols))
+		return
+	}
+	for _, symbol := range symbols {
+		w, ok := conn.watches[symbol]
+		if ok && w.subscribed {
+			continue
+		}
+		if ok {
+			w.unsubscribe()
+		} else {
+			w = &watch{}
+			conn.watches[symbol] = w
+		}
+		w.subscribed = true
+
+		// Restart watches already kept for alerts, so the client gets the
+		// latest event straight away
+		conn.startWatchLocked(symbol, w)
+	}
+
+	// Reply before unlocking so the reply is sent ahead of the first event
+	conn.reply(Response{Type: TypeSubscribed, ID: request.ID, Data: Subscriptions{Symbols: conn.subscribedLocked()}})
+	conn.mu.Unlock()
+}
+
+// unsubscribe stops streaming bbo events of the requested symbols
+func (conn *connection) unsubscribe(request Request) {
+	symbols, err := conn.normalizeSymbols(request.Symbols)
+	if err != nil {
+		conn.failCode(request, err)
+		return
+	}
+
+	conn.mu.Lock()
+	for _, symbol := range symbols {
+		if w, ok := conn.watches[symbol]; ok {
+			w.subscribed = false
+			conn.unwatchLocked(symbol)
+		}
+	}
+	subscribed := conn.subscribedLocked()
+	conn.mu.Unlock()
+
+	conn.reply(Response{Type: TypeUnsubscribed, ID: request.ID, Data: Subscriptions{Symbols: subscribed}})
+}
+
+// normalizeSymbols normalizes symbols the way quotes do, dropping duplicates
+// so "btc" and "BTC-USD" share one poller feed
+func (conn *connection) normalizeSymbols(symbols []string) ([]string, error) {
+	normalized := []string{}
+	for _, symbol := range symbols {
+		symbol, err := conn.controller.orderService.NormalizeSymbol(symbol)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(normalized, symbol) {
+			normalized = append(normalized, symbol)
+		}
+	}
+	return normalized, nil
+}
+
+// quote prices the requested quote without blocking the read loop
+func (conn *connection) quote(request Request) {
+	if request.Quote == nil {
+		conn.fail(request, errors.New("invalid quote"))
+		return
+	}
+	if request.Quote.Side != order.SideBuy && request.Quote.Side != order.SideSell {
+		conn.fail(request, errors.New("invalid side"))
+		return
+	}
+	if request.Quote.Amount <= 0 {
+		conn.failCode(request, order.ErrInvalidAmount)
+		return
+	}
+
+	select {
+	case conn.quotes <- struct{}{}:
+	default:
+		conn.fail(request, fmt.Errorf("at most %d quotes in flight", maxQuotes))
+		return
+	}
+
+	// Each quote counts against the key like a REST request would
+	if conn.authenticator != nil && conn.key != nil {
+		if err := conn.authenticator.Admit(conn.key, time.Now()); err != nil {
+			<-conn.quotes
+			conn.reply(Response{Type: TypeError, ID: request.ID, Error: err.Error(), Code: auth.ErrorCode(err)})
+			return
+		}
+	}
+
+	go func() {
+		defer func() { <-conn.quotes }()
+
+		quote, err := conn.controller.orderService.Quote(conn.ctx, *request.Quote)
+		if err != nil {
+			conn.failCode(request, err)
+			return
+		}
+		conn.reply(Response{Type: TypeQuote, ID: request.ID, Data: newQuoteResult(quote)})
+	}()
+}
+
+// addAlert registers an alert on the connection
+func (conn *connection) addAlert(request Request) {
+	if request.Alert == nil {
+		conn.fail(request, errors.New("invalid alert"))
+		return
+	}
+	if err := request.Alert.Validate(); err != nil {
+		conn.fail(request, err)
+		return
+	}
+	symbol, err := conn.controller.orderService.NormalizeSymbol(request.Alert.Symbol)
+	if err != nil {
+		conn.failCode(request, err)
+		return
+	}
+
+	conn.mu.Lock()
+	a := &alert{Alert: *request.Alert}
+	a.Symbol = symbol
+	defer conn.mu.Unlock()
+	if a.ID == "" {
+		// Skip IDs clients gave their own alerts
+		for a.ID == "" || conn.alerts[a.ID] != nil {
+			conn.nextAlert++
+			a.ID = strconv.Itoa(conn.nextAlert)
+		}
+	}
+
+	if _, ok := conn.alerts[a.ID]; ok {
+		conn.fail(request, fmt.Errorf("alert %s already exists", a.ID))
+		return
+	}
+	if len(conn.alerts) >= maxAlerts {
+		conn.fail(request, fmt.Errorf("at most %d alerts per connection", maxAlerts))
+		return
+	}
+	conn.alerts[a.ID] = a
+	conn.watchLocked(a.Symbol)
+
+	// Reply before unlocking so the reply is sent ahead of the alert's
+	// first trigger
+	conn.reply(Response{Type: TypeAlertAdded, ID: request.ID, Data: a.Alert})
+}
+
+// removeAlert removes an alert from the connection
+func (conn *connection) removeAlert(request Request) {
+	conn.mu.Lock()
+	a, ok := conn.alerts[request.AlertID]
+	if ok {
+		delete(conn.alerts, a.ID)
+		conn.unwatchLocked(a.Symbol)
+	}
+	conn.mu.Unlock()
+
+	if !ok {
+		conn.fail(request, fmt.Errorf("alert %s not found", request.AlertID))
+		return
+	}
+	conn.reply(Response{Type: TypeAlertRemoved, ID: request.ID, Data: a.Alert})
+}
+
+// watchLocked returns the watch of a symbol, starting it when needed
+func (conn *connection) watchLocked(symbol string) *watch {
+	w, ok := conn.watches[symbol]
+	if !ok {
+		w = &watch{}
+		conn.watches[symbol] = w
+		conn.startWatchLocked(symbol, w)
+	}
+	return w
+}
+
+// startWatchLocked subscribes to the poller and forwards its events to the
+// client and its alerts
+func (conn *connection) startWatchLocked(symbol string, w *watch) {
+	events, unsubscribe := conn.controller.poller.Subscribe(symbol)
+	w.unsubscribe = unsubscribe
+
+	go func() {
+		for event := range events {
+			conn.mu.Lock()
+			subscribed := w.subscribed
+			conn.mu.Unlock()
+
+			if subscribed {
+				conn.publish(Response{Type: TypeBBO, Data: event})
+			}
+			conn.evaluate(event)
+		}
+	}()
+}
+
+// unwatchLocked stops the watch of a symbol once nothing needs it
+func (conn *connection) unwatchLocked(symbol string) {
+	w, ok := conn.watches[symbol]
+	if !ok || w.subscribed {
+		return
+	}
+	for _, a := range conn.alerts {
+		if a.Symbol == symbol {
+			return
+		}
+	}
+	w.unsubscribe()
+	delete(conn.watches, symbol)
+}
+
+// stopWatches ends every poller subscription of the connection
+func (conn *connection) stopWatches() {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	for symbol, w := range conn.watches {
+		w.unsubscribe()
+		delete(conn.watches, symbol)
+	}
+}
+
+// subscribedLocked returns the symbols the client is subscribed to
+func (conn *connection) subscribedLocked() []string {
+	symbols := []string{}
+	for symbol, w := range conn.watches {
+		if w.subscribed {
+			symbols = append(symbols, symbol)
+		}
+	}
+	slices.Sort(symbols)
+	return symbols
+}
+
+// evaluate sends an alert.triggered event for every alert of the event's
+// symbol whose condition started holding
+func (conn *connection) evaluate(event bbo.Event) {
+	triggers := []AlertTrigger{}
+
+	conn.mu.Lock()
+	for _, a := range conn.alerts {
+		if a.Symbol != event.Symbol {
+			continue
+		}
+		value, holding := a.Evaluate(&event.Market)
+		if holding && !a.holding {
+			triggers = append(triggers, AlertTrigger{Alert: a.Alert, Value: value, Sequence: event.Sequence})
+		}
+		a.holding = holding
+	}
+	conn.mu.Unlock()
+
+	for _, trigger := range triggers {
+		conn.reply(Response{Type: TypeAlertTriggered, Data: trigger})
+	}
+}
+
+// reply queues a message the client must receive, closing the connection
+// when the client is too far behind to take it
+func (conn *connection) reply(response Response) {
+	if !conn.queue(response) {
+		conn.controller.logger.WarnContext(conn.ctx, "closing websocket", "error", errSlowConsumer)
+		conn.close(websocket.ClosePolicyViolation, errSlowConsumer.Error())
+	}
+}
+
+// publish queues market data, dropping it when the client is too far behind.
+// Clients notice from the gap in sequence numbers
+func (conn *connection) publish(response Response) {
+	conn.queue(response)
+}
+
+// queue adds a message to the send buffer, reporting whether there was room
+func (conn *connection) queue(response Response) bool {
+	response.V = Version
+	data, err := json.Marshal(response)
+	if err != nil {
+		conn.controller.logger.ErrorContext(conn.ctx, "failed to encode websocket message", "error", err)
+		return true
+	}
+
+	select {
+	case <-conn.ctx.Done():
+		return true
+	case conn.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// fail replies with an error to a client message
+func (conn *connection) fail(request Request, err error) {
+	conn.reply(Response{Type: TypeError, ID: request.ID, Error: err.Error()})
+}
+
+// failCode replies with an OrderService error and its REST code
+func (conn *connection) failCode(request Request, err error) {
+	conn.reply(Response{Type: TypeError, ID: request.ID, Error: err.Error(), Code: orders.ErrorCode(err)})
+}
+
+// write sends queued messages and pings until the connection closes
+func (conn *connection) write() {
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-conn.ctx.Done():
+			return
+		case data := <-conn.send:
+			conn.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+				conn.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				conn.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// close sends a close frame with the given code, when it is one that can be
+// sent, and closes the connection
+func (conn *connection) close(code int, reason string) {
+	conn.closeOnce.Do(func() {
+		conn.cancel()
+		if code != websocket.CloseAbnormalClosure {
+			conn.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+		}
+		// Closing a hijacked connection is left to the server once serve
+		// returns, end the read loop so it does
+		conn.ws.SetReadDeadline(time.Now())
+		conn.ws.Close()
+	})
+}
@@ -0,0 +1,95 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// StreamController handles HTTP requests for live price streams
+type StreamController struct {
+	orderService *order.OrderService
+	poller       *bbo.Poller
+	maxSymbols   int
+}
+
+// NewStreamController creates a new StreamController streaming from the
+// given Poller, with symbols normalized by orderService, allowing up to
+// maxSymbols symbols per stream
+func NewStreamController(orderService *order.OrderService, poller *bbo.Poller, maxSymbols int) *StreamController {
+	return &StreamController{orderService: orderService, poller: poller, maxSymbols: maxSymbols}
+}
+
+// BBOHandler handles the /v1/stream/bbo endpoint, sending every change of the
+// best bid or offer of the requested symbols as a Server-Sent Event
+func (sc *StreamController) BBOHandler(c *fiber.Ctx) error {
+	symbols := []string{}
+	for _, symbol := range strings.Split(c.Query("symbols"), ",") {
+		if strings.TrimSpace(symbol) == "" {
+			continue
+		}
+		symbol, err := sc.orderService.NormalizeSymbol(symbol)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": orders.ErrorCode(err)})
+		}
+		if !slices.Contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid symbols", "code": "invalid_symbol"})
+	}
+	if len(symbols) > sc.maxSymbols {
+		message := fmt.Sprintf("at most %d symbols per stream", sc.maxSymbols)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": message, "code": "too_many_symbols"})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	events, unsubscribe := sc.poller.Subscribe(symbols...)
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		// Send a comment on an interval so dead clients are noticed even
+		// when prices don't move
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				// The poller ends subscriptions when it stops
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: bbo\nid: %s:%d\ndata: %s\n\n", event.Symbol, event.Sequence, data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// Flush fails once the client has gone away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}))
+
+	return nil
+}
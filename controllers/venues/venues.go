@@ -0,0 +1,92 @@
+package venues
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/SmMistry/triumph-project/services/venues"
+	"github.com/gofiber/fiber/v2"
+)
+
+// VenueController handles HTTP requests for enabling, disabling and
+// reconfiguring venues at runtime
+type VenueController struct {
+	manager *venues.Manager
+}
+
+// NewVenueController creates a new VenueController with the given Manager
+func NewVenueController(manager *venues.Manager) *VenueController {
+	return &VenueController{manager: manager}
+}
+
+// ListHandler handles the GET /v1/admin/venues endpoint
+func (vc *VenueController) ListHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"venues": vc.manager.List()})
+}
+
+// UpdateHandler handles the PATCH /v1/admin/venues/:name endpoint, fields
+// left out of the body are left as they are
+func (vc *VenueController) UpdateHandler(c *fiber.Ctx) error {
+	var body struct {
+		Enabled *bool   `json:"enabled"`
+		Timeout *string `json:"timeout"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid venue", "code": "invalid_venue"})
+	}
+
+	change := venues.Change{Enabled: body.Enabled}
+	if body.Timeout != nil {
+		timeout, err := time.ParseDuration(*body.Timeout)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid timeout, expected a duration such as 3s",
+				"code":  "invalid_timeout",
+			})
+		}
+		change.Timeout = &timeout
+	}
+
+	var actor string
+	if key := auth.Key(c); key != nil {
+		actor = key.ID
+	}
+
+	venue, err := vc.manager.Update(c.UserContext(), c.Params("name"), change, actor)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error(), "code": errorCode(err)})
+	}
+
+	return c.JSON(fiber.Map{"venue": venue})
+}
+
+// errorStatus maps a Manager error to an HTTP status code
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, venues.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, venues.ErrInvalidTimeout):
+		return http.StatusBadRequest
+	case errors.Is(err, order.ErrLastVenue):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorCode maps a Manager error to the code sent along with it
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, venues.ErrNotFound):
+		return "venue_not_found"
+	case errors.Is(err, venues.ErrInvalidTimeout):
+		return "invalid_timeout"
+	case errors.Is(err, order.ErrLastVenue):
+		return "last_venue"
+	default:
+		return "internal_error"
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/rpc"
+	quotev1 "github.com/SmMistry/triumph-project/proto/quote/v1"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialGRPC serves a QuoteServer over an in-memory listener and connects to it
+func dialGRPC(t *testing.T, quoteServer *rpc.QuoteServer) *grpc.ClientConn {
+	server, _ := rpc.NewServer(quoteServer)
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCQuoteService(t *testing.T) {
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990}
+	kraken := &MockExchange{Name: "kraken", BuyPrice: 10010, SellPrice: 9995}
+	orderService := order.NewOrderService(coinbase, kraken)
+	poller := bbo.NewPoller(orderService, time.Hour)
+	conn := dialGRPC(t, rpc.NewQuoteServer(orderService, poller, 2))
+	client := quotev1.NewQuoteServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.GetQuote(ctx, &quotev1.GetQuoteRequest{Side: quotev1.Side_SIDE_BUY, Symbol: "BTC", Amount: 2})
+	require.NoError(t, err)
+	assert.Equal(t, quotev1.Side_SIDE_BUY, resp.Quote.Side)
+	assert.Equal(t, 10000.0, resp.Quote.Price)
+	assert.Equal(t, 20000.0, resp.Quote.UsdAmount)
+	assert.Equal(t, []string{"coinbase"}, resp.Quote.Exchanges)
+
+	// Invalid requests get the code matching the REST status
+	_, err = client.GetQuote(ctx, &quotev1.GetQuoteRequest{Side: quotev1.Side_SIDE_BUY, Symbol: "BTC", Amount: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.GetQuote(ctx, &quotev1.GetQuoteRequest{Symbol: "BTC", Amount: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.GetQuote(ctx, &quotev1.GetQuoteRequest{Side: quotev1.Side_SIDE_SELL, Symbol: "BTC", Amount: 1, Venues: []string{"nope"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "unknown venue")
+
+	market, err := client.GetMarket(ctx, &quotev1.GetMarketRequest{Symbol: "BTC"})
+	require.NoError(t, err)
+	assert.Equal(t, 9995.0, market.Market.BestBid.Price)
+	assert.Equal(t, 10000.0, market.Market.BestAsk.Price)
+	assert.Len(t, market.Market.Venues, 2)
+
+	// Streams start with the latest best bid and offer, then follow changes.
+	// Symbols are normalized, so both spellings share one feed
+	stream, err := client.WatchBBO(ctx, &quotev1.WatchBBORequest{Symbols: []string{"btc", "BTC-USD"}})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(poller.Symbols()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	poller.Poll(ctx)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), event.Sequence)
+	assert.Equal(t, "BTC", event.Market.Symbol)
+	assert.Equal(t, 10000.0, event.Market.BestAsk.Price)
+
+	coinbase.BuyPrice = 9998
+	poller.Poll(ctx)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), event.Sequence)
+	assert.Equal(t, 9998.0, event.Market.BestAsk.Price)
+
+	// Too many symbols are only reported on the first receive
+	tooMany, err := client.WatchBBO(ctx, &quotev1.WatchBBORequest{Symbols: []string{"BTC", "ETH", "SOL"}})
+	require.NoError(t, err)
+	_, err = tooMany.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: quotev1.QuoteService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.Status)
+}
+
+func TestGRPCAuth(t *testing.T) {
+	store, err := auth.Open(filepath.Join(t.TempDir(), "keys.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, quoteToken, err := store.Create("quotes", []auth.Scope{auth.ScopeQuote}, 0, 1)
+	require.NoError(t, err)
+	_, tradeToken, err := store.Create("trades", []auth.Scope{auth.ScopeTrade}, 0, 0)
+	require.NoError(t, err)
+
+	orderService := order.NewOrderService(&MockExchange{Name: "kraken", BuyPrice: 10005, SellPrice: 9985})
+	quoteServer := rpc.NewQuoteServer(orderService, bbo.NewPoller(orderService, time.Hour), 2)
+	quoteServer.SetAuthenticator(auth.NewAuthenticator(store))
+	conn := dialGRPC(t, quoteServer)
+	client := quotev1.NewQuoteServiceClient(conn)
+
+	getQuote := func(md ...string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ctx = metadata.AppendToOutgoingContext(ctx, md...)
+		_, err := client.GetQuote(ctx, &quotev1.GetQuoteRequest{Side: quotev1.Side_SIDE_BUY, Symbol: "BTC", Amount: 1})
+		return err
+	}
+
+	// Failures carry the REST error code as their reason
+	assertFails := func(code codes.Code, reason string, err error) {
+		t.Helper()
+		st := status.Convert(err)
+		assert.Equal(t, code, st.Code())
+		require.Len(t, st.Details(), 1)
+		assert.Equal(t, reason, st.Details()[0].(*errdetails.ErrorInfo).Reason)
+	}
+	assertFails(codes.Unauthenticated, "missing_api_key", getQuote())
+	assertFails(codes.Unauthenticated, "invalid_api_key", getQuote("authorization", "Bearer "+quoteToken+"x"))
+	assertFails(codes.PermissionDenied, "insufficient_scope", getQuote("x-api-key", tradeToken))
+	assert.NoError(t, getQuote("authorization", "Bearer "+quoteToken))
+	assertFails(codes.ResourceExhausted, "quota_exceeded", getQuote("authorization", "Bearer "+quoteToken))
+
+	// Health checks don't need a key
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+}
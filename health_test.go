@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/health"
+	arbitrageservice "github.com/SmMistry/triumph-project/services/arbitrage"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthProbes(t *testing.T) {
+	coinbase := &MockCountingExchange{MockExchange: MockExchange{Name: "coinbase", BuyPrice: 10010, SellPrice: 9990}}
+	kraken := &MockCountingExchange{MockExchange: MockExchange{Name: "kraken", Err: fmt.Errorf("kraken error")}}
+	orderService := order.NewOrderService(coinbase, kraken)
+
+	app := fiber.New()
+	healthController := health.NewHealthController(orderService, 2, "BTC")
+	healthController.SetProbeInterval(50 * time.Millisecond)
+	app.Get("/healthz", healthController.LivenessHandler)
+	app.Get("/readyz", healthController.ReadinessHandler)
+
+	get := func(path string) (int, string) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("/healthz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"status":"ok"}`, body)
+
+	// Not ready until both venues have answered, checks probe them at most
+	// once per interval
+	status, body = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.JSONEq(t, `{"status":"not ready","venues":["coinbase"],"minVenues":2}`, body)
+	assert.Equal(t, 1, kraken.Calls)
+
+	kraken.Err = nil
+	kraken.BuyPrice, kraken.SellPrice = 10005, 9985
+	status, _ = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, 1, kraken.Calls)
+
+	time.Sleep(60 * time.Millisecond)
+	status, body = get("/readyz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"status":"ready","venues":["coinbase","kraken"],"minVenues":2}`, body)
+
+	// Once ready the venues aren't probed anymore
+	status, _ = get("/readyz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 2, kraken.Calls)
+
+	// Disabled venues don't count
+	require.NoError(t, orderService.SetVenueEnabled("kraken", false))
+	status, body = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.JSONEq(t, `{"status":"not ready","venues":["coinbase"],"minVenues":2}`, body)
+	require.NoError(t, orderService.SetVenueEnabled("kraken", true))
+
+	// Neither do answers older than the window
+	orderService.SetAnswerWindow(50 * time.Millisecond)
+	kraken.Err = fmt.Errorf("kraken error")
+	time.Sleep(100 * time.Millisecond)
+	status, body = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.JSONEq(t, `{"status":"not ready","venues":["coinbase"],"minVenues":2}`, body)
+}
+
+func TestScannerShutdown(t *testing.T) {
+	orderService := order.NewOrderService(&MockExchange{Name: "coinbase", BuyPrice: 10010, SellPrice: 9990})
+	scanner := arbitrageservice.NewScanner(orderService, []string{"BTC"}, time.Hour, 0)
+
+	events, unsubscribe := scanner.Subscribe()
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scanner.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scanner didn't stop")
+	}
+
+	// Subscriptions end with the scanner so streams can finish
+	_, open := <-events
+	assert.False(t, open)
+	late, _ := scanner.Subscribe()
+	_, open = <-late
+	assert.False(t, open)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/controllers/quotes"
+	"github.com/SmMistry/triumph-project/services/history"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestQuoteHistory(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "quotes.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990}
+	kraken := &MockExchange{Name: "kraken", Err: fmt.Errorf("kraken error")}
+	orderService := order.NewOrderService(coinbase, kraken)
+	orderService.SetRecorder(store)
+
+	app := fiber.New()
+	orderController := orders.NewOrderController(orderService)
+	quoteController := quotes.NewQuoteController(orderService, store)
+	app.Get("/buy", orderController.BuyHandler)
+	app.Get("/sell", orderController.SellHandler)
+	app.Get("/v1/quotes", quoteController.ListHandler)
+	app.Get("/v1/quotes/:id", quoteController.GetHandler)
+
+	get := func(url string, v any) int {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, url, nil))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, v))
+		return resp.StatusCode
+	}
+
+	// Produce three BTC quotes and one ETH quote
+	var quote struct {
+		QuoteID string `json:"quoteId"`
+	}
+	for _, url := range []string{"/buy?amount=1&symbol=BTC", "/sell?amount=2&symbol=BTC", "/buy?amount=3&symbol=ETH", "/buy?amount=4&symbol=BTC"} {
+		assert.Equal(t, http.StatusOK, get(url, &quote))
+		assert.NotEmpty(t, quote.QuoteID)
+	}
+
+	// Page through the BTC quotes two at a time, the symbol is normalized
+	// like a quote's
+	var page history.Page
+	assert.Equal(t, http.StatusOK, get("/v1/quotes?symbol=btc&limit=2", &page))
+	if assert.Len(t, page.Quotes, 2) {
+		first := page.Quotes[0]
+		assert.Equal(t, order.SideBuy, first.Request.Side)
+		assert.Equal(t, 1.0, first.Request.Amount)
+		assert.Equal(t, []string{"coinbase"}, first.Exchanges)
+		assert.Equal(t, 10000.0, first.USDAmount)
+		if assert.Len(t, first.Venues, 2) {
+			assert.Equal(t, order.VenueStatus{Exchange: "coinbase", Status: order.StatusOK, Bid: 9990, Ask: 10000, LatencyMs: first.Venues[0].LatencyMs}, first.Venues[0])
+			assert.Equal(t, order.StatusError, first.Venues[1].Status)
+			assert.Equal(t, "kraken error", first.Venues[1].Error)
+		}
+		assert.Equal(t, order.SideSell, page.Quotes[1].Request.Side)
+	}
+	assert.NotEmpty(t, page.Next)
+
+	var next history.Page
+	assert.Equal(t, http.StatusOK, get("/v1/quotes?symbol=btc-usd&limit=2&cursor="+page.Next, &next))
+	if assert.Len(t, next.Quotes, 1) {
+		assert.Equal(t, 4.0, next.Quotes[0].Request.Amount)
+	}
+	assert.Empty(t, next.Next)
+
+	// Quotes can be looked up by ID and time filters exclude everything
+	// outside the range
+	var record order.QuoteRecord
+	assert.Equal(t, http.StatusOK, get("/v1/quotes/"+quote.QuoteID, &record))
+	assert.Equal(t, "BTC", record.Request.Symbol)
+
+	var empty history.Page
+	assert.Equal(t, http.StatusOK, get("/v1/quotes?to=2000-01-01T00:00:00Z", &empty))
+	assert.Empty(t, empty.Quotes)
+
+	var failed map[string]string
+	assert.Equal(t, http.StatusBadRequest, get("/v1/quotes?from=yesterday", &failed))
+	assert.Equal(t, map[string]string{"error": "invalid time: from must be an RFC 3339 timestamp or unix seconds", "code": "invalid_time"}, failed)
+	assert.Equal(t, http.StatusBadRequest, get("/v1/quotes?limit=0", &failed))
+	assert.Equal(t, "invalid_limit", failed["code"])
+	assert.Equal(t, http.StatusBadRequest, get("/v1/quotes?symbol=B!TC", &failed))
+	assert.Equal(t, "invalid_symbol", failed["code"])
+	assert.Equal(t, http.StatusBadRequest, get("/v1/quotes?cursor=x", &failed))
+	assert.Equal(t, map[string]string{"error": "invalid cursor", "code": "invalid_cursor"}, failed)
+	assert.Equal(t, http.StatusNotFound, get("/v1/quotes/missing", &failed))
+	assert.Equal(t, map[string]string{"error": "quote not found", "code": "quote_not_found"}, failed)
+}
+
+func TestQuoteHistoryRejected(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "quotes.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	orderService := order.NewOrderService(&MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990})
+	orderService.SetRecorder(store)
+
+	// Rejected quotes are kept with the symbol as the client sent it, even
+	// when there was none
+	for _, symbol := range []string{"BT C!", ""} {
+		_, err := orderService.Quote(context.Background(), order.QuoteRequest{Side: order.SideBuy, Symbol: symbol, Amount: 1})
+		require.ErrorIs(t, err, order.ErrInvalidSymbol)
+	}
+
+	page, err := store.Query(history.Query{})
+	require.NoError(t, err)
+	require.Len(t, page.Quotes, 2)
+	assert.Equal(t, "BT C!", page.Quotes[0].Request.Symbol)
+	assert.Contains(t, page.Quotes[0].Error, "invalid symbol")
+	assert.Equal(t, "", page.Quotes[1].Request.Symbol)
+
+	page, err = store.Query(history.Query{Symbol: "BT C!"})
+	require.NoError(t, err)
+	assert.Len(t, page.Quotes, 1)
+}
+
+func TestQuoteHistoryIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.db")
+	store, err := history.Open(path)
+	require.NoError(t, err)
+
+	record := &order.QuoteRecord{ID: "q1", Timestamp: time.Now(), Request: order.QuoteRequest{Side: order.SideBuy, Symbol: "BTC", Amount: 1}}
+	require.NoError(t, store.RecordQuote(record))
+	got, err := store.Get("q1")
+	require.NoError(t, err)
+	assert.Equal(t, "BTC", got.Request.Symbol)
+	require.NoError(t, store.Close())
+
+	// Stores written before IDs were indexed are indexed when opened
+	db, err := bolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error { return tx.DeleteBucket([]byte("by_id")) }))
+	require.NoError(t, db.Close())
+
+	store, err = history.Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+	got, err = store.Get("q1")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, got.Request.Amount)
+	_, err = store.Get("q2")
+	assert.ErrorIs(t, err, history.ErrNotFound)
+}
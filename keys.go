@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/config"
+)
+
+// keysUsage documents the keys admin command
+const keysUsage = `usage: triumph [--config file] keys <command>
+
+commands:
+  create --name NAME --scopes quote,trade,admin [--rate-limit N] [--daily-quota N]
+  list
+  revoke ID
+
+The key store can't be opened while the server is running, use the
+/v1/admin/keys endpoints with an admin key instead.
+`
+
+// runKeys runs the keys admin command against the configured key store
+func runKeys(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, keysUsage)
+		return errors.New("missing keys command")
+	}
+
+	store, err := auth.Open(cfg.Storage.Keys)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("keys create", flag.ContinueOnError)
+		flags.SetOutput(out)
+		name := flags.String("name", "", "who the key is for")
+		scopes := flags.String("scopes", string(auth.ScopeQuote), "comma separated scopes: quote, trade, admin")
+		rateLimit := flags.Int("rate-limit", 0, "requests allowed per minute, 0 for unlimited")
+		dailyQuota := flags.Int("daily-quota", 0, "requests allowed per UTC day, 0 for unlimited")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *name == "" {
+			return errors.New("--name is required")
+		}
+
+		parsed, err := auth.ParseScopes(*scopes)
+		if err != nil {
+			return err
+		}
+		key, token, err := store.Create(*name, parsed, *rateLimit, *dailyQuota)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "created key %s for %s\n", key.ID, key.Name)
+		fmt.Fprintf(out, "token (shown once, store it safely): %s\n", token)
+		return nil
+
+	case "list":
+		keys, err := store.List()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSCOPES\tRATE LIMIT\tDAILY QUOTA\tCREATED\tSTATUS")
+		for _, key := range keys {
+			scopes := make([]string, len(key.Scopes))
+			for i, scope := range key.Scopes {
+				scopes[i] = string(scope)
+			}
+			status := "active"
+			if key.RevokedAt != nil {
+				status = "revoked"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, strings.Join(scopes, ","),
+				limit(key.RateLimit), limit(key.DailyQuota), key.CreatedAt.Format("2006-01-02 15:04"), status)
+		}
+		return w.Flush()
+
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New("usage: keys revoke ID")
+		}
+		key, err := store.Revoke(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "revoked key %s for %s\n", key.ID, key.Name)
+		return nil
+
+	default:
+		fmt.Fprint(out, keysUsage)
+		return fmt.Errorf("unknown keys command %q", args[0])
+	}
+}
+
+// limit formats a rate limit or quota, where zero means unlimited
+func limit(n int) string {
+	if n == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprint(n)
+}
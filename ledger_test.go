@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/accounts"
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/history"
+	"github.com/SmMistry/triumph-project/services/ledger"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaperTrading(t *testing.T) {
+	dir := t.TempDir()
+	store, err := history.Open(filepath.Join(dir, "quotes.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	coinbase := &MockFeeExchange{Fee: 0.01, MockBookExchange: MockBookExchange{
+		Name: "coinbase",
+		Book: exchange.OrderBook{
+			Bids: []exchange.Level{{Price: 9900, Size: 10}},
+			Asks: []exchange.Level{{Price: 10000, Size: 1}, {Price: 10200, Size: 10}},
+		},
+	}}
+	kraken := &MockExchange{Name: "kraken", BuyPrice: 10100, SellPrice: 9800}
+	orderService := order.NewOrderService(coinbase, kraken)
+	orderService.SetRecorder(store)
+
+	paperLedger, err := ledger.Open(filepath.Join(dir, "ledger.db"), orderService, store)
+	require.NoError(t, err)
+	defer paperLedger.Close()
+
+	app := fiber.New()
+	orderController := orders.NewOrderController(orderService)
+	accountController := accounts.NewAccountController(paperLedger)
+	app.Get("/buy", orderController.BuyHandler)
+	app.Get("/sell", orderController.SellHandler)
+	app.Get("/v1/accounts/:id", accountController.AccountHandler)
+	app.Post("/v1/accounts/:id/deposits", accountController.DepositHandler)
+	app.Post("/v1/accounts/:id/fills", accountController.FillHandler)
+
+	do := func(method, url, body string, v any) int {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, v), string(data))
+		return resp.StatusCode
+	}
+	accept := func(quoteURL string) (int, map[string]any) {
+		var quote struct {
+			QuoteID string `json:"quoteId"`
+		}
+		require.Equal(t, http.StatusOK, do(http.MethodGet, quoteURL, "", &quote))
+
+		var fill map[string]any
+		status := do(http.MethodPost, "/v1/accounts/alice/fills", `{"quoteId":"`+quote.QuoteID+`"}`, &fill)
+		return status, fill
+	}
+
+	var txn ledger.Transaction
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/v1/accounts/alice/deposits", `{"asset":"usd","amount":25000}`, &txn))
+
+	// Buying 2 BTC walks Coinbase's book, 10000 + 10200 plus a 1% fee
+	status, fill := accept("/buy?amount=2&symbol=BTC")
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "coinbase", fill["exchange"])
+	assert.Equal(t, 10100.0, fill["avgPrice"])
+	assert.InDelta(t, 202.0, fill["fee"], 1e-9)
+
+	// Selling 1 BTC at Coinbase's 9900 bid less the fee
+	status, _ = accept("/sell?amount=1&symbol=BTC")
+	assert.Equal(t, http.StatusCreated, status)
+
+	var account ledger.Account
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/v1/accounts/alice", "", &account))
+	assert.InDelta(t, 25000-20402+9801, account.Balances["USD"], 1e-6)
+	assert.InDelta(t, 1, account.Balances["BTC"], 1e-9)
+	if assert.Len(t, account.Positions, 1) {
+		pos := account.Positions[0]
+		assert.InDelta(t, 1, pos.Quantity, 1e-9)
+		assert.InDelta(t, 10201, pos.AvgCost, 1e-6)
+		assert.InDelta(t, 9801-10201, pos.RealizedPnL, 1e-6)
+		assert.Equal(t, 9900.0, pos.MarketPrice)
+		assert.InDelta(t, 9900-10201, pos.UnrealizedPnL, 1e-6)
+	}
+
+	// Selling more than the account holds is rejected
+	status, fill = accept("/sell?amount=5&symbol=BTC")
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, "insufficient funds: alice BTC", fill["error"])
+
+	// A quote can only be accepted once
+	var quote struct {
+		QuoteID string `json:"quoteId"`
+	}
+	require.Equal(t, http.StatusOK, do(http.MethodGet, "/sell?amount=0.1&symbol=BTC", "", &quote))
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/v1/accounts/alice/fills", `{"quoteId":"`+quote.QuoteID+`"}`, &fill))
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/v1/accounts/alice/fills", `{"quoteId":"`+quote.QuoteID+`"}`, &fill))
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/v1/accounts/alice/fills", `{"quoteId":"missing"}`, &fill))
+}
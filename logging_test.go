@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogging(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "info")
+	require.NoError(t, err)
+
+	orderService := order.NewOrderService(
+		&MockExchange{Name: "coinbase", Err: fmt.Errorf("coinbase error")},
+		&MockExchange{Name: "kraken", BuyPrice: 10005, SellPrice: 9985},
+	)
+	orderService.SetLogger(logger)
+	orderController := orders.NewOrderController(orderService)
+	orderController.SetLogger(logger)
+
+	app := fiber.New()
+	app.Use(logging.Middleware(logger))
+	app.Get("/buy", orderController.BuyHandler)
+
+	// A request ID sent by the caller is propagated
+	req := httptest.NewRequest(http.MethodGet, "/buy?symbol=BTC&amount=1", nil)
+	req.Header.Set(logging.RequestIDHeader, "caller-id")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "caller-id", resp.Header.Get(logging.RequestIDHeader))
+
+	records := readRecords(t, &out)
+	require.Len(t, records, 3)
+	for _, record := range records {
+		assert.Equal(t, "caller-id", record["request_id"])
+	}
+	assert.Equal(t, "WARN", records[0]["level"])
+	assert.Equal(t, "failed to get price from exchange", records[0]["msg"])
+	assert.Equal(t, "coinbase", records[0]["exchange"])
+	assert.Equal(t, "quote", records[1]["msg"])
+	assert.Equal(t, "request", records[2]["msg"])
+	assert.Equal(t, "/buy", records[2]["route"])
+	assert.Equal(t, float64(http.StatusOK), records[2]["status"])
+
+	// Otherwise one is generated
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/buy?symbol=BTC&amount=1", nil))
+	require.NoError(t, err)
+	id := resp.Header.Get(logging.RequestIDHeader)
+	assert.NotEmpty(t, id)
+	for _, record := range readRecords(t, &out) {
+		assert.Equal(t, id, record["request_id"])
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "warn")
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+	records := readRecords(t, &out)
+	require.Len(t, records, 1)
+	assert.Equal(t, "kept", records[0]["msg"])
+
+	_, err = logging.New(&out, "loud")
+	assert.EqualError(t, err, `invalid log level "loud"`)
+}
+
+// readRecords decodes and consumes the JSON log records written to out
+func readRecords(t *testing.T, out *bytes.Buffer) []map[string]any {
+	records := []map[string]any{}
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		record := map[string]any{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	return records
+}
@@ -1,41 +1,588 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
-	
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/accounts"
+	alertcontroller "github.com/SmMistry/triumph-project/controllers/alerts"
+	"github.com/SmMistry/triumph-project/controllers/arbitrage"
+	candlecontroller "github.com/SmMistry/triumph-project/controllers/candles"
+	"github.com/SmMistry/triumph-project/controllers/docs"
+	"github.com/SmMistry/triumph-project/controllers/health"
+	"github.com/SmMistry/triumph-project/controllers/keys"
+	"github.com/SmMistry/triumph-project/controllers/markets"
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/controllers/quotes"
+	"github.com/SmMistry/triumph-project/controllers/rpc"
+	"github.com/SmMistry/triumph-project/controllers/socket"
+	"github.com/SmMistry/triumph-project/controllers/stream"
+	venuecontroller "github.com/SmMistry/triumph-project/controllers/venues"
+	"github.com/SmMistry/triumph-project/services/alerts"
+	arbitrageservice "github.com/SmMistry/triumph-project/services/arbitrage"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/candles"
+	"github.com/SmMistry/triumph-project/services/config"
 	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/history"
+	"github.com/SmMistry/triumph-project/services/ledger"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/metrics"
+	"github.com/SmMistry/triumph-project/services/openapi"
 	"github.com/SmMistry/triumph-project/services/order"
-	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/tracing"
+	"github.com/SmMistry/triumph-project/services/venues"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
 )
 
-func initializeService() *order.OrderService {
-	// Initialize the exchanges
-	coinbase := &exchange.CoinbaseExchange{}
-	kraken := &exchange.KrakenExchange{}
+// Command line flags, the config file can also be set through CONFIG_FILE
+var (
+	configPath  = flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	printConfig = flag.Bool("print-config", false, "print the effective config with secrets redacted and exit")
+)
+
+func initializeConfig() *config.Config {
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	return cfg
+}
+
+func initializeLogger(cfg *config.Config) *slog.Logger {
+	logger, err := logging.New(os.Stderr, cfg.LogLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Send anything still using the log package through the same handler
+	slog.SetDefault(logger)
+	return logger
+}
+
+func initializeExchanges(cfg *config.Config, logger *slog.Logger) []exchange.Exchange {
+	exchanges, err := cfg.Exchanges(logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return exchanges
+}
+
+func initializeService(cfg *config.Config, logger *slog.Logger) *order.OrderService {
+	orderService := order.NewOrderService(initializeExchanges(cfg, logger)...)
+	orderService.SetLogger(logger)
+
+	// Price symbols a venue doesn't list against USD through these assets
+	orderService.SetIntermediates(cfg.Routing.Intermediates...)
+
+	// A client's preferred venue wins unless another one is meaningfully better
+	orderService.SetPriceImprovementBps(cfg.Routing.PriceImprovementBps)
+
+	// Reuse venue prices for a short while under bursts of requests
+	orderService.SetCacheTTL(cfg.Cache.PriceTTL)
+
+	// Normalize symbols and check amounts against the venues' size rules
+	orderService.SetSymbolAliases(cfg.SymbolAliases())
+	orderService.SetMaxAmount(cfg.Symbols.MaxAmount)
+	orderService.SetSymbolRulesTTL(cfg.Symbols.RulesTTL)
+
+	// Only recent answers count towards readiness
+	orderService.SetAnswerWindow(cfg.Readiness.Window)
+
+	return orderService
+}
+
+func initializeOrderController(orderService *order.OrderService, logger *slog.Logger) *orders.OrderController {
+	orderController := orders.NewOrderController(orderService)
+	orderController.SetLogger(logger)
+	return orderController
+}
+
+func initializeMarketController(orderService *order.OrderService) *markets.MarketController {
+	return markets.NewMarketController(orderService)
+}
+
+func initializeArbitrageScanner(cfg *config.Config, orderService *order.OrderService, logger *slog.Logger) *arbitrageservice.Scanner {
+	scanner := arbitrageservice.NewScanner(orderService, cfg.Arbitrage.Symbols, cfg.Arbitrage.Interval, cfg.Arbitrage.MinEdgeBps)
+	scanner.SetLogger(logger)
+	return scanner
+}
+
+func initializeQuoteHistory(cfg *config.Config, orderService *order.OrderService) *history.Store {
+	store, err := history.Open(cfg.Storage.Quotes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	orderService.SetRecorder(store)
+	return store
+}
+
+func initializeCandleStore(cfg *config.Config) *candles.Store {
+	store, err := candles.Open(cfg.Storage.Candles)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return store
+}
+
+func initializeLedger(cfg *config.Config, orderService *order.OrderService, quoteHistory *history.Store) *ledger.Ledger {
+	paperLedger, err := ledger.Open(cfg.Storage.Ledger, orderService, quoteHistory)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return paperLedger
+}
+
+func initializeTraders(cfg *config.Config, logger *slog.Logger) []exchange.Trader {
+	traders := []exchange.Trader{}
+	for _, name := range cfg.EnabledVenues {
+		venue := cfg.Venues[name]
+		if venue.APIKey == "" {
+			logger.Warn("order placement disabled", "exchange", name, "error", exchange.ErrNoCredentials)
+			continue
+		}
+
+		client := &http.Client{Timeout: venue.Timeout}
+		switch venue.AdapterName(name) {
+		case "coinbase":
+			traders = append(traders, &exchange.CoinbaseTrader{
+				Name:    name,
+				BaseURL: venue.TradeURL,
+				KeyName: venue.APIKey,
+				Secret:  venue.APISecret,
+				Client:  client,
+				Logger:  logger,
+			})
+		case "kraken":
+			traders = append(traders, &exchange.KrakenTrader{
+				Name:    name,
+				BaseURL: venue.TradeURL,
+				Key:     venue.APIKey,
+				Secret:  venue.APISecret,
+				Live:    venue.Live,
+				Client:  client,
+				Logger:  logger,
+			})
+		default:
+			logger.Warn("order placement disabled", "exchange", name, "error", "the adapter cannot trade")
+		}
+	}
+	return traders
+}
+
+func initializeMetrics(orderService *order.OrderService) *metrics.Metrics {
+	m := metrics.New()
+	orderService.SetObserver(m)
+	return m
+}
+
+func initializeTracing(cfg *config.Config) func(context.Context) error {
+	shutdown, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.File)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return shutdown
+}
+
+func initializeSpec() *openapi.Spec {
+	spec, err := openapi.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return spec
+}
+
+func initializeAlertStore(cfg *config.Config) *alerts.Store {
+	store, err := alerts.Open(cfg.Storage.Alerts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return store
+}
+
+func initializeVenueManager(cfg *config.Config, orderService *order.OrderService, logger *slog.Logger) *venues.Manager {
+	timeouts := map[string]time.Duration{}
+	for _, name := range cfg.EnabledVenues {
+		timeouts[name] = cfg.Venues[name].Timeout
+	}
+
+	// Timeout changes recreate the venue as configured, with the new timeout
+	build := func(name string, timeout time.Duration) (exchange.Exchange, error) {
+		venue := cfg.Venues[name]
+		venue.Timeout = timeout
+		return venue.Exchange(name, logger)
+	}
+
+	manager := venues.NewManager(orderService, build, timeouts)
+	manager.SetLogger(logger)
+	return manager
+}
+
+func initializeVenueStore(cfg *config.Config) *venues.Store {
+	store, err := venues.Open(cfg.Storage.Venues)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return store
+}
+
+func initializeKeyStore(cfg *config.Config) *auth.Store {
+	store, err := auth.Open(cfg.Storage.Keys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return store
+}
+
+// allow lets every request through, it stands in for the scope checks when
+// authentication is disabled
+func allow(c *fiber.Ctx) error {
+	return c.Next()
+}
+
+func initializeQuoteServer(cfg *config.Config, orderService *order.OrderService, poller *bbo.Poller, authenticator *auth.Authenticator, logger *slog.Logger) *rpc.QuoteServer {
+	quoteServer := rpc.NewQuoteServer(orderService, poller, cfg.Stream.MaxSymbols)
+	quoteServer.SetLogger(logger)
+	if authenticator != nil {
+		quoteServer.SetAuthenticator(authenticator)
+	}
+	return quoteServer
+}
+
+// stopGRPC stops the gRPC server, letting in-flight calls finish for up to
+// timeout before cutting them off
+func stopGRPC(server *grpc.Server, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
 
-	return order.NewOrderService(coinbase, kraken)
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		server.Stop()
+		return false
+	}
 }
 
-func initializeOrderController(orderService *order.OrderService) *orders.OrderController{
-	return orders.NewOrderController(orderService)
+func initializeOrderStore(cfg *config.Config, orderService *order.OrderService, logger *slog.Logger) *order.OrderStore {
+	store, err := order.OpenOrderStore(cfg.Storage.Orders)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	orderService.SetOrderStore(store)
+	orderService.SetTraders(initializeTraders(cfg, logger)...)
+	return store
 }
 
 func main() {
+	// Load and validate the config file and environment
+	flag.Parse()
+	cfg := initializeConfig()
+	if *printConfig {
+		if err := cfg.Redacted().Write(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Manage API keys
+	if flag.Arg(0) == "keys" {
+		if err := runKeys(cfg, flag.Args()[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves until SIGINT or SIGTERM, then drains in-flight requests, stops
+// the background workers and closes the stores
+func run(cfg *config.Config) error {
+	// Log structured records, tagged with the ID of the request they belong to
+	logger := initializeLogger(cfg)
+
+	// Stop when asked to by the orchestrator or the terminal
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers stop with ctx, and are waited for before the stores
+	// they write to are closed
+	var workers sync.WaitGroup
+	start := func(worker func(context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker(ctx)
+		}()
+	}
+
+	// Trace requests through the service and out to the exchanges
+	shutdownTracing := initializeTracing(cfg)
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		shutdownTracing(flushCtx)
+	}()
+
 	// Create the order service
-	orderService := initializeService()
+	orderService := initializeService(cfg, logger)
+
+	// Venues can be disabled and reconfigured at runtime, changes made before
+	// a restart are restored when they are kept
+	venueManager := initializeVenueManager(cfg, orderService, logger)
+	if cfg.Storage.Venues != "" {
+		venueStore := initializeVenueStore(cfg)
+		defer venueStore.Close()
 
-	// Create the order controller
-	orderController := initializeOrderController(orderService)
+		if err := venueManager.Restore(ctx, venueStore); err != nil {
+			return err
+		}
+	}
+
+	// Track placed orders through their lifecycle
+	orderStore := initializeOrderStore(cfg, orderService, logger)
+	defer orderStore.Close()
+
+	// Create the controllers
+	orderController := initializeOrderController(orderService, logger)
+	marketController := initializeMarketController(orderService)
+	healthController := health.NewHealthController(orderService, cfg.Readiness.MinVenues, cfg.Readiness.ProbeSymbol)
+	healthController.SetProbeInterval(cfg.Readiness.ProbeInterval)
 
 	// Initialize the Fiber app
 	app := fiber.New()
 
+	// Probes are answered before any middleware so they are never rate
+	// limited, logged or traced
+	app.Get("/healthz", healthController.LivenessHandler)
+	app.Get("/readyz", healthController.ReadinessHandler)
+
+	app.Use(tracing.Middleware)
+	app.Use(logging.Middleware(logger))
+
+	// Collect request, exchange and routing metrics
+	if cfg.Features.Metrics {
+		serviceMetrics := initializeMetrics(orderService)
+		app.Use(serviceMetrics.Middleware)
+		app.Get("/metrics", serviceMetrics.Handler())
+	}
+
+	// Limit the requests each client can make
+	if cfg.RateLimit.Requests > 0 {
+		app.Use(limiter.New(limiter.Config{
+			Max:        cfg.RateLimit.Requests,
+			Expiration: cfg.RateLimit.Window,
+		}))
+	}
+
+	// Serve the OpenAPI document, and reject requests that don't conform to it
+	// before they reach a handler
+	spec := initializeSpec()
+	docsController := docs.NewDocsController(spec)
+	app.Get("/openapi.json", docsController.SpecHandler)
+	app.Get("/docs", docsController.UIHandler)
+	app.Use(spec.Middleware)
+
+	// Every API endpoint requires a key with the right scope, checked along
+	// with its rate limit and quota before the handler runs. Streams need the
+	// quote scope and also take the key as a query parameter, for browsers
+	quoteScope, tradeScope, adminScope, streamScope := allow, allow, allow, allow
+	var authenticator *auth.Authenticator
+	if cfg.Features.Auth {
+		keyStore := initializeKeyStore(cfg)
+		defer keyStore.Close()
+
+		authenticator = auth.NewAuthenticator(keyStore)
+		quoteScope = authenticator.Require(auth.ScopeQuote)
+		tradeScope = authenticator.Require(auth.ScopeTrade)
+		adminScope = authenticator.Require(auth.ScopeAdmin)
+		streamScope = authenticator.RequireStream(auth.ScopeQuote)
+
+		keyController := keys.NewKeyController(keyStore)
+		app.Post("/v1/admin/keys", adminScope, keyController.CreateHandler)
+		app.Get("/v1/admin/keys", adminScope, keyController.ListHandler)
+		app.Delete("/v1/admin/keys/:id", adminScope, keyController.RevokeHandler)
+
+		venueController := venuecontroller.NewVenueController(venueManager)
+		app.Get("/v1/admin/venues", adminScope, venueController.ListHandler)
+		app.Patch("/v1/admin/venues/:name", adminScope, venueController.UpdateHandler)
+	}
+
 	// Define the API routes
-	app.Get("/buy", orderController.BuyHandler)
-	app.Get("/sell", orderController.SellHandler)
+	app.Get("/buy", quoteScope, orderController.BuyHandler)
+	app.Get("/sell", quoteScope, orderController.SellHandler)
+	app.Post("/v1/orders", tradeScope, orderController.PlaceHandler)
+	app.Get("/v1/orders/:id", tradeScope, orderController.GetOrderHandler)
+	app.Delete("/v1/orders/:id", tradeScope, orderController.CancelHandler)
+	app.Get("/v1/markets/:symbol", quoteScope, marketController.MarketHandler)
+
+	// Keep every quote for best execution audits
+	var quoteHistory *history.Store
+	if cfg.Features.QuoteHistory {
+		quoteHistory = initializeQuoteHistory(cfg, orderService)
+		defer quoteHistory.Close()
+
+		quoteController := quotes.NewQuoteController(orderService, quoteHistory)
+		app.Get("/v1/quotes", quoteScope, quoteController.ListHandler)
+		app.Get("/v1/quotes/:id", quoteScope, quoteController.GetHandler)
+	}
+
+	// Start recording prices for candles
+	if cfg.Features.Candles {
+		candleHistory := initializeCandleStore(cfg)
+		defer candleHistory.Close()
+
+		candleRecorder := candles.NewRecorder(orderService, candleHistory, cfg.Candles.Symbols, cfg.Candles.Interval)
+		candleRecorder.SetLogger(logger)
+		candleRecorder.SetRetention(cfg.Candles.Retention)
+		start(candleRecorder.Run)
+
+		candleController := candlecontroller.NewCandleController(orderService, candleHistory)
+		app.Get("/v1/candles/:symbol", quoteScope, candleController.CandlesHandler)
+	}
+
+	// Start the arbitrage scanner, its streams end when it stops
+	if cfg.Features.Arbitrage {
+		scanner := initializeArbitrageScanner(cfg, orderService, logger)
+		start(scanner.Run)
+
+		arbitrageController := arbitrage.NewArbitrageController(scanner)
+		app.Get("/v1/arbitrage", quoteScope, arbitrageController.ListHandler)
+		app.Get("/v1/arbitrage/stream", streamScope, arbitrageController.StreamHandler)
+	}
 
-	// Start the server
-	log.Fatal(app.Listen(":4000"))
-}
\ No newline at end of file
+	// Best bid/offer changes are polled once however many SSE, WebSocket and
+	// gRPC clients listen
+	var poller *bbo.Poller
+	if cfg.Features.Stream || cfg.Features.GRPC {
+		poller = bbo.NewPoller(orderService, cfg.Stream.Interval)
+		poller.SetLogger(logger)
+		start(poller.Run)
+	}
+
+	// Stream best bid/offer changes over SSE and WebSockets
+	var socketController *socket.SocketController
+	if cfg.Features.Stream {
+		streamController := stream.NewStreamController(orderService, poller, cfg.Stream.MaxSymbols)
+		app.Get("/v1/stream/bbo", streamScope, streamController.BBOHandler)
+
+		socketController = socket.NewSocketController(orderService, poller, cfg.Stream.MaxSymbols)
+		socketController.SetLogger(logger)
+		if authenticator != nil {
+			socketController.SetAuthenticator(authenticator)
+		}
+		app.Get("/v1/ws", streamScope, socketController.Handler)
+	}
+
+	// Evaluate price alerts and notify their webhooks
+	if cfg.Features.Alerts {
+		alertStore := initializeAlertStore(cfg)
+		defer alertStore.Close()
+		alertStore.SetOrderService(orderService)
+
+		notifier := alerts.NewNotifier(alertStore, cfg.Alerts.MaxAttempts, cfg.Alerts.Backoff, cfg.Alerts.Timeout)
+		notifier.SetAllowPrivateNetworks(cfg.Alerts.AllowPrivateNetworks)
+		notifier.SetLogger(logger)
+		engine := alerts.NewEngine(orderService, alertStore, notifier, cfg.Alerts.Interval)
+		engine.SetLogger(logger)
+		start(engine.Run)
+
+		alertController := alertcontroller.NewAlertController(alertStore, notifier)
+		app.Post("/v1/alerts", quoteScope, alertController.CreateHandler)
+		app.Get("/v1/alerts", quoteScope, alertController.ListHandler)
+		app.Get("/v1/alerts/dead-letters", quoteScope, alertController.DeadLettersHandler)
+		app.Post("/v1/alerts/dead-letters/:id/redeliver", quoteScope, alertController.RedeliverHandler)
+		app.Delete("/v1/alerts/dead-letters/:id", quoteScope, alertController.DeleteDeadLetterHandler)
+		app.Get("/v1/alerts/:id", quoteScope, alertController.GetHandler)
+		app.Put("/v1/alerts/:id", quoteScope, alertController.UpdateHandler)
+		app.Delete("/v1/alerts/:id", quoteScope, alertController.DeleteHandler)
+	}
+
+	// Paper trading simulates fills of accepted quotes in a local ledger
+	if cfg.Features.PaperTrading {
+		paperLedger := initializeLedger(cfg, orderService, quoteHistory)
+		defer paperLedger.Close()
+
+		accountController := accounts.NewAccountController(paperLedger)
+		app.Get("/v1/accounts/:id", tradeScope, accountController.AccountHandler)
+		app.Post("/v1/accounts/:id/deposits", tradeScope, accountController.DepositHandler)
+		app.Post("/v1/accounts/:id/fills", tradeScope, accountController.FillHandler)
+	}
+
+	// Start the servers, the gRPC QuoteService is served on its own port
+	listenErr := make(chan error, 2)
+	var grpcServer *grpc.Server
+	var grpcHealth *grpchealth.Server
+	if cfg.Features.GRPC {
+		listener, err := net.Listen("tcp", cfg.GRPCListen)
+		if err != nil {
+			stop()
+			workers.Wait()
+			return err
+		}
+
+		grpcServer, grpcHealth = rpc.NewServer(initializeQuoteServer(cfg, orderService, poller, authenticator, logger))
+		go func() { listenErr <- grpcServer.Serve(listener) }()
+	}
+	go func() { listenErr <- app.Listen(cfg.Listen) }()
+
+	select {
+	case err := <-listenErr:
+		stop()
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		app.Shutdown()
+		workers.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	// Stop accepting requests and give the in-flight ones time to finish
+	logger.Info("shutting down", "timeout", cfg.ShutdownTimeout.String())
+	if err := app.ShutdownWithTimeout(cfg.ShutdownTimeout); err != nil {
+		logger.Error("in-flight requests cut off", "error", err)
+	}
+	if socketController != nil {
+		// WebSocket clients are told to reconnect elsewhere
+		closeCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		if err := socketController.Shutdown(closeCtx); err != nil {
+			logger.Error("websocket connections cut off", "error", err)
+		}
+		cancel()
+	}
+	if grpcServer != nil {
+		// Health checks report NOT_SERVING while calls drain
+		grpcHealth.Shutdown()
+		if !stopGRPC(grpcServer, cfg.ShutdownTimeout) {
+			logger.Error("in-flight gRPC calls cut off")
+		}
+	}
+	workers.Wait()
+	return nil
+}
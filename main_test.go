@@ -104,7 +104,7 @@ func TestBuyHandler(t *testing.T) {
 				{Name: "kraken", BuyPrice: 9900, SellPrice: 9900, Err: nil},
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid amount"}`,
+			expectedBody:   `{"error":"invalid amount: must be a finite number greater than 0","code":"invalid_amount"}`,
 		},
 		{
 			name: "Missing amount parameter",
@@ -115,7 +115,7 @@ func TestBuyHandler(t *testing.T) {
 				{Name: "kraken", BuyPrice: 9900, SellPrice: 9900, Err: nil},
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid amount"}`,
+			expectedBody:   `{"error":"invalid amount: must be a finite number greater than 0","code":"invalid_amount"}`,
 		},
 		{
 			name: "Error fetching price from Coinbase",
@@ -137,7 +137,7 @@ func TestBuyHandler(t *testing.T) {
 				{Name: "kraken", BuyPrice: 0, SellPrice: 0, Err: fmt.Errorf("kraken error")},
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"failed to find best price for BTC"}`,
+			expectedBody:   `{"error":"failed to find best price for BTC","code":"internal_error"}`,
 		},
 	}
 
@@ -256,7 +256,7 @@ func TestSellHandler(t *testing.T) {
 				{Name: "kraken", BuyPrice: 9900, SellPrice: 9900, Err: nil},
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid amount"}`,
+			expectedBody:   `{"error":"invalid amount: must be a finite number greater than 0","code":"invalid_amount"}`,
 		},
 		{
 			name: "Missing amount parameter",
@@ -267,7 +267,7 @@ func TestSellHandler(t *testing.T) {
 				{Name: "kraken", BuyPrice: 9900, SellPrice: 9900, Err: nil},
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid amount"}`,
+			expectedBody: `{"error":"invalid amount: must be a finite number greater than 0","code":"invalid_amount"}`,
 		},
 		{
 			name: "Error fetching price from Coinbase",
@@ -289,7 +289,7 @@ func TestSellHandler(t *testing.T) {
 				{Name: "kraken", BuyPrice: 0, SellPrice: 0, Err: fmt.Errorf("kraken error")},
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to find best price for BTC"}`,
+			expectedBody: `{"error":"failed to find best price for BTC","code":"internal_error"}`,
 		},
 	}
 
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/markets"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarketHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		exchanges      []exchange.Exchange
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Aggregates both venues",
+			exchanges: []exchange.Exchange{
+				&MockBookExchange{Name: "coinbase", Book: exchange.OrderBook{
+					Bids: []exchange.Level{{Price: 9990, Size: 2}},
+					Asks: []exchange.Level{{Price: 10010, Size: 3}},
+				}},
+				&MockExchange{Name: "kraken", BuyPrice: 10005, SellPrice: 9985},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"symbol":"BTC",
+				"venues":[
+					{"exchange":"coinbase","bid":9990,"ask":10010,"mid":10000,"spread":20,"spreadBps":20,"bidSize":2,"askSize":3},
+					{"exchange":"kraken","bid":9985,"ask":10005,"mid":9995,"spread":20,"spreadBps":20.01000500250125}
+				],
+				"bestBid":{"price":9990,"exchanges":["coinbase"]},
+				"bestAsk":{"price":10005,"exchanges":["kraken"]},
+				"spread":15,"spreadBps":15.003750937734434,"midPrice":9997.5}`,
+		},
+		{
+			name: "Failed venue is reported",
+			exchanges: []exchange.Exchange{
+				&MockExchange{Name: "coinbase", Err: fmt.Errorf("coinbase error")},
+				&MockExchange{Name: "kraken", BuyPrice: 10005, SellPrice: 9985},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"symbol":"BTC",
+				"venues":[
+					{"exchange":"coinbase","error":"coinbase error"},
+					{"exchange":"kraken","bid":9985,"ask":10005,"mid":9995,"spread":20,"spreadBps":20.01000500250125}
+				],
+				"bestBid":{"price":9985,"exchanges":["kraken"]},
+				"bestAsk":{"price":10005,"exchanges":["kraken"]},
+				"spread":20,"spreadBps":20.01000500250125,"midPrice":9995}`,
+		},
+		{
+			name: "Every venue failed",
+			exchanges: []exchange.Exchange{
+				&MockExchange{Name: "coinbase", Err: fmt.Errorf("coinbase error")},
+				&MockExchange{Name: "kraken", Err: fmt.Errorf("kraken error")},
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"failed to find best price for BTC","code":"internal_error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			marketController := markets.NewMarketController(order.NewOrderService(tt.exchanges...))
+			app.Get("/v1/markets/:symbol", marketController.MarketHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/markets/BTC", nil)
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/metrics"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	orderService := order.NewOrderService(
+		&MockExchange{Name: "coinbase", BuyPrice: 10010, SellPrice: 9990},
+		&MockExchange{Name: "kraken", BuyPrice: 10005, SellPrice: 9985},
+		&MockExchange{Name: "gemini", Err: fmt.Errorf("lookup failed: %w", context.DeadlineExceeded)},
+		&MockExchange{Name: "bitstamp", Err: exchange.ErrPairNotFound},
+	)
+	m := metrics.New()
+	orderService.SetObserver(m)
+
+	app := fiber.New()
+	app.Use(m.Middleware)
+	orderController := orders.NewOrderController(orderService)
+	app.Get("/buy", orderController.BuyHandler)
+	app.Get("/metrics", m.Handler())
+
+	// Both requests name the symbol so the quote is priced on every venue,
+	// and the failed one is rejected for its amount alone
+	for _, tt := range []struct {
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"/buy?symbol=BTC&amount=1", http.StatusOK, `"coin":"BTC"`},
+		{"/buy?symbol=BTC&amount=0", http.StatusBadRequest, `"code":"invalid_amount"`},
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.url, nil))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, tt.expectedStatus, resp.StatusCode, tt.url)
+		assert.Contains(t, string(body), tt.expectedBody, tt.url)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, line := range []string{
+		`http_requests_total{method="GET",route="/buy",status="200"} 1`,
+		`http_requests_total{method="GET",route="/buy",status="400"} 1`,
+		`exchange_request_duration_seconds_count{exchange="coinbase"} 1`,
+		`exchange_request_duration_seconds_count{exchange="kraken"} 1`,
+		`exchange_errors_total{exchange="gemini",type="timeout"} 1`,
+		`exchange_timeouts_total{exchange="gemini"} 1`,
+		`exchange_errors_total{exchange="bitstamp",type="not_found"} 1`,
+		`quote_venue_wins_total{exchange="kraken",side="buy"} 1`,
+		`quote_venues_skipped_sum 2`,
+		`go_goroutines`,
+	} {
+		assert.Contains(t, string(body), line)
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/accounts"
+	alertcontroller "github.com/SmMistry/triumph-project/controllers/alerts"
+	"github.com/SmMistry/triumph-project/controllers/arbitrage"
+	candlecontroller "github.com/SmMistry/triumph-project/controllers/candles"
+	"github.com/SmMistry/triumph-project/controllers/docs"
+	"github.com/SmMistry/triumph-project/controllers/health"
+	"github.com/SmMistry/triumph-project/controllers/keys"
+	"github.com/SmMistry/triumph-project/controllers/markets"
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/controllers/quotes"
+	"github.com/SmMistry/triumph-project/controllers/socket"
+	"github.com/SmMistry/triumph-project/controllers/stream"
+	venuecontroller "github.com/SmMistry/triumph-project/controllers/venues"
+	"github.com/SmMistry/triumph-project/services/alerts"
+	arbitrageservice "github.com/SmMistry/triumph-project/services/arbitrage"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/candles"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/history"
+	"github.com/SmMistry/triumph-project/services/ledger"
+	"github.com/SmMistry/triumph-project/services/metrics"
+	"github.com/SmMistry/triumph-project/services/openapi"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/SmMistry/triumph-project/services/venues"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// servedRoutes returns every route main registers, as "METHOD /path"
+func servedRoutes(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, 0)
+	require.NoError(t, err)
+
+	routes := []string{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if receiver, ok := selector.X.(*ast.Ident); !ok || receiver.Name != "app" {
+			return true
+		}
+		path, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || path.Kind != token.STRING {
+			return true
+		}
+		switch method := strings.ToUpper(selector.Sel.Name); method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			value, err := strconv.Unquote(path.Value)
+			require.NoError(t, err)
+			routes = append(routes, method+" "+value)
+		}
+		return true
+	})
+
+	sort.Strings(routes)
+	return routes
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	// Every served route is documented, and every documented route served
+	assert.Equal(t, servedRoutes(t), spec.Operations())
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(spec.JSON(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+}
+
+func TestOpenAPIValidation(t *testing.T) {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	orderService := order.NewOrderService(&MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990})
+	orderController := orders.NewOrderController(orderService)
+
+	app := fiber.New()
+	app.Use(spec.Middleware)
+	app.Get("/buy", orderController.BuyHandler)
+	app.Post("/v1/orders", orderController.PlaceHandler)
+	app.Get("/v1/candles/:symbol", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/undocumented", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	request := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	for _, tc := range []struct {
+		name, method, path, body, error, code string
+	}{
+		{"negative amount", http.MethodGet, "/buy?symbol=BTC&amount=-1", "", "invalid amount: number must be more than 0", "invalid_amount"},
+		{"zero amount", http.MethodGet, "/buy?symbol=BTC&amount=0", "", "invalid amount: number must be more than 0", "invalid_amount"},
+		{"non numeric amount", http.MethodGet, "/buy?symbol=BTC&amount=lots", "", "invalid amount", "invalid_amount"},
+		{"missing amount", http.MethodGet, "/buy?symbol=BTC", "", "invalid amount: value is required but missing", "invalid_amount"},
+		{"missing symbol", http.MethodGet, "/buy?amount=1", "", "invalid symbol: value is required but missing", "invalid_symbol"},
+		{"negative slippage", http.MethodGet, "/buy?symbol=BTC&amount=1&maxSlippageBps=-5", "", "invalid maxSlippageBps", "invalid_max_slippage"},
+		{"invalid allowPartial", http.MethodGet, "/buy?symbol=BTC&amount=1&allowPartial=maybe", "", "invalid allowPartial", "invalid_request"},
+		{"unknown interval", http.MethodGet, "/v1/candles/BTC?interval=2m", "", "invalid interval", "invalid_interval"},
+		{"invalid side", http.MethodPost, "/v1/orders", `{"side":"hold","symbol":"BTC","amount":1}`, "invalid side", "invalid_side"},
+		{"missing order amount", http.MethodPost, "/v1/orders", `{"side":"buy","symbol":"BTC"}`, `property "amount" is missing`, "invalid_amount"},
+		{"malformed order", http.MethodPost, "/v1/orders", `{"side":`, "invalid request body", "invalid_request"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(tc.method, tc.path, tc.body)
+			assert.Equal(t, http.StatusBadRequest, status)
+
+			var response struct{ Error, Code string }
+			require.NoError(t, json.Unmarshal([]byte(body), &response), body)
+			assert.Contains(t, response.Error, tc.error)
+			assert.Equal(t, tc.code, response.Code)
+		})
+	}
+
+	// Conforming requests reach the handler, undocumented routes are left alone
+	status, body := request(http.MethodGet, "/buy?symbol=BTC&amount=2&venues=coinbase", "")
+	assert.Equal(t, http.StatusOK, status, body)
+	assert.Contains(t, body, `"usdAmount":20000`)
+	status, _ = request(http.MethodGet, "/undocumented?amount=-1", "")
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestOpenAPIConformance(t *testing.T) {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	// Kraken bids above Coinbase's ask, so there is an arbitrage to report
+	coinbase := &MockBookExchange{Name: "coinbase", Book: exchange.OrderBook{
+		Bids: []exchange.Level{{Price: 9900, Size: 10}},
+		Asks: []exchange.Level{{Price: 10000, Size: 10}},
+	}}
+	kraken := &MockExchange{Name: "kraken", BuyPrice: 10100, SellPrice: 10050}
+	orderService := order.NewOrderService(coinbase, kraken)
+
+	quoteHistory, err := history.Open(filepath.Join(dir, "quotes.db"))
+	require.NoError(t, err)
+	defer quoteHistory.Close()
+	orderService.SetRecorder(quoteHistory)
+
+	orderStore, err := order.OpenOrderStore(filepath.Join(dir, "orders.db"))
+	require.NoError(t, err)
+	defer orderStore.Close()
+	orderService.SetOrderStore(orderStore)
+	orderService.SetTraders(&MockTrader{Name: "coinbase", Orders: map[string]*exchange.OrderStatus{}})
+
+	candleStore, err := candles.Open(filepath.Join(dir, "candles.db"))
+	require.NoError(t, err)
+	defer candleStore.Close()
+	recorder := candles.NewRecorder(orderService, candleStore, []string{"BTC"}, time.Minute)
+	recorder.Sample(context.Background(), time.Now())
+
+	paperLedger, err := ledger.Open(filepath.Join(dir, "ledger.db"), orderService, quoteHistory)
+	require.NoError(t, err)
+	defer paperLedger.Close()
+
+	keyStore, err := auth.Open(filepath.Join(dir, "keys.db"))
+	require.NoError(t, err)
+	defer keyStore.Close()
+
+	alertStore, err := alerts.Open(filepath.Join(dir, "alerts.db"))
+	require.NoError(t, err)
+	defer alertStore.Close()
+
+	scanner := arbitrageservice.NewScanner(orderService, []string{"BTC"}, time.Hour, 0)
+	scanner.Scan(context.Background())
+	poller := bbo.NewPoller(orderService, time.Hour)
+
+	orderController := orders.NewOrderController(orderService)
+	marketController := markets.NewMarketController(orderService)
+	healthController := health.NewHealthController(orderService, 1, "BTC")
+	docsController := docs.NewDocsController(spec)
+	keyController := keys.NewKeyController(keyStore)
+	quoteController := quotes.NewQuoteController(orderService, quoteHistory)
+	candleController := candlecontroller.NewCandleController(orderService, candleStore)
+	arbitrageController := arbitrage.NewArbitrageController(scanner)
+	streamController := stream.NewStreamController(orderService, poller, 2)
+	socketController := socket.NewSocketController(orderService, poller, 2)
+	alertController := alertcontroller.NewAlertController(alertStore, alerts.NewNotifier(alertStore, 1, 0, time.Second))
+	accountController := accounts.NewAccountController(paperLedger)
+	venueController := venuecontroller.NewVenueController(venues.NewManager(orderService, nil, map[string]time.Duration{"coinbase": time.Second}))
+
+	// The same routes as main, without authentication, recording which were
+	// called
+	called := map[string]bool{}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		err := c.Next()
+		if route := c.Route(); route.Path != "/" {
+			called[route.Method+" "+route.Path] = true
+		}
+		return err
+	})
+	app.Get("/healthz", healthController.LivenessHandler)
+	app.Get("/readyz", healthController.ReadinessHandler)
+	app.Get("/metrics", metrics.New().Handler())
+	app.Get("/openapi.json", docsController.SpecHandler)
+	app.Get("/docs", docsController.UIHandler)
+	app.Use(spec.Middleware)
+	app.Post("/v1/admin/keys", keyController.CreateHandler)
+	app.Get("/v1/admin/keys", keyController.ListHandler)
+	app.Delete("/v1/admin/keys/:id", keyController.RevokeHandler)
+	app.Get("/v1/admin/venues", venueController.ListHandler)
+	app.Patch("/v1/admin/venues/:name", venueController.UpdateHandler)
+	app.Get("/buy", orderController.BuyHandler)
+	app.Get("/sell", orderController.SellHandler)
+	app.Post("/v1/orders", orderController.PlaceHandler)
+	app.Get("/v1/orders/:id", orderController.GetOrderHandler)
+	app.Delete("/v1/orders/:id", orderController.CancelHandler)
+	app.Get("/v1/markets/:symbol", marketController.MarketHandler)
+	app.Get("/v1/quotes", quoteController.ListHandler)
+	app.Get("/v1/quotes/:id", quoteController.GetHandler)
+	app.Get("/v1/candles/:symbol", candleController.CandlesHandler)
+	app.Get("/v1/arbitrage", arbitrageController.ListHandler)
+	app.Get("/v1/arbitrage/stream", arbitrageController.StreamHandler)
+	app.Get("/v1/stream/bbo", streamController.BBOHandler)
+	app.Get("/v1/ws", socketController.Handler)
+	app.Post("/v1/alerts", alertController.CreateHandler)
+	app.Get("/v1/alerts", alertController.ListHandler)
+	app.Get("/v1/alerts/dead-letters", alertController.DeadLettersHandler)
+	app.Post("/v1/alerts/dead-letters/:id/redeliver", alertController.RedeliverHandler)
+	app.Delete("/v1/alerts/dead-letters/:id", alertController.DeleteDeadLetterHandler)
+	app.Get("/v1/alerts/:id", alertController.GetHandler)
+	app.Put("/v1/alerts/:id", alertController.UpdateHandler)
+	app.Delete("/v1/alerts/:id", alertController.DeleteHandler)
+	app.Get("/v1/accounts/:id", accountController.AccountHandler)
+	app.Post("/v1/accounts/:id/deposits", accountController.DepositHandler)
+	app.Post("/v1/accounts/:id/fills", accountController.FillHandler)
+
+	// call sends a request and checks the response conforms to the document
+	call := func(method, path, body string, wantStatus int) map[string]json.RawMessage {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, wantStatus, resp.StatusCode, "%s %s: %s", method, path, data)
+
+		check := httptest.NewRequest(method, path, nil)
+		assert.NoError(t, spec.ValidateResponse(check, resp.StatusCode, resp.Header, data), "%s %s: %s", method, path, data)
+
+		response := map[string]json.RawMessage{}
+		json.Unmarshal(data, &response)
+		return response
+	}
+	id := func(raw json.RawMessage) string {
+		var v struct{ ID string }
+		require.NoError(t, json.Unmarshal(raw, &v))
+		return v.ID
+	}
+	str := func(raw json.RawMessage) string {
+		var s string
+		require.NoError(t, json.Unmarshal(raw, &s))
+		return s
+	}
+
+	call(http.MethodGet, "/healthz", "", http.StatusOK)
+	call(http.MethodGet, "/readyz", "", http.StatusOK)
+	call(http.MethodGet, "/metrics", "", http.StatusOK)
+	call(http.MethodGet, "/openapi.json", "", http.StatusOK)
+	call(http.MethodGet, "/docs", "", http.StatusOK)
+
+	// Quotes and their history
+	quote := call(http.MethodGet, "/buy?symbol=BTC&amount=1&maxSlippageBps=50&venues=coinbase", "", http.StatusOK)
+	quoteID := str(quote["quoteId"])
+	call(http.MethodGet, "/sell?symbol=BTC&amount=1&allowPartial=true", "", http.StatusOK)
+	call(http.MethodGet, "/buy?symbol=BTC&amount=1&venues=binance", "", http.StatusBadRequest)
+	call(http.MethodGet, "/buy?symbol=BTC&amount=-1", "", http.StatusBadRequest)
+	call(http.MethodGet, "/v1/quotes?symbol=BTC&limit=10", "", http.StatusOK)
+	call(http.MethodGet, "/v1/quotes?from=yesterday", "", http.StatusBadRequest)
+	call(http.MethodGet, "/v1/quotes/"+quoteID, "", http.StatusOK)
+	call(http.MethodGet, "/v1/quotes/missing", "", http.StatusNotFound)
+
+	// Market data
+	call(http.MethodGet, "/v1/markets/BTC", "", http.StatusOK)
+	call(http.MethodGet, "/v1/candles/BTC?interval=5m", "", http.StatusOK)
+	call(http.MethodGet, "/v1/candles/BTC?interval=2m", "", http.StatusBadRequest)
+	call(http.MethodGet, "/v1/arbitrage", "", http.StatusOK)
+	call(http.MethodGet, "/v1/stream/bbo", "", http.StatusBadRequest)
+	call(http.MethodGet, "/v1/stream/bbo?symbols=BTC,ETH,SOL", "", http.StatusBadRequest)
+	call(http.MethodGet, "/v1/ws", "", http.StatusUpgradeRequired)
+
+	// Orders
+	placed := call(http.MethodPost, "/v1/orders", `{"side":"buy","symbol":"BTC","amount":1,"venues":["coinbase"]}`, http.StatusCreated)
+	orderID := id(placed["order"])
+	call(http.MethodPost, "/v1/orders", `{"side":"buy","symbol":"BTC","amount":0}`, http.StatusBadRequest)
+	call(http.MethodGet, "/v1/orders/"+orderID, "", http.StatusOK)
+	call(http.MethodDelete, "/v1/orders/"+orderID, "", http.StatusOK)
+	call(http.MethodGet, "/v1/orders/missing", "", http.StatusNotFound)
+
+	// Alerts
+	created := call(http.MethodPost, "/v1/alerts", `{"symbol":"BTC","metric":"bestAsk","operator":"below","threshold":60000,"webhookUrl":"https://example.com/hook"}`, http.StatusCreated)
+	alertID := id(created["alert"])
+	call(http.MethodPost, "/v1/alerts", `{"symbol":"BTC","metric":"volume","operator":"below","threshold":1,"webhookUrl":"https://example.com/hook"}`, http.StatusBadRequest)
+	call(http.MethodGet, "/v1/alerts", "", http.StatusOK)
+	call(http.MethodGet, "/v1/alerts/"+alertID, "", http.StatusOK)
+	call(http.MethodPut, "/v1/alerts/"+alertID, `{"symbol":"ETH","metric":"spreadBps","operator":"above","threshold":20,"webhookUrl":"https://example.com/hook"}`, http.StatusOK)
+	call(http.MethodDelete, "/v1/alerts/"+alertID, "", http.StatusNoContent)
+	call(http.MethodGet, "/v1/alerts/"+alertID, "", http.StatusNotFound)
+	call(http.MethodGet, "/v1/alerts/dead-letters", "", http.StatusOK)
+	call(http.MethodPost, "/v1/alerts/dead-letters/missing/redeliver", "", http.StatusNotFound)
+	call(http.MethodDelete, "/v1/alerts/dead-letters/missing", "", http.StatusNotFound)
+
+	// Paper trading
+	call(http.MethodPost, "/v1/accounts/alice/deposits", `{"asset":"USD","amount":100000}`, http.StatusCreated)
+	call(http.MethodPost, "/v1/accounts/alice/deposits", `{"asset":"USD","amount":-5}`, http.StatusBadRequest)
+	call(http.MethodPost, "/v1/accounts/alice/fills", `{"quoteId":"`+quoteID+`"}`, http.StatusCreated)
+	call(http.MethodPost, "/v1/accounts/alice/fills", `{"quoteId":"`+quoteID+`"}`, http.StatusConflict)
+	call(http.MethodGet, "/v1/accounts/alice", "", http.StatusOK)
+	call(http.MethodGet, "/v1/accounts/not%20valid", "", http.StatusBadRequest)
+
+	// Admin
+	createdKey := call(http.MethodPost, "/v1/admin/keys", `{"name":"acme","scopes":["quote"],"rateLimit":60}`, http.StatusCreated)
+	call(http.MethodPost, "/v1/admin/keys", `{"name":"acme","scopes":["root"]}`, http.StatusBadRequest)
+	call(http.MethodGet, "/v1/admin/keys", "", http.StatusOK)
+	call(http.MethodDelete, "/v1/admin/keys/"+id(createdKey["key"]), "", http.StatusOK)
+	call(http.MethodDelete, "/v1/admin/keys/missing", "", http.StatusNotFound)
+	call(http.MethodGet, "/v1/admin/venues", "", http.StatusOK)
+	call(http.MethodPatch, "/v1/admin/venues/kraken", `{"enabled":false}`, http.StatusOK)
+	call(http.MethodPatch, "/v1/admin/venues/coinbase", `{"enabled":false}`, http.StatusConflict)
+	call(http.MethodPatch, "/v1/admin/venues/kraken", `{"enabled":true,"timeout":"soon"}`, http.StatusBadRequest)
+	call(http.MethodPatch, "/v1/admin/venues/kraken", `{}`, http.StatusBadRequest)
+	call(http.MethodPatch, "/v1/admin/venues/binance", `{"enabled":true}`, http.StatusNotFound)
+	call(http.MethodPatch, "/v1/admin/venues/kraken", `{"enabled":true}`, http.StatusOK)
+
+	// Streams end straight away once their source has stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	scanner.Run(ctx)
+	poller.Run(ctx)
+	call(http.MethodGet, "/v1/arbitrage/stream", "", http.StatusOK)
+	call(http.MethodGet, "/v1/stream/bbo?symbols=BTC", "", http.StatusOK)
+
+	// Every documented operation was checked
+	for _, operation := range spec.Operations() {
+		assert.True(t, called[operation], "%s was not called", operation)
+	}
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTrader is an in memory Trader, orders stay in the state it sets
+type MockTrader struct {
+	Name   string
+	Err    error
+	Orders map[string]*exchange.OrderStatus
+}
+
+func (m *MockTrader) PlaceOrder(ctx context.Context, req exchange.OrderRequest) (*exchange.OrderStatus, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	status := &exchange.OrderStatus{
+		ID: fmt.Sprintf("%s-%d", m.Name, len(m.Orders)+1), ClientOrderID: req.ClientOrderID,
+		Exchange: m.Name, Symbol: req.Symbol, Side: req.Side, State: exchange.OrderStateOpen,
+	}
+	m.Orders[status.ID] = status
+	return status, nil
+}
+
+func (m *MockTrader) CancelOrder(ctx context.Context, id string) error {
+	m.Orders[id].State = exchange.OrderStateCancelled
+	return nil
+}
+
+func (m *MockTrader) GetOrder(ctx context.Context, id string) (*exchange.OrderStatus, error) {
+	status := *m.Orders[id]
+	return &status, nil
+}
+
+func (m *MockTrader) GetName() string {
+	return m.Name
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	store, err := order.OpenOrderStore(filepath.Join(t.TempDir(), "orders.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	coinbase := &MockBookExchange{Name: "coinbase", Book: exchange.OrderBook{
+		Bids: []exchange.Level{{Price: 9900, Size: 1}},
+		Asks: []exchange.Level{{Price: 10000, Size: 1}, {Price: 10030, Size: 5}},
+	}}
+	kraken := &MockBookExchange{Name: "kraken", Book: exchange.OrderBook{
+		Bids: []exchange.Level{{Price: 9890, Size: 1}},
+		Asks: []exchange.Level{{Price: 10010, Size: 2}},
+	}}
+	coinbaseTrader := &MockTrader{Name: "coinbase", Orders: map[string]*exchange.OrderStatus{}}
+	krakenTrader := &MockTrader{Name: "kraken", Orders: map[string]*exchange.OrderStatus{}}
+
+	orderService := order.NewOrderService(coinbase, kraken)
+	orderService.SetOrderStore(store)
+	orderService.SetTraders(coinbaseTrader, krakenTrader)
+
+	app := fiber.New()
+	orderController := orders.NewOrderController(orderService)
+	app.Post("/v1/orders", orderController.PlaceHandler)
+	app.Get("/v1/orders/:id", orderController.GetOrderHandler)
+	app.Delete("/v1/orders/:id", orderController.CancelHandler)
+
+	type response struct {
+		Order       order.Order    `json:"order"`
+		ChildOrders []*order.Order `json:"childOrders"`
+		Error       string         `json:"error"`
+	}
+	do := func(method, url, body string) (int, response) {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		var r response
+		require.NoError(t, json.Unmarshal(data, &r), string(data))
+		return resp.StatusCode, r
+	}
+
+	// 3.5 BTC takes Coinbase's first level, all of Kraken's and the rest of
+	// Coinbase's second level
+	status, placed := do(http.MethodPost, "/v1/orders", `{"side":"buy","symbol":"BTC","amount":3.5}`)
+	require.Equal(t, http.StatusCreated, status, placed.Error)
+	assert.Equal(t, order.StatusRouted, placed.Order.Status)
+	require.Len(t, placed.ChildOrders, 2)
+	assert.Equal(t, "coinbase", placed.ChildOrders[0].Exchange)
+	assert.Equal(t, 1.5, placed.ChildOrders[0].Amount)
+	assert.Equal(t, "kraken", placed.ChildOrders[1].Exchange)
+	assert.Equal(t, 2.0, placed.ChildOrders[1].Amount)
+	for _, child := range placed.ChildOrders {
+		assert.Equal(t, placed.Order.ID, child.ParentID)
+		assert.Equal(t, order.StatusRouted, child.Status)
+	}
+
+	// Kraken fills, Coinbase fills part of its share
+	krakenTrader.Orders["kraken-1"].State = exchange.OrderStateFilled
+	krakenTrader.Orders["kraken-1"].FilledAmount = 2
+	krakenTrader.Orders["kraken-1"].AvgPrice = 10010
+	coinbaseTrader.Orders["coinbase-1"].FilledAmount = 1
+	coinbaseTrader.Orders["coinbase-1"].AvgPrice = 10000
+
+	status, tracked := do(http.MethodGet, "/v1/orders/"+placed.Order.ID, "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, order.StatusPartiallyFilled, tracked.Order.Status)
+	assert.Equal(t, 3.0, tracked.Order.FilledAmount)
+	assert.InDelta(t, (2*10010+10000)/3.0, tracked.Order.AvgPrice, 1e-9)
+	assert.Equal(t, order.StatusPartiallyFilled, tracked.ChildOrders[0].Status)
+	assert.Equal(t, order.StatusFilled, tracked.ChildOrders[1].Status)
+
+	// Looking up a child order returns the whole order
+	status, byChild := do(http.MethodGet, "/v1/orders/"+placed.ChildOrders[1].ID, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, placed.Order.ID, byChild.Order.ID)
+
+	// Cancelling stops the rest of Coinbase's share, keeping the part that
+	// filled
+	status, cancelled := do(http.MethodDelete, "/v1/orders/"+placed.Order.ID, "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, order.StatusCancelled, cancelled.Order.Status)
+	assert.Equal(t, 3.0, cancelled.Order.FilledAmount)
+	assert.Equal(t, exchange.OrderStateCancelled, coinbaseTrader.Orders["coinbase-1"].State)
+
+	var statuses []order.Status
+	for _, transition := range cancelled.Order.Transitions {
+		statuses = append(statuses, transition.To)
+		assert.False(t, transition.At.IsZero())
+		assert.NotEmpty(t, transition.Reason)
+	}
+	assert.Equal(t, []order.Status{order.StatusNew, order.StatusRouted, order.StatusPartiallyFilled, order.StatusCancelled}, statuses)
+
+	status, failed := do(http.MethodDelete, "/v1/orders/"+placed.Order.ID, "")
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "invalid order transition: "+placed.Order.ID+" is already cancelled", failed.Error)
+
+	// Venues that reject the order reject the parent when none accept it
+	krakenTrader.Err = fmt.Errorf("%w: kraken: EOrder:Insufficient funds", exchange.ErrOrderRejected)
+	status, rejected := do(http.MethodPost, "/v1/orders", `{"side":"buy","symbol":"BTC","amount":1,"venues":["kraken"]}`)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, order.StatusRejected, rejected.Order.Status)
+	assert.Equal(t, "order rejected: kraken: EOrder:Insufficient funds", rejected.ChildOrders[0].Transitions[1].Reason)
+
+	status, _ = do(http.MethodGet, "/v1/orders/missing", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// Retrying with the same client order ID returns the original order
+	// without placing it again
+	krakenTrader.Err = nil
+	body := `{"side":"buy","symbol":"BTC","amount":1,"venues":["kraken"],"clientOrderId":"retry-1"}`
+	status, first := do(http.MethodPost, "/v1/orders", body)
+	require.Equal(t, http.StatusCreated, status, first.Error)
+	placedOrders := len(krakenTrader.Orders)
+
+	status, retried := do(http.MethodPost, "/v1/orders", body)
+	require.Equal(t, http.StatusCreated, status, retried.Error)
+	assert.Equal(t, first.Order.ID, retried.Order.ID)
+	assert.Equal(t, "retry-1", retried.Order.ClientOrderID)
+	require.Len(t, retried.ChildOrders, 1)
+	assert.Equal(t, first.ChildOrders[0].ID, retried.ChildOrders[0].ID)
+	assert.Equal(t, placedOrders, len(krakenTrader.Orders))
+}
+
+func TestCancelPartlyFilledOrder(t *testing.T) {
+	ctx := context.Background()
+	store, err := order.OpenOrderStore(filepath.Join(t.TempDir(), "orders.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	trader := &MockTrader{Name: "coinbase", Orders: map[string]*exchange.OrderStatus{}}
+	orderService := order.NewOrderService(&MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990})
+	orderService.SetOrderStore(store)
+	orderService.SetTraders(trader)
+
+	parent, _, err := orderService.PlaceOrder(ctx, order.PlaceRequest{
+		QuoteRequest: order.QuoteRequest{Side: order.SideBuy, Symbol: "BTC", Amount: 2},
+	})
+	require.NoError(t, err)
+	trader.Orders["coinbase-1"].FilledAmount = 0.5
+	trader.Orders["coinbase-1"].AvgPrice = 10000
+	_, _, err = orderService.GetOrder(ctx, parent.ID)
+	require.NoError(t, err)
+
+	// The order ends cancelled whatever filled before, the fill stays on it
+	cancelled, children, err := orderService.CancelOrder(ctx, parent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, order.Status("cancelled"), cancelled.Status)
+	assert.Equal(t, order.StatusCancelled, children[0].Status)
+	assert.Equal(t, 0.5, cancelled.FilledAmount)
+	assert.Equal(t, 2.0, cancelled.Amount)
+}
+
+// slowTrader holds placements until released, announcing the client order ID
+// of each one
+type slowTrader struct {
+	*MockTrader
+	placing chan string
+	release chan struct{}
+}
+
+func (s *slowTrader) PlaceOrder(ctx context.Context, req exchange.OrderRequest) (*exchange.OrderStatus, error) {
+	s.placing <- req.ClientOrderID
+	<-s.release
+	return s.MockTrader.PlaceOrder(ctx, req)
+}
+
+func TestOrderPlacementLocks(t *testing.T) {
+	ctx := context.Background()
+	store, err := order.OpenOrderStore(filepath.Join(t.TempDir(), "orders.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	coinbaseTrader := &MockTrader{Name: "coinbase", Orders: map[string]*exchange.OrderStatus{}}
+	krakenTrader := &slowTrader{
+		MockTrader: &MockTrader{Name: "kraken", Orders: map[string]*exchange.OrderStatus{}},
+		placing:    make(chan string, 2),
+		release:    make(chan struct{}),
+	}
+	orderService := order.NewOrderService(
+		&MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990},
+		&MockExchange{Name: "kraken", BuyPrice: 10000, SellPrice: 9990},
+	)
+	orderService.SetOrderStore(store)
+	orderService.SetTraders(coinbaseTrader, krakenTrader)
+
+	place := func(venue, clientOrderID string) (*order.Order, error) {
+		parent, _, err := orderService.PlaceOrder(ctx, order.PlaceRequest{
+			QuoteRequest:  order.QuoteRequest{Side: order.SideBuy, Symbol: "BTC", Amount: 1, Venues: []string{venue}},
+			ClientOrderID: clientOrderID,
+		})
+		return parent, err
+	}
+	first, err := place("coinbase", "")
+	require.NoError(t, err)
+
+	type result struct {
+		order *order.Order
+		err   error
+	}
+	results := make(chan result, 2)
+	go func() {
+		parent, err := place("kraken", "slow-1")
+		results <- result{parent, err}
+	}()
+
+	// The order is kept before the venue sees it
+	child, err := store.Get(<-krakenTrader.placing)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusNew, child.Status)
+	parent, err := store.Get(child.ParentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{child.ID}, parent.Children)
+
+	// A slow venue holds up neither other orders nor placements
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := orderService.GetOrder(ctx, first.ID)
+		if err == nil {
+			_, err = place("coinbase", "fast-1")
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("waited on another order's venue")
+	}
+
+	// A retry waits for the original and returns it without placing again
+	go func() {
+		parent, err := place("kraken", "slow-1")
+		results <- result{parent, err}
+	}()
+	close(krakenTrader.release)
+	original, retried := <-results, <-results
+	require.NoError(t, original.err)
+	require.NoError(t, retried.err)
+	assert.Equal(t, original.order.ID, retried.order.ID)
+	assert.Empty(t, krakenTrader.placing)
+	assert.Len(t, krakenTrader.Orders, 1)
+}
+
+func TestOrderTransitions(t *testing.T) {
+	o := &order.Order{ID: "order-1", Status: order.StatusNew}
+	now := time.Now()
+
+	assert.NoError(t, o.Transition(order.StatusRouted, "routed to coinbase", now))
+	assert.NoError(t, o.Transition(order.StatusFilled, "coinbase reported filled", now))
+	assert.True(t, o.Status.Terminal())
+	assert.ErrorIs(t, o.Transition(order.StatusCancelled, "too late", now), order.ErrInvalidTransition)
+}
+
+// mockLotExchange is a MockBookExchange publishing size rules
+type mockLotExchange struct {
+	MockBookExchange
+	Info *exchange.SymbolInfo
+}
+
+func (m *mockLotExchange) GetSymbolInfo(ctx context.Context, symbol string) (*exchange.SymbolInfo, error) {
+	return m.Info, nil
+}
+
+func TestOrderPlacementRouting(t *testing.T) {
+	ctx := context.Background()
+	place := func(orderService *order.OrderService, symbol string, amount float64, traders ...exchange.Trader) (*order.Order, []*order.Order) {
+		store, err := order.OpenOrderStore(filepath.Join(t.TempDir(), "orders.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { store.Close() })
+		orderService.SetOrderStore(store)
+		orderService.SetTraders(traders...)
+
+		parent, children, err := orderService.PlaceOrder(ctx, order.PlaceRequest{
+			QuoteRequest: order.QuoteRequest{Side: order.SideBuy, Symbol: symbol, Amount: amount},
+		})
+		require.NoError(t, err)
+		return parent, children
+	}
+
+	// Kraken only prices XYZ through BTC, the cheaper synthetic book is never
+	// traded
+	kraken := &MockPairExchange{Name: "kraken", Books: map[string]exchange.OrderBook{
+		"XYZ-BTC": {Bids: []exchange.Level{{Price: 0.0001, Size: 1000}}, Asks: []exchange.Level{{Price: 0.0001, Size: 1000}}},
+		"BTC-USD": {Bids: []exchange.Level{{Price: 10000, Size: 10}}, Asks: []exchange.Level{{Price: 10000, Size: 10}}},
+	}}
+	coinbase := &MockBookExchange{Name: "coinbase", Book: exchange.OrderBook{
+		Bids: []exchange.Level{{Price: 1.9, Size: 1000}},
+		Asks: []exchange.Level{{Price: 2, Size: 1000}},
+	}}
+	orderService := order.NewOrderService(coinbase, kraken)
+	orderService.SetIntermediates("BTC")
+
+	_, children := place(orderService, "XYZ", 10,
+		&MockTrader{Name: "coinbase", Orders: map[string]*exchange.OrderStatus{}},
+		&MockTrader{Name: "kraken", Orders: map[string]*exchange.OrderStatus{}})
+	require.Len(t, children, 1)
+	assert.Equal(t, "coinbase", children[0].Exchange)
+	assert.Equal(t, 10.0, children[0].Amount)
+
+	// Each child is rounded to its own venue's lot size, what Kraken's share
+	// loses goes to Coinbase and back to Kraken so the children still add up
+	orderService = order.NewOrderService(
+		&mockLotExchange{
+			MockBookExchange: MockBookExchange{Name: "kraken", Book: exchange.OrderBook{
+				Bids: []exchange.Level{{Price: 9990, Size: 5}},
+				Asks: []exchange.Level{{Price: 10000, Size: 0.7555}},
+			}},
+			Info: &exchange.SymbolInfo{LotSize: 0.001},
+		},
+		&mockLotExchange{
+			MockBookExchange: MockBookExchange{Name: "coinbase", Book: exchange.OrderBook{
+				Bids: []exchange.Level{{Price: 9990, Size: 5}},
+				Asks: []exchange.Level{{Price: 10010, Size: 5}},
+			}},
+			Info: &exchange.SymbolInfo{LotSize: 0.01},
+		},
+	)
+
+	parent, children := place(orderService, "BTC", 1.5,
+		&MockTrader{Name: "coinbase", Orders: map[string]*exchange.OrderStatus{}},
+		&MockTrader{Name: "kraken", Orders: map[string]*exchange.OrderStatus{}})
+	assert.Equal(t, 1.5, parent.Amount)
+	require.Len(t, children, 2)
+	assert.Equal(t, "kraken", children[0].Exchange)
+	assert.Equal(t, 0.76, children[0].Amount)
+	assert.Equal(t, "coinbase", children[1].Exchange)
+	assert.Equal(t, 0.74, children[1].Amount)
+
+	// Orders are rejected rather than placed short when what's cut off fits
+	// no venue's lot size
+	orderService = order.NewOrderService(
+		&mockLotExchange{
+			MockBookExchange: MockBookExchange{Name: "kraken", Book: exchange.OrderBook{
+				Bids: []exchange.Level{{Price: 9990, Size: 5}},
+				Asks: []exchange.Level{{Price: 10000, Size: 0.7}},
+			}},
+			Info: &exchange.SymbolInfo{LotSize: 0.5},
+		},
+		&mockLotExchange{
+			MockBookExchange: MockBookExchange{Name: "coinbase", Book: exchange.OrderBook{
+				Bids: []exchange.Level{{Price: 9990, Size: 5}},
+				Asks: []exchange.Level{{Price: 10010, Size: 5}},
+			}},
+			Info: &exchange.SymbolInfo{LotSize: 0.2},
+		},
+	)
+
+	krakenTrader := &MockTrader{Name: "kraken", Orders: map[string]*exchange.OrderStatus{}}
+	parent, children = place(orderService, "BTC", 1, krakenTrader,
+		&MockTrader{Name: "coinbase", Orders: map[string]*exchange.OrderStatus{}})
+	assert.Equal(t, order.StatusRejected, parent.Status)
+	assert.Contains(t, parent.Transitions[1].Reason, "of 1 BTC doesn't fit the lot size of any venue")
+	assert.Empty(t, children)
+	assert.Empty(t, krakenTrader.Orders)
+}
@@ -0,0 +1,4 @@
+// Package quotev1 is the generated code of the gRPC quote API
+package quotev1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative quote/v1/quote.proto
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/config"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/SmMistry/triumph-project/services/venues"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRESTAdapter(t *testing.T) {
+	// One venue answers with [price, size] string arrays, the other nests
+	// unsorted object levels under a key named after the pair
+	venues := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?pair=" + r.URL.Query().Get("pair") {
+		case "/api/v2/order_book/btcusd/?pair=":
+			w.Write([]byte(`{"timestamp":"1731067200","bids":[["9990.5","1.2"],["9990","3"]],"asks":[["10001","0.5"],["10002","4"]]}`))
+		case "/0/book?pair=BTC_USD":
+			w.Write([]byte(`{"result":{"BTC_USD":{"bids":[{"p":9980,"q":1},{"p":9995,"q":2},{"p":9970,"q":5}],"asks":[{"p":10005,"q":1},{"p":10000,"q":0.1}]}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer venues.Close()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+enabledVenues: [bitstamp, gemini]
+venues:
+  bitstamp:
+    adapter: rest
+    marketURL: `+venues.URL+`
+    takerFee: 0.004
+    rest:
+      bookURL: /api/v2/order_book/{symbol}/
+      lowerCase: true
+      bids: bids
+      asks: asks
+  gemini:
+    adapter: rest
+    marketURL: https://api.gemini.com
+    rest:
+      bookURL: `+venues.URL+`/0/book?pair={symbol}&depth={depth}
+      symbol: "{base}_{quote}"
+      bids: $.result.*.bids
+      asks: result.*.asks
+      price: p
+      size: q
+`), 0o644))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, cfg.Venues["gemini"].Timeout)
+
+	exchanges, err := cfg.Exchanges(nil)
+	require.NoError(t, err)
+	require.Len(t, exchanges, 2)
+	assert.Equal(t, "bitstamp", exchanges[0].GetName())
+	assert.Equal(t, 0.004, exchanges[0].(exchange.FeeProvider).GetTakerFee())
+	ctx := context.Background()
+
+	buy, sell, err := exchanges[0].GetPrices(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, 10001.0, buy)
+	assert.Equal(t, 9990.5, sell)
+
+	// Levels are sorted best first and cut to the requested depth
+	book, err := exchanges[1].(exchange.BookProvider).GetOrderBook(ctx, "BTC", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []exchange.Level{{Price: 9995, Size: 2}, {Price: 9980, Size: 1}}, book.Bids)
+	assert.Equal(t, []exchange.Level{{Price: 10000, Size: 0.1}, {Price: 10005, Size: 1}}, book.Asks)
+
+	_, _, err = exchanges[0].GetPrices(ctx, "NOPE")
+	assert.ErrorIs(t, err, exchange.ErrPairNotFound)
+
+	// The configured venues quote like any other
+	orderService := order.NewOrderService(exchanges...)
+	market, err := orderService.Market(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, 9995.0, market.BestBid.Price)
+	assert.Equal(t, 10000.0, market.BestAsk.Price)
+}
+
+func TestRESTAdapterValidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+enabledVenues: [coinbase, bitstamp, gemini]
+venues:
+  bitstamp:
+    adapter: rest
+    marketURL: https://www.bitstamp.net
+  gemini:
+    adapter: rest
+    marketURL: https://api.gemini.com
+    rest:
+      bookURL: /v1/book/{symbol}
+      symbol: "{quote}"
+      bids: bids[x]
+`), 0o644))
+
+	_, err := config.Load(path)
+	assert.EqualError(t, err, `invalid config:
+venues.bitstamp.rest: must be set for the rest adapter
+venues.gemini.rest: symbol must contain {base}, got "{quote}"`)
+}
+
+func TestRegister(t *testing.T) {
+	exchange.Register("mock", func(s exchange.Settings) (exchange.Exchange, error) {
+		return &MockExchange{Name: s.Name, BuyPrice: 100, SellPrice: 99}, nil
+	})
+	assert.Equal(t, []string{"coinbase", "kraken", "mock", "rest"}, exchange.Adapters())
+
+	// Venues pick registered adapters by name
+	t.Setenv("VENUES", "paper")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("venues:\n  okx:\n    adapter: binance\n"), 0o644))
+	_, err := config.Load(path)
+	assert.EqualError(t, err, `invalid config:
+enabledVenues: unknown exchange "paper", expected one of coinbase, kraken, okx
+venues.okx.adapter: unknown adapter "binance", expected one of coinbase, kraken, mock, rest`)
+
+	require.NoError(t, os.WriteFile(path, []byte("venues:\n  paper:\n    adapter: mock\n    marketURL: https://example.com\n"), 0o644))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	exchanges, err := cfg.Exchanges(nil)
+	require.NoError(t, err)
+	require.Len(t, exchanges, 1)
+	assert.Equal(t, "paper", exchanges[0].GetName())
+
+	assert.PanicsWithValue(t, "exchange: Register called twice for adapter mock", func() {
+		exchange.Register("mock", func(exchange.Settings) (exchange.Exchange, error) { return nil, nil })
+	})
+	_, err = exchange.New("binance", exchange.Settings{Name: "okx"})
+	assert.ErrorIs(t, err, exchange.ErrUnknownAdapter)
+}
+
+func TestRenamedVenue(t *testing.T) {
+	// Two Coinbase venues, e.g. regional deployments, told apart by name
+	book := func(ask string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"bids":[["9990","1",1]],"asks":[["` + ask + `","1",1]]}`))
+		}))
+	}
+	us, eu := book("10010"), book("10000")
+	defer us.Close()
+	defer eu.Close()
+
+	t.Setenv("VENUES", "coinbase,coinbase-eu")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`venues:
+  coinbase:
+    marketURL: `+us.URL+`
+  coinbase-eu:
+    adapter: coinbase
+    marketURL: `+eu.URL+`
+`), 0o644))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	exchanges, err := cfg.Exchanges(nil)
+	require.NoError(t, err)
+	require.Len(t, exchanges, 2)
+	assert.Equal(t, "coinbase", exchanges[0].GetName())
+	assert.Equal(t, "coinbase-eu", exchanges[1].GetName())
+
+	orderService := order.NewOrderService(exchanges...)
+	quote, err := orderService.Quote(context.Background(), order.QuoteRequest{Side: order.SideBuy, Symbol: "BTC", Amount: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coinbase-eu"}, quote.Exchanges)
+
+	// Reconfiguring the renamed venue rebuilds it under its own name
+	timeout := time.Second
+	manager := initializeVenueManager(cfg, orderService, nil)
+	_, err = manager.Update(context.Background(), "coinbase-eu", venues.Change{Timeout: &timeout}, "test")
+	require.NoError(t, err)
+	assert.Equal(t, []order.VenueState{{Name: "coinbase", Enabled: true}, {Name: "coinbase-eu", Enabled: true}}, orderService.Venues())
+	quote, err = orderService.Quote(context.Background(), order.QuoteRequest{Side: order.SideBuy, Symbol: "BTC", Amount: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coinbase-eu"}, quote.Exchanges)
+}
@@ -0,0 +1,117 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+// Metrics a condition can watch
+const (
+	MetricBestBid   = "bestBid"
+	MetricBestAsk   = "bestAsk"
+	MetricMidPrice  = "midPrice"
+	MetricSpreadBps = "spreadBps"
+	// MetricArbitrageBps is the best edge from buying on one venue and
+	// selling on another, before fees
+	MetricArbitrageBps = "arbitrageBps"
+)
+
+// Operators comparing a metric to the threshold
+const (
+	OperatorAbove = "above"
+	OperatorBelow = "below"
+)
+
+// ErrInvalidCondition is returned for conditions that can't be evaluated
+var ErrInvalidCondition = errors.New("invalid alert condition")
+
+// Condition compares a metric of a symbol's market to a threshold, e.g. BTC
+// bestAsk below 60000
+type Condition struct {
+	Symbol    string  `json:"symbol"`
+	Metric    string  `json:"metric"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	// Venues limits arbitrageBps to edges between these venues, in either
+	// direction. Every venue is compared when empty
+	Venues []string `json:"venues,omitempty"`
+}
+
+// Validate checks that the condition can be evaluated
+func (c Condition) Validate() error {
+	switch {
+	case c.Symbol == "":
+		return fmt.Errorf("%w: symbol is required", ErrInvalidCondition)
+	case !slices.Contains([]string{MetricBestBid, MetricBestAsk, MetricMidPrice, MetricSpreadBps, MetricArbitrageBps}, c.Metric):
+		return fmt.Errorf("%w: unknown metric %q", ErrInvalidCondition, c.Metric)
+	case c.Operator != OperatorAbove && c.Operator != OperatorBelow:
+		return fmt.Errorf("%w: operator must be above or below", ErrInvalidCondition)
+	case len(c.Venues) > 0 && c.Metric != MetricArbitrageBps:
+		return fmt.Errorf("%w: venues only apply to %s", ErrInvalidCondition, MetricArbitrageBps)
+	case len(c.Venues) == 1:
+		return fmt.Errorf("%w: arbitrage needs at least two venues", ErrInvalidCondition)
+	}
+	return nil
+}
+
+// Evaluate returns the condition's metric in the market and whether the
+// condition holds. It never holds when the metric can't be measured, e.g.
+// when fewer than two venues answered for an arbitrage
+func (c Condition) Evaluate(market *order.Market) (float64, bool) {
+	var value float64
+	switch c.Metric {
+	case MetricBestBid:
+		value = market.BestBid.Price
+	case MetricBestAsk:
+		value = market.BestAsk.Price
+	case MetricMidPrice:
+		value = market.MidPrice
+	case MetricSpreadBps:
+		value = market.SpreadBps
+	case MetricArbitrageBps:
+		var ok bool
+		if value, ok = c.arbitrageBps(market); !ok {
+			return 0, false
+		}
+	}
+
+	if c.Operator == OperatorAbove {
+		return value, value > c.Threshold
+	}
+	return value, value < c.Threshold
+}
+
+// arbitrageBps returns the best edge from buying at one venue's ask and
+// selling at another's bid
+func (c Condition) arbitrageBps(market *order.Market) (float64, bool) {
+	best, found := 0.0, false
+	for _, buy := range market.Venues {
+		for _, sell := range market.Venues {
+			if buy.Exchange == sell.Exchange || !c.compares(buy) || !c.compares(sell) {
+				continue
+			}
+			edge := (sell.Bid - buy.Ask) / buy.Ask * 10000
+			if !found || edge > best {
+				best, found = edge, true
+			}
+		}
+	}
+	return best, found
+}
+
+// compares reports whether a venue answered and takes part in arbitrageBps
+func (c Condition) compares(venue order.VenueMarket) bool {
+	if venue.Error != "" || venue.Bid <= 0 || venue.Ask <= 0 {
+		return false
+	}
+	return len(c.Venues) == 0 || slices.Contains(c.Venues, venue.Exchange)
+}
+
+// equal reports whether two conditions are the same
+func (c Condition) equal(other Condition) bool {
+	return c.Symbol == other.Symbol && c.Metric == other.Metric && c.Operator == other.Operator &&
+		c.Threshold == other.Threshold && slices.Equal(c.Venues, other.Venues)
+}
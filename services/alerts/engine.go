@@ -0,0 +1,115 @@
+package alerts
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+// Engine periodically evaluates every stored alert against the venues' top of
+// book, notifying an alert's webhook when its condition starts to hold
+type Engine struct {
+	orderService *order.OrderService
+	store        *Store
+	notifier     *Notifier
+	interval     time.Duration
+	logger       *slog.Logger
+}
+
+// NewEngine creates a new Engine evaluating alerts every interval
+func NewEngine(orderService *order.OrderService, store *Store, notifier *Notifier, interval time.Duration) *Engine {
+	return &Engine{
+		orderService: orderService,
+		store:        store,
+		notifier:     notifier,
+		interval:     interval,
+		logger:       logging.Discard(),
+	}
+}
+
+// SetLogger sets the logger failed evaluations are reported to, nothing is
+// logged by default
+func (e *Engine) SetLogger(logger *slog.Logger) {
+	e.logger = logging.OrDiscard(logger)
+}
+
+// Run evaluates alerts on every interval until the context is cancelled,
+// then waits for the deliveries in flight
+func (e *Engine) Run(ctx context.Context) {
+	defer e.notifier.Wait()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.Evaluate(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate evaluates every alert once, fetching each symbol's market a
+// single time however many alerts watch it
+func (e *Engine) Evaluate(ctx context.Context) {
+	alerts, err := e.store.all()
+	if err != nil {
+		e.logger.ErrorContext(ctx, "failed to load alerts", "error", err)
+		return
+	}
+
+	bySymbol := map[string][]*storedAlert{}
+	for _, alert := range alerts {
+		bySymbol[alert.Symbol] = append(bySymbol[alert.Symbol], alert)
+	}
+
+	var wg sync.WaitGroup
+	for symbol, alerts := range bySymbol {
+		wg.Add(1)
+		go func(symbol string, alerts []*storedAlert) {
+			defer wg.Done()
+
+			market, err := e.orderService.Market(ctx, symbol)
+			if err != nil {
+				e.logger.WarnContext(ctx, "failed to evaluate alerts", "symbol", symbol, "error", err)
+				return
+			}
+			for _, alert := range alerts {
+				e.evaluate(ctx, alert, market, time.Now().UTC())
+			}
+		}(symbol, alerts)
+	}
+	wg.Wait()
+}
+
+// evaluate checks one alert against its market, triggering it when its
+// condition started holding
+func (e *Engine) evaluate(ctx context.Context, alert *storedAlert, market *order.Market, now time.Time) {
+	value, holding := alert.Evaluate(market)
+	if holding == alert.Holding {
+		return
+	}
+
+	var triggeredAt *time.Time
+	if holding {
+		triggeredAt = &now
+	}
+	applied, err := e.store.setState(alert, holding, triggeredAt)
+	if err != nil {
+		e.logger.ErrorContext(ctx, "failed to store alert state", "alert", alert.ID, "error", err)
+		return
+	}
+
+	if applied && holding {
+		e.logger.InfoContext(ctx, "alert triggered", "alert", alert.ID, "symbol", alert.Symbol,
+			"metric", alert.Metric, "value", value, "threshold", alert.Threshold)
+		e.notifier.Notify(ctx, &alert.Alert, newEvent(&alert.Alert, value, market, now))
+	}
+}
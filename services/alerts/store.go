@@ -0,0 +1,369 @@
+package alerts
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets used by the store. Alerts are stored by ID along with their
+// webhook secret, deadLetters holds the deliveries that failed for good
+var (
+	alertsBucket      = []byte("alerts")
+	deadLettersBucket = []byte("deadLetters")
+)
+
+var (
+	// ErrAlertNotFound is returned for alerts that don't exist, or belong to
+	// another owner
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrDeadLetterNotFound is returned for dead letters that don't exist, or
+	// belong to another owner
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrInvalidWebhook is returned for webhook URLs that aren't absolute
+	// http or https URLs
+	ErrInvalidWebhook = errors.New("invalid webhook URL")
+)
+
+// Alert is a condition and the webhook notified when it starts to hold
+type Alert struct {
+	ID string `json:"id"`
+	// Owner is the ID of the API key that created the alert, it is empty
+	// when authentication is disabled
+	Owner string `json:"owner,omitempty"`
+	Condition
+	WebhookURL string    `json:"webhookUrl"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// Holding is whether the condition held when it was last evaluated, the
+	// alert triggers again only once it has stopped holding
+	Holding     bool       `json:"holding"`
+	TriggeredAt *time.Time `json:"triggeredAt,omitempty"`
+}
+
+// storedAlert is an Alert as kept in the store
+type storedAlert struct {
+	Alert
+	// Secret signs the alert's webhook deliveries
+	Secret string `json:"secret"`
+}
+
+// DeadLetter is a webhook delivery that failed every attempt
+type DeadLetter struct {
+	ID         string `json:"id"`
+	AlertID    string `json:"alertId"`
+	Owner      string `json:"owner,omitempty"`
+	WebhookURL string `json:"webhookUrl"`
+	// Payload is the body that was sent
+	Payload  json.RawMessage `json:"payload"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failedAt"`
+}
+
+// Store keeps alerts and dead letters in a local bbolt database
+type Store struct {
+	db           *bolt.DB
+	orderService *order.OrderService
+}
+
+// Open opens, or creates, the store at the given path
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open alert store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{alertsBucket, deadLettersBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize alert store: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SetOrderService normalizes the symbols of alerts with orderService as they
+// are created or updated, symbols are stored as given by default
+func (s *Store) SetOrderService(orderService *order.OrderService) {
+	s.orderService = orderService
+}
+
+// Create creates an alert and returns it along with the secret its webhook
+// deliveries are signed with. The secret can't be recovered later
+func (s *Store) Create(owner string, condition Condition, webhookURL string) (*Alert, string, error) {
+	condition, err := s.validate(condition, webhookURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+
+	alert := storedAlert{
+		Alert: Alert{
+			ID:         uuid.NewString(),
+			Owner:      owner,
+			Condition:  condition,
+			WebhookURL: webhookURL,
+			CreatedAt:  time.Now().UTC(),
+		},
+		Secret: "whsec_" + base64.RawURLEncoding.EncodeToString(secret),
+	}
+
+	if err := s.put(alertsBucket, alert.ID, &alert); err != nil {
+		return nil, "", err
+	}
+	return &alert.Alert, alert.Secret, nil
+}
+
+// Get returns an owner's alert
+func (s *Store) Get(owner, id string) (*Alert, error) {
+	alert, err := s.get(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	return &alert.Alert, nil
+}
+
+// List returns an owner's alerts, oldest first
+func (s *Store) List(owner string) ([]*Alert, error) {
+	alerts, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+
+	owned := []*Alert{}
+	for _, alert := range alerts {
+		if alert.Owner == owner {
+			owned = append(owned, &alert.Alert)
+		}
+	}
+	return owned, nil
+}
+
+// Update replaces an owner's alert condition and webhook URL, the alert
+// starts over as if it had never held
+func (s *Store) Update(owner, id string, condition Condition, webhookURL string) (*Alert, error) {
+	condition, err := s.validate(condition, webhookURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *Alert
+	err = s.update(owner, id, func(alert *storedAlert) {
+		alert.Condition = condition
+		alert.WebhookURL = webhookURL
+		alert.Holding = false
+		alert.TriggeredAt = nil
+		updated = &alert.Alert
+	})
+	return updated, err
+}
+
+// Delete deletes an owner's alert, its dead letters are kept
+func (s *Store) Delete(owner, id string) error {
+	if _, err := s.get(owner, id); err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(alertsBucket).Delete([]byte(id))
+	})
+}
+
+// DeadLetters returns an owner's dead letters, oldest first
+func (s *Store) DeadLetters(owner string) ([]*DeadLetter, error) {
+	letters := []*DeadLetter{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLettersBucket).ForEach(func(_, v []byte) error {
+			var letter DeadLetter
+			if err := json.Unmarshal(v, &letter); err != nil {
+				return err
+			}
+			if letter.Owner == owner {
+				letters = append(letters, &letter)
+			}
+			return nil
+		})
+	})
+
+	sort.Slice(letters, func(i, j int) bool { return letters[i].FailedAt.Before(letters[j].FailedAt) })
+	return letters, err
+}
+
+// DeadLetter returns an owner's dead letter
+func (s *Store) DeadLetter(owner, id string) (*DeadLetter, error) {
+	var letter *DeadLetter
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(deadLettersBucket).Get([]byte(id))
+		if v == nil {
+			return ErrDeadLetterNotFound
+		}
+		letter = &DeadLetter{}
+		return json.Unmarshal(v, letter)
+	})
+	if err == nil && letter.Owner != owner {
+		return nil, ErrDeadLetterNotFound
+	}
+	return letter, err
+}
+
+// DeleteDeadLetter deletes an owner's dead letter
+func (s *Store) DeleteDeadLetter(owner, id string) error {
+	if _, err := s.DeadLetter(owner, id); err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLettersBucket).Delete([]byte(id))
+	})
+}
+
+// addDeadLetter stores a failed delivery
+func (s *Store) addDeadLetter(letter *DeadLetter) error {
+	return s.put(deadLettersBucket, letter.ID, letter)
+}
+
+// all returns every stored alert, oldest first
+func (s *Store) all() ([]*storedAlert, error) {
+	alerts := []*storedAlert{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(alertsBucket).ForEach(func(_, v []byte) error {
+			var alert storedAlert
+			if err := json.Unmarshal(v, &alert); err != nil {
+				return err
+			}
+			alerts = append(alerts, &alert)
+			return nil
+		})
+	})
+
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].CreatedAt.Before(alerts[j].CreatedAt) })
+	return alerts, err
+}
+
+// secret returns the webhook secret of an alert, whoever owns it
+func (s *Store) secret(id string) (string, error) {
+	var alert storedAlert
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(alertsBucket).Get([]byte(id))
+		if v == nil {
+			return ErrAlertNotFound
+		}
+		return json.Unmarshal(v, &alert)
+	})
+	return alert.Secret, err
+}
+
+// setState records whether an alert's condition holds, reporting false for
+// alerts deleted or changed since they were evaluated, which are left alone
+func (s *Store) setState(evaluated *storedAlert, holding bool, triggeredAt *time.Time) (bool, error) {
+	applied := false
+	err := s.update(evaluated.Owner, evaluated.ID, func(alert *storedAlert) {
+		if alert.Condition.equal(evaluated.Condition) {
+			alert.Holding = holding
+			if triggeredAt != nil {
+				alert.TriggeredAt = triggeredAt
+			}
+			applied = true
+		}
+	})
+	if errors.Is(err, ErrAlertNotFound) {
+		return false, nil
+	}
+	return applied, err
+}
+
+// get returns an owner's stored alert
+func (s *Store) get(owner, id string) (*storedAlert, error) {
+	var alert *storedAlert
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(alertsBucket).Get([]byte(id))
+		if v == nil {
+			return ErrAlertNotFound
+		}
+		alert = &storedAlert{}
+		return json.Unmarshal(v, alert)
+	})
+	if err == nil && alert.Owner != owner {
+		return nil, ErrAlertNotFound
+	}
+	return alert, err
+}
+
+// update changes an owner's stored alert in a single transaction
+func (s *Store) update(owner, id string, change func(*storedAlert)) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(alertsBucket)
+		v := bucket.Get([]byte(id))
+		if v == nil {
+			return ErrAlertNotFound
+		}
+		var alert storedAlert
+		if err := json.Unmarshal(v, &alert); err != nil {
+			return err
+		}
+		if alert.Owner != owner {
+			return ErrAlertNotFound
+		}
+
+		change(&alert)
+		data, err := json.Marshal(&alert)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(id), data)
+	})
+}
+
+// put stores a value as JSON
+func (s *Store) put(bucket []byte, id string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(id), data)
+	})
+}
+
+// validate checks an alert's condition and webhook URL, returning the
+// condition with its symbol normalized
+func (s *Store) validate(condition Condition, webhookURL string) (Condition, error) {
+	if err := condition.Validate(); err != nil {
+		return condition, err
+	}
+	if s.orderService != nil {
+		symbol, err := s.orderService.NormalizeSymbol(condition.Symbol)
+		if err != nil {
+			return condition, fmt.Errorf("%w: %w", ErrInvalidCondition, err)
+		}
+		condition.Symbol = symbol
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return condition, ErrInvalidWebhook
+	}
+	return condition, nil
+}
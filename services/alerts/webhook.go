@@ -0,0 +1,274 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/google/uuid"
+)
+
+// Headers sent with every webhook delivery. The signature is "sha256="
+// followed by the hex encoded HMAC-SHA256, keyed with the alert's secret, of
+// the timestamp, a dot and the body
+const (
+	EventIDHeader   = "X-Triumph-Event-ID"
+	TimestampHeader = "X-Triumph-Timestamp"
+	SignatureHeader = "X-Triumph-Signature"
+)
+
+// EventTriggered is the type of the events sent when an alert triggers
+const EventTriggered = "alert.triggered"
+
+// ErrPrivateAddress is returned for deliveries to loopback, private or link
+// local addresses, which any API key could otherwise make the server reach
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// Event is the body of a webhook delivery
+type Event struct {
+	// ID is the same on every attempt of a delivery, so receivers can drop
+	// duplicates
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	AlertID     string    `json:"alertId"`
+	Condition   Condition `json:"condition"`
+	Value       float64   `json:"value"`
+	TriggeredAt time.Time `json:"triggeredAt"`
+	// Market is the market the condition was evaluated against
+	Market *order.Market `json:"market"`
+}
+
+// Sign returns the signature of a delivery sent at timestamp, in unix seconds
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notifier delivers events to webhooks, retrying failed deliveries with
+// exponential backoff before moving them to the dead letters
+type Notifier struct {
+	store       *Store
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	timeout     time.Duration
+	logger      *slog.Logger
+
+	wg sync.WaitGroup
+}
+
+// NewNotifier creates a new Notifier making up to maxAttempts attempts per
+// delivery, waiting backoff after the first failed attempt and twice as long
+// after every following one. Webhooks on private addresses are refused
+func NewNotifier(store *Store, maxAttempts int, backoff, timeout time.Duration) *Notifier {
+	return &Notifier{
+		store:       store,
+		client:      newWebhookClient(timeout, false),
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		timeout:     timeout,
+		logger:      logging.Discard(),
+	}
+}
+
+// SetAllowPrivateNetworks lets deliveries reach loopback, private and link
+// local addresses, for webhooks running next to the server
+func (n *Notifier) SetAllowPrivateNetworks(allow bool) {
+	n.client = newWebhookClient(n.timeout, allow)
+}
+
+// SetLogger sets the logger failed deliveries are reported to, nothing is
+// logged by default
+func (n *Notifier) SetLogger(logger *slog.Logger) {
+	n.logger = logging.OrDiscard(logger)
+}
+
+// Notify delivers an event to an alert's webhook in the background.
+// Deliveries interrupted by ctx are moved to the dead letters
+func (n *Notifier) Notify(ctx context.Context, alert *Alert, event *Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "failed to encode webhook event", "alert", alert.ID, "error", err)
+		return
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.deliver(ctx, &DeadLetter{
+			ID:         event.ID,
+			AlertID:    alert.ID,
+			Owner:      alert.Owner,
+			WebhookURL: alert.WebhookURL,
+			Payload:    body,
+		})
+	}()
+}
+
+// Redeliver makes one more attempt at a dead letter, deleting it when the
+// webhook accepts it
+func (n *Notifier) Redeliver(ctx context.Context, owner, id string) (*DeadLetter, error) {
+	letter, err := n.store.DeadLetter(owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	letter.Attempts++
+	if _, err := n.attempt(ctx, letter); err != nil {
+		letter.Error = err.Error()
+		letter.FailedAt = time.Now().UTC()
+		if storeErr := n.store.addDeadLetter(letter); storeErr != nil {
+			return nil, storeErr
+		}
+		return letter, err
+	}
+
+	return letter, n.store.DeleteDeadLetter(owner, id)
+}
+
+// Wait waits for the deliveries in flight
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+// deliver attempts a delivery until it succeeds, fails for good or runs out
+// of attempts, then moves it to the dead letters
+func (n *Notifier) deliver(ctx context.Context, letter *DeadLetter) {
+	err := n.retry(ctx, letter)
+	if err == nil {
+		return
+	}
+
+	n.logger.WarnContext(ctx, "webhook delivery failed",
+		"alert", letter.AlertID, "event", letter.ID, "attempts", letter.Attempts, "error", err)
+
+	letter.Error = err.Error()
+	letter.FailedAt = time.Now().UTC()
+	if err := n.store.addDeadLetter(letter); err != nil {
+		n.logger.ErrorContext(ctx, "failed to store dead letter", "alert", letter.AlertID, "event", letter.ID, "error", err)
+	}
+}
+
+// retry attempts a delivery until it succeeds, fails for good, runs out of
+// attempts or ctx is done, returning the last error
+func (n *Notifier) retry(ctx context.Context, letter *DeadLetter) error {
+	for {
+		letter.Attempts++
+		retry, err := n.attempt(ctx, letter)
+		if err == nil || !retry || letter.Attempts >= n.maxAttempts {
+			return err
+		}
+
+		// Double the wait after every failed attempt
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("delivery interrupted after %d attempts: %w", letter.Attempts, err)
+		case <-time.After(n.backoff << (letter.Attempts - 1)):
+		}
+	}
+}
+
+// attempt posts a delivery once, reporting whether a failure is worth
+// retrying. Network errors, 429s and 5xxs are, other responses aren't
+func (n *Notifier) attempt(ctx context.Context, letter *DeadLetter) (bool, error) {
+	secret, err := n.store.secret(letter.AlertID)
+	if err != nil {
+		return false, err
+	}
+
+	// Bound the attempt without cutting it off at shutdown, the delivery
+	// has already been decided on
+	ctx = context.WithoutCancel(ctx)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, letter.WebhookURL, bytes.NewReader(letter.Payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "triumph-alerts/1")
+	req.Header.Set(EventIDHeader, letter.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, letter.Payload))
+
+	resp, err := n.client.Do(req)
+	if errors.Is(err, ErrPrivateAddress) {
+		return false, err
+	}
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+}
+
+// newWebhookClient returns the client deliveries are posted with. Unless
+// allowPrivate is set, addresses are checked as they are dialed, after DNS
+// resolution, so neither redirects nor rebinding reach private addresses
+func newWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer.Control = checkPublic
+		// A proxy would dial the webhook on the server's behalf
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// checkPublic refuses connections to addresses that aren't public
+func checkPublic(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, private to providers
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// newEvent creates the event sent when an alert triggers
+func newEvent(alert *Alert, value float64, market *order.Market, now time.Time) *Event {
+	return &Event{
+		ID:          uuid.NewString(),
+		Type:        EventTriggered,
+		AlertID:     alert.ID,
+		Condition:   alert.Condition,
+		Value:       value,
+		TriggeredAt: now,
+		Market:      market,
+	}
+}
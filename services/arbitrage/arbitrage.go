@@ -0,0 +1,291 @@
+package arbitrage
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+// Opportunity is a fee inclusive edge from buying on one venue and selling on
+// another
+type Opportunity struct {
+	Symbol       string  `json:"symbol"`
+	BuyExchange  string  `json:"buyExchange"`
+	SellExchange string  `json:"sellExchange"`
+	BuyPrice     float64 `json:"buyPrice"`
+	SellPrice    float64 `json:"sellPrice"`
+	// EdgeBps is the fee inclusive edge at the top of both books
+	EdgeBps float64 `json:"edgeBps"`
+	// Size is how much can be bought and sold before the fee inclusive edge
+	// disappears, it is zero when either venue doesn't provide depth
+	Size float64 `json:"size"`
+	// AvgBuyPrice, AvgSellPrice and ProfitUSD are fee inclusive and cover Size
+	AvgBuyPrice  float64   `json:"avgBuyPrice,omitempty"`
+	AvgSellPrice float64   `json:"avgSellPrice,omitempty"`
+	ProfitUSD    float64   `json:"profitUsd"`
+	DetectedAt   time.Time `json:"detectedAt"`
+}
+
+// key identifies an opportunity across scans
+func (o Opportunity) key() string {
+	return o.Symbol + "/" + o.BuyExchange + "/" + o.SellExchange
+}
+
+// EventType describes what happened to an opportunity
+type EventType string
+
+const (
+	EventOpened EventType = "opened"
+	EventClosed EventType = "closed"
+)
+
+// Event is published when an opportunity opens or closes
+type Event struct {
+	Type        EventType   `json:"type"`
+	Opportunity Opportunity `json:"opportunity"`
+}
+
+// Scanner periodically checks a list of symbols for arbitrage between venues.
+// It only reports opportunities, it never trades them
+type Scanner struct {
+	orderService *order.OrderService
+	symbols      []string
+	interval     time.Duration
+	minEdgeBps   float64
+	logger       *slog.Logger
+
+	mu          sync.RWMutex
+	latest      map[string]Opportunity
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+// NewScanner creates a new Scanner checking symbols every interval and
+// reporting edges above minEdgeBps
+func NewScanner(orderService *order.OrderService, symbols []string, interval time.Duration, minEdgeBps float64) *Scanner {
+	return &Scanner{
+		orderService: orderService,
+		symbols:      symbols,
+		interval:     interval,
+		minEdgeBps:   minEdgeBps,
+		logger:       logging.Discard(),
+		latest:       map[string]Opportunity{},
+		subscribers:  map[chan Event]struct{}{},
+	}
+}
+
+// SetLogger sets the logger opened opportunities are reported to, nothing is
+// logged by default
+func (s *Scanner) SetLogger(logger *slog.Logger) {
+	s.logger = logging.OrDiscard(logger)
+}
+
+// Run scans on every interval until the context is cancelled, then ends
+// every subscription
+func (s *Scanner) Run(ctx context.Context) {
+	defer s.close()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan checks every symbol once, updating the latest opportunities and
+// publishing events for the ones that opened or closed
+func (s *Scanner) Scan(ctx context.Context) {
+	found := map[string]Opportunity{}
+	for _, symbol := range s.symbols {
+		books := s.orderService.Books(ctx, symbol)
+		for _, opp := range Find(symbol, books, s.minEdgeBps) {
+			found[opp.key()] = opp
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, opp := range found {
+		if _, ok := s.latest[key]; !ok {
+			s.logger.InfoContext(ctx, "arbitrage opened",
+				"symbol", opp.Symbol,
+				"buy_exchange", opp.BuyExchange, "buy_price", opp.BuyPrice,
+				"sell_exchange", opp.SellExchange, "sell_price", opp.SellPrice,
+				"edge_bps", opp.EdgeBps)
+			s.publish(Event{Type: EventOpened, Opportunity: opp})
+		}
+	}
+	for key, opp := range s.latest {
+		if _, ok := found[key]; !ok {
+			s.publish(Event{Type: EventClosed, Opportunity: opp})
+		}
+	}
+
+	s.latest = found
+}
+
+// Opportunities returns the opportunities found by the latest scan, best
+// edge first
+func (s *Scanner) Opportunities() []Opportunity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	opps := make([]Opportunity, 0, len(s.latest))
+	for _, opp := range s.latest {
+		opps = append(opps, opp)
+	}
+	sort.Slice(opps, func(i, j int) bool { return opps[i].EdgeBps > opps[j].EdgeBps })
+
+	return opps
+}
+
+// Subscribe returns a channel receiving every event and a function to stop
+// the subscription. Events are dropped for subscribers that fall behind
+func (s *Scanner) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	s.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}
+}
+
+// close ends every subscription, later subscriptions end immediately
+func (s *Scanner) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for ch := range s.subscribers {
+		close(ch)
+		delete(s.subscribers, ch)
+	}
+}
+
+// publish sends an event to every subscriber, the caller must hold the lock
+func (s *Scanner) publish(event Event) {
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Find returns every fee inclusive opportunity above minEdgeBps between the
+// given books
+func Find(symbol string, books []order.VenueBook, minEdgeBps float64) []Opportunity {
+	opps := []Opportunity{}
+	now := time.Now()
+
+	for _, buy := range books {
+		for _, sell := range books {
+			if buy.Exchange == sell.Exchange || buy.Err != nil || sell.Err != nil {
+				continue
+			}
+
+			opp, ok := edge(buy, sell)
+			if !ok || opp.EdgeBps <= minEdgeBps {
+				continue
+			}
+			opp.Symbol = symbol
+			opp.DetectedAt = now
+			opps = append(opps, opp)
+		}
+	}
+
+	return opps
+}
+
+// edge computes the opportunity from buying on the asks of buy and selling on
+// the bids of sell, walking both books while the fee inclusive edge holds
+func edge(buy, sell order.VenueBook) (Opportunity, bool) {
+	asks, bids := buy.Book.Asks, sell.Book.Bids
+	if len(asks) == 0 || len(bids) == 0 {
+		return Opportunity{}, false
+	}
+
+	opp := Opportunity{
+		BuyExchange:  buy.Exchange,
+		SellExchange: sell.Exchange,
+		BuyPrice:     asks[0].Price,
+		SellPrice:    bids[0].Price,
+	}
+	netBuy := netPrice(asks[0], buy.Fee, 1)
+	netSell := netPrice(bids[0], sell.Fee, -1)
+	opp.EdgeBps = (netSell - netBuy) / netBuy * 1e4
+	if opp.EdgeBps <= 0 {
+		return opp, false
+	}
+
+	// Match the two books level by level while selling still nets more
+	// than buying costs
+	var cost, proceeds float64
+	askLeft, bidLeft := levelSizes(asks), levelSizes(bids)
+	for i, j := 0, 0; i < len(asks) && j < len(bids); {
+		if netPrice(bids[j], sell.Fee, -1) <= netPrice(asks[i], buy.Fee, 1) {
+			break
+		}
+
+		size := min(askLeft[i], bidLeft[j])
+		opp.Size += size
+		cost += size * netPrice(asks[i], buy.Fee, 1)
+		proceeds += size * netPrice(bids[j], sell.Fee, -1)
+		askLeft[i] -= size
+		bidLeft[j] -= size
+
+		if askLeft[i] <= 0 {
+			i++
+		}
+		if bidLeft[j] <= 0 {
+			j++
+		}
+	}
+
+	if opp.Size > 0 {
+		opp.AvgBuyPrice = cost / opp.Size
+		opp.AvgSellPrice = proceeds / opp.Size
+		opp.ProfitUSD = proceeds - cost
+	}
+
+	return opp, true
+}
+
+// netPrice applies the taker fee to a level price, sign is 1 when buying and
+// -1 when selling
+func netPrice(level exchange.Level, fee float64, sign float64) float64 {
+	return level.Price * (1 + sign*fee)
+}
+
+// levelSizes copies the sizes of the levels so they can be consumed
+func levelSizes(levels []exchange.Level) []float64 {
+	sizes := make([]float64, len(levels))
+	for i, level := range levels {
+		sizes[i] = level.Size
+	}
+	return sizes
+}
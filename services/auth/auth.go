@@ -0,0 +1,374 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Scope grants access to a group of endpoints
+type Scope string
+
+const (
+	// ScopeQuote grants quotes and market data
+	ScopeQuote Scope = "quote"
+	// ScopeTrade grants placing orders and paper trading
+	ScopeTrade Scope = "trade"
+	// ScopeAdmin grants managing the service, and implies every other scope
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes lists every scope a key can be given
+var Scopes = []Scope{ScopeQuote, ScopeTrade, ScopeAdmin}
+
+// tokenPrefix starts every API key so they are easy to recognize, e.g. by
+// secret scanners
+const tokenPrefix = "tri_"
+
+// Buckets used by the store. Keys are stored by ID with a hash of their
+// secret, usage holds the requests made per key and UTC day
+var (
+	keysBucket  = []byte("keys")
+	usageBucket = []byte("usage")
+)
+
+var (
+	// ErrInvalidKey is returned for keys that are malformed, unknown or revoked
+	ErrInvalidKey = errors.New("invalid API key")
+	// ErrKeyNotFound is returned when managing a key that doesn't exist
+	ErrKeyNotFound = errors.New("API key not found")
+	// ErrInvalidScope is returned when creating a key with an unknown scope
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrInvalidLimit is returned when creating a key with a negative rate
+	// limit or quota
+	ErrInvalidLimit = errors.New("rate limit and daily quota must not be negative")
+	// ErrQuotaExceeded is returned once a key has used its daily quota
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+	// ErrRateLimited is returned once a key has used its rate limit for the
+	// current window
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrMissingKey is returned for requests sent without a key
+	ErrMissingKey = errors.New("missing API key")
+	// ErrInsufficientScope is returned for keys lacking the scope a request
+	// needs
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// APIKey describes a client's key, the key itself is only known when it is
+// created
+type APIKey struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Scopes []Scope `json:"scopes"`
+	// RateLimit is the requests allowed per minute, zero means unlimited
+	RateLimit int `json:"rateLimit"`
+	// DailyQuota is the requests allowed per UTC day, zero means unlimited
+	DailyQuota int        `json:"dailyQuota"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Allows reports whether the key has been granted scope
+func (k *APIKey) Allows(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckScope returns an error matching ErrInsufficientScope unless the key
+// has been granted scope
+func (k *APIKey) CheckScope(scope Scope) error {
+	if k.Allows(scope) {
+		return nil
+	}
+	return &scopeError{scope: scope}
+}
+
+// scopeError names the scope a key lacks
+type scopeError struct {
+	scope Scope
+}
+
+func (e *scopeError) Error() string {
+	return "API key lacks the " + string(e.scope) + " scope"
+}
+
+func (e *scopeError) Is(target error) bool {
+	return target == ErrInsufficientScope
+}
+
+// storedKey is an APIKey as kept in the store
+type storedKey struct {
+	APIKey
+	// Hash is the hex encoded SHA-256 of the key's secret. Secrets are 256
+	// bits of randomness, so a slow password hash would add nothing
+	Hash string `json:"hash"`
+}
+
+// Usage is a key's consumption of its daily quota
+type Usage struct {
+	Used  int
+	Limit int
+	Reset time.Time
+}
+
+// Store keeps API keys and their usage in a local bbolt database
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens, or creates, the store at the given path
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{keysBucket, usageBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize key store: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// ParseScopes parses a comma separated list of scopes
+func ParseScopes(list string) ([]Scope, error) {
+	scopes := []Scope{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		scope := Scope(strings.ToLower(item))
+		if !validScope(scope) {
+			return nil, fmt.Errorf("%w %q", ErrInvalidScope, item)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// Create creates a key and returns it along with the token clients send.
+// The token can't be recovered later
+func (s *Store) Create(name string, scopes []Scope, rateLimit, dailyQuota int) (*APIKey, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+	for _, scope := range scopes {
+		if !validScope(scope) {
+			return nil, "", fmt.Errorf("%w %q", ErrInvalidScope, scope)
+		}
+	}
+	if rateLimit < 0 || dailyQuota < 0 {
+		return nil, "", ErrInvalidLimit
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+
+	key := storedKey{
+		APIKey: APIKey{
+			ID:         id,
+			Name:       name,
+			Scopes:     scopes,
+			RateLimit:  rateLimit,
+			DailyQuota: dailyQuota,
+			CreatedAt:  time.Now().UTC(),
+		},
+		Hash: hashSecret(encodedSecret),
+	}
+
+	if err := s.put(&key); err != nil {
+		return nil, "", err
+	}
+	return &key.APIKey, tokenPrefix + id + "." + encodedSecret, nil
+}
+
+// List returns every key, oldest first
+func (s *Store) List() ([]*APIKey, error) {
+	keys := []*APIKey{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(keysBucket).ForEach(func(_, v []byte) error {
+			var key storedKey
+			if err := json.Unmarshal(v, &key); err != nil {
+				return err
+			}
+			keys = append(keys, &key.APIKey)
+			return nil
+		})
+	})
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, err
+}
+
+// Revoke revokes the key with the given ID, revoking a key twice keeps the
+// original revocation time
+func (s *Store) Revoke(id string) (*APIKey, error) {
+	key, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt == nil {
+		now := time.Now().UTC()
+		key.RevokedAt = &now
+		if err := s.put(key); err != nil {
+			return nil, err
+		}
+	}
+	return &key.APIKey, nil
+}
+
+// Authenticate returns the key a token belongs to
+func (s *Store) Authenticate(token string) (*APIKey, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, tokenPrefix), ".")
+	if !strings.HasPrefix(token, tokenPrefix) || !ok {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := s.get(id)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.Hash)) != 1 || key.RevokedAt != nil {
+		return nil, ErrInvalidKey
+	}
+	return &key.APIKey, nil
+}
+
+// Consume counts a request against the key's daily quota. Once the quota is
+// used up it returns ErrQuotaExceeded without counting the request. Keys
+// without a quota aren't counted, and only today's count of a key is kept
+func (s *Store) Consume(key *APIKey, now time.Time) (Usage, error) {
+	now = now.UTC()
+	day := []byte(now.Format(time.DateOnly))
+	usage := Usage{
+		Limit: key.DailyQuota,
+		Reset: time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC),
+	}
+	if usage.Limit <= 0 {
+		return usage, nil
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		keyUsage, err := tx.Bucket(usageBucket).CreateBucketIfNotExists([]byte(key.ID))
+		if err != nil {
+			return err
+		}
+
+		// Drop the counts of previous days, they are never read again
+		stale := [][]byte{}
+		keyUsage.ForEach(func(k, _ []byte) error {
+			if !bytes.Equal(k, day) {
+				stale = append(stale, bytes.Clone(k))
+			}
+			return nil
+		})
+		for _, k := range stale {
+			if err := keyUsage.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		if v := keyUsage.Get(day); v != nil {
+			usage.Used = int(binary.BigEndian.Uint64(v))
+		}
+		if usage.Used >= usage.Limit {
+			return ErrQuotaExceeded
+		}
+
+		usage.Used++
+		return keyUsage.Put(day, binary.BigEndian.AppendUint64(nil, uint64(usage.Used)))
+	})
+
+	return usage, err
+}
+
+// get returns the stored key with the given ID
+func (s *Store) get(id string) (*storedKey, error) {
+	var key *storedKey
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(keysBucket).Get([]byte(id))
+		if v == nil {
+			return ErrKeyNotFound
+		}
+		key = &storedKey{}
+		return json.Unmarshal(v, key)
+	})
+	return key, err
+}
+
+// put stores a key
+func (s *Store) put(key *storedKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(keysBucket).Put([]byte(key.ID), data)
+	})
+}
+
+// validScope reports whether scope is known
+func validScope(scope Scope) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// hashSecret returns the hex encoded SHA-256 of a key's secret
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
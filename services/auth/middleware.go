@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Header clients may send their key in, instead of an Authorization bearer
+// token
+const KeyHeader = "X-API-Key"
+
+// KeyQuery is the query parameter streaming clients may send their key in,
+// browsers can't set headers on EventSource or WebSocket requests
+const KeyQuery = "api_key"
+
+// rateWindow is the window a key's rate limit applies to
+const rateWindow = time.Minute
+
+// localsKey is where the authenticated key is kept on the request
+const localsKey = "apiKey"
+
+// Authenticator checks API keys, scopes, rate limits and quotas
+type Authenticator struct {
+	store *Store
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// window counts a key's requests in the current rate limit window
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewAuthenticator creates a new Authenticator for the keys in store
+func NewAuthenticator(store *Store) *Authenticator {
+	return &Authenticator{store: store, windows: map[string]*window{}}
+}
+
+// Key returns the key a request was authenticated with
+func Key(c *fiber.Ctx) *APIKey {
+	key, _ := c.Locals(localsKey).(*APIKey)
+	return key
+}
+
+// Require returns middleware letting requests through only with a valid key
+// granted scope and within the key's rate limit and daily quota
+func (a *Authenticator) Require(scope Scope) fiber.Handler {
+	return a.require(scope, false)
+}
+
+// RequireStream is Require for streaming endpoints, which also accept the
+// key in the KeyQuery parameter
+func (a *Authenticator) RequireStream(scope Scope) fiber.Handler {
+	return a.require(scope, true)
+}
+
+// require checks the key of a request, read from the query string as a last
+// resort when fromQuery is set
+func (a *Authenticator) require(scope Scope, fromQuery bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Get(KeyHeader)
+		if bearer, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+			token = bearer
+		}
+		if token == "" && fromQuery {
+			token = c.Query(KeyQuery)
+		}
+		if token == "" {
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return fail(c, http.StatusUnauthorized, ErrMissingKey)
+		}
+
+		key, err := a.store.Authenticate(token)
+		if errors.Is(err, ErrInvalidKey) {
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return fail(c, http.StatusUnauthorized, err)
+		}
+		if err != nil {
+			return fail(c, http.StatusInternalServerError, err)
+		}
+		if err := key.CheckScope(scope); err != nil {
+			return fail(c, http.StatusForbidden, err)
+		}
+
+		now := time.Now()
+
+		if key.RateLimit > 0 {
+			remaining, reset, ok := a.allow(key, now)
+			c.Set("X-RateLimit-Limit", strconv.Itoa(key.RateLimit))
+			c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			c.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			if !ok {
+				return tooManyRequests(c, now, reset, ErrRateLimited)
+			}
+		}
+
+		usage, err := a.store.Consume(key, now)
+		if key.DailyQuota > 0 {
+			c.Set("X-Quota-Limit", strconv.Itoa(usage.Limit))
+			c.Set("X-Quota-Remaining", strconv.Itoa(usage.Limit-usage.Used))
+			c.Set("X-Quota-Reset", strconv.FormatInt(usage.Reset.Unix(), 10))
+		}
+		if errors.Is(err, ErrQuotaExceeded) {
+			return tooManyRequests(c, now, usage.Reset, err)
+		}
+		if err != nil {
+			return fail(c, http.StatusInternalServerError, err)
+		}
+
+		c.Locals(localsKey, key)
+		return c.Next()
+	}
+}
+
+// Authenticate returns the key a token belongs to, for transports that
+// can't use Require
+func (a *Authenticator) Authenticate(token string) (*APIKey, error) {
+	return a.store.Authenticate(token)
+}
+
+// Admit counts a request against the key's rate limit and daily quota, for
+// transports that can't use Require. It returns ErrRateLimited or
+// ErrQuotaExceeded when the request is over either
+func (a *Authenticator) Admit(key *APIKey, now time.Time) error {
+	if key.RateLimit > 0 {
+		if _, _, ok := a.allow(key, now); !ok {
+			return ErrRateLimited
+		}
+	}
+	_, err := a.store.Consume(key, now)
+	return err
+}
+
+// allow counts a request against the key's rate limit, returning how many
+// requests are left in the window and when it resets
+func (a *Authenticator) allow(key *APIKey, now time.Time) (int, time.Time, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.windows[key.ID]
+	if !ok || !now.Before(w.start.Add(rateWindow)) {
+		w = &window{start: now.Truncate(rateWindow)}
+		a.windows[key.ID] = w
+	}
+	reset := w.start.Add(rateWindow)
+
+	if w.count >= key.RateLimit {
+		return 0, reset, false
+	}
+	w.count++
+	return key.RateLimit - w.count, reset, true
+}
+
+// tooManyRequests responds with 429, telling the client when to retry
+func tooManyRequests(c *fiber.Ctx, now, reset time.Time, err error) error {
+	retryAfter := int(reset.Sub(now).Round(time.Second) / time.Second)
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+	return fail(c, http.StatusTooManyRequests, err)
+}
+
+// fail responds with the error and its code
+func fail(c *fiber.Ctx, status int, err error) error {
+	return c.Status(status).JSON(fiber.Map{"error": err.Error(), "code": ErrorCode(err)})
+}
+
+// ErrorCode returns the machine readable code of an authentication failure,
+// gRPC sends the same codes as ErrorInfo reasons
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrMissingKey):
+		return "missing_api_key"
+	case errors.Is(err, ErrInvalidKey):
+		return "invalid_api_key"
+	case errors.Is(err, ErrInsufficientScope):
+		return "insufficient_scope"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrQuotaExceeded):
+		return "quota_exceeded"
+	default:
+		return "internal_error"
+	}
+}
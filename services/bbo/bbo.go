@@ -0,0 +1,230 @@
+package bbo
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+// Event is a change of the aggregated best bid or offer of a symbol, with the
+// top of book of every venue. Sequence increases by one with every event of
+// a symbol, so clients can tell when they missed one
+type Event struct {
+	Sequence uint64    `json:"sequence"`
+	Time     time.Time `json:"time"`
+	order.Market
+}
+
+// Poller polls the top of book of every symbol someone is subscribed to and
+// fans changes out to the subscribers, so upstream calls don't grow with the
+// number of clients
+type Poller struct {
+	orderService *order.OrderService
+	interval     time.Duration
+	logger       *slog.Logger
+
+	mu      sync.Mutex
+	symbols map[string]*feed
+	closed  bool
+}
+
+// feed is the state of one polled symbol
+type feed struct {
+	subscribers map[chan Event]struct{}
+	latest      *Event
+}
+
+// NewPoller creates a new Poller polling on the given interval
+func NewPoller(orderService *order.OrderService, interval time.Duration) *Poller {
+	return &Poller{
+		orderService: orderService,
+		interval:     interval,
+		logger:       logging.Discard(),
+		symbols:      map[string]*feed{},
+	}
+}
+
+// SetLogger sets the logger polling failures are reported to, nothing is
+// logged by default
+func (p *Poller) SetLogger(logger *slog.Logger) {
+	p.logger = logging.OrDiscard(logger)
+}
+
+// Run polls on every interval until the context is cancelled, then ends
+// every subscription
+func (p *Poller) Run(ctx context.Context) {
+	defer p.close()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll polls every subscribed symbol once, publishing an event for each one
+// whose best bid or offer changed
+func (p *Poller) Poll(ctx context.Context) {
+	p.mu.Lock()
+	symbols := make([]string, 0, len(p.symbols))
+	for symbol := range p.symbols {
+		symbols = append(symbols, symbol)
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func(symbol string) {
+			defer wg.Done()
+
+			market, err := p.orderService.Market(ctx, symbol)
+			if err != nil {
+				p.logger.WarnContext(ctx, "failed to poll market", "symbol", symbol, "error", err)
+				return
+			}
+			p.update(symbol, market, time.Now())
+		}(symbol)
+	}
+	wg.Wait()
+}
+
+// Subscribe returns a channel receiving the events of the given symbols and
+// a function to stop the subscription, which closes the channel. The latest event of each symbol is
+// sent straight away when there is one. Events are dropped for subscribers
+// that fall behind
+func (p *Poller) Subscribe(symbols ...string) (<-chan Event, func()) {
+	ch := make(chan Event, 16+len(symbols))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	for _, symbol := range symbols {
+		f, ok := p.symbols[symbol]
+		if !ok {
+			f = &feed{subscribers: map[chan Event]struct{}{}}
+			p.symbols[symbol] = f
+		}
+		f.subscribers[ch] = struct{}{}
+		if f.latest != nil {
+			ch <- *f.latest
+		}
+	}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { p.unsubscribe(ch, symbols) })
+	}
+}
+
+// Symbols returns the symbols currently polled
+func (p *Poller) Symbols() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	symbols := make([]string, 0, len(p.symbols))
+	for symbol := range p.symbols {
+		symbols = append(symbols, symbol)
+	}
+	slices.Sort(symbols)
+	return symbols
+}
+
+// unsubscribe removes a subscriber and closes its channel, symbols without
+// subscribers stop being polled
+func (p *Poller) unsubscribe(ch chan Event, symbols []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The channel was already closed if the poller stopped
+	subscribed := false
+	for _, symbol := range symbols {
+		f, ok := p.symbols[symbol]
+		if !ok {
+			continue
+		}
+		if _, ok := f.subscribers[ch]; ok {
+			subscribed = true
+			delete(f.subscribers, ch)
+		}
+		if len(f.subscribers) == 0 {
+			delete(p.symbols, symbol)
+		}
+	}
+	if subscribed {
+		close(ch)
+	}
+}
+
+// update publishes an event when the best bid or offer of the market polled
+// for symbol changed. The market's symbol is normalized, so it may differ
+// from the one subscribers asked for
+func (p *Poller) update(symbol string, market *order.Market, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Nobody may be listening anymore
+	f, ok := p.symbols[symbol]
+	if !ok {
+		return
+	}
+	if f.latest != nil && sameBest(f.latest.BestBid, market.BestBid) && sameBest(f.latest.BestAsk, market.BestAsk) {
+		return
+	}
+
+	event := Event{Time: now.UTC(), Market: *market}
+	if f.latest != nil {
+		event.Sequence = f.latest.Sequence + 1
+	} else {
+		event.Sequence = 1
+	}
+	f.latest = &event
+
+	for ch := range f.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// close ends every subscription, later subscriptions end immediately
+func (p *Poller) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	closed := map[chan Event]struct{}{}
+	for _, f := range p.symbols {
+		for ch := range f.subscribers {
+			if _, ok := closed[ch]; !ok {
+				close(ch)
+				closed[ch] = struct{}{}
+			}
+		}
+	}
+	p.symbols = map[string]*feed{}
+}
+
+// sameBest reports whether two best prices are quoted at the same price by
+// the same venues
+func sameBest(a, b order.BestPrice) bool {
+	return a.Price == b.Price && slices.Equal(a.Exchanges, b.Exchanges)
+}
@@ -0,0 +1,330 @@
+package candles
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Aggregate is the venue name used for candles built from the best bid and
+// offer across every venue
+const Aggregate = "all"
+
+// Intervals candles are rolled up into, keyed by the name used in the API
+var Intervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+}
+
+// Buckets used by the store. Both hold one nested bucket per series, samples
+// per symbol and candles per symbol, venue and interval
+var (
+	samplesBucket = []byte("samples")
+	candlesBucket = []byte("candles")
+)
+
+// ErrUnknownInterval is returned when a candle interval isn't supported
+var ErrUnknownInterval = errors.New("unknown interval")
+
+// pruneInterval is how often the recorder drops samples past their retention
+const pruneInterval = time.Hour
+
+// Sample is the best bid and ask of every venue for a symbol at one point in
+// time, along with the aggregated best bid and offer
+type Sample struct {
+	Time    time.Time    `json:"time"`
+	Symbol  string       `json:"symbol"`
+	Venues  []VenuePrice `json:"venues"`
+	BestBid float64      `json:"bestBid"`
+	BestAsk float64      `json:"bestAsk"`
+}
+
+// VenuePrice is the top of book of a single venue in a sample
+type VenuePrice struct {
+	Exchange string  `json:"exchange"`
+	Bid      float64 `json:"bid"`
+	Ask      float64 `json:"ask"`
+}
+
+// Candle is the OHLC of the mid price over one interval
+type Candle struct {
+	Time    time.Time `json:"time"`
+	Open    float64   `json:"open"`
+	High    float64   `json:"high"`
+	Low     float64   `json:"low"`
+	Close   float64   `json:"close"`
+	Samples int       `json:"samples"`
+}
+
+// Store keeps price samples and the candles rolled up from them in a local
+// bbolt database
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens, or creates, the store at the given path
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open candle store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{samplesBucket, candlesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize candle store: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add stores a sample and folds it into the candles of every interval, for
+// each venue and for the aggregate
+func (s *Store) Add(sample *Sample) error {
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		samples, err := tx.Bucket(samplesBucket).CreateBucketIfNotExists([]byte(sample.Symbol))
+		if err != nil {
+			return err
+		}
+		if err := samples.Put(timeKey(sample.Time), data); err != nil {
+			return err
+		}
+
+		mids := map[string]float64{Aggregate: (sample.BestBid + sample.BestAsk) / 2}
+		for _, venue := range sample.Venues {
+			mids[venue.Exchange] = (venue.Bid + venue.Ask) / 2
+		}
+
+		for venue, mid := range mids {
+			for name, interval := range Intervals {
+				if err := fold(tx, seriesName(sample.Symbol, venue, name), sample.Time.Truncate(interval), mid); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// fold updates the candle starting at start with a new mid price
+func fold(tx *bolt.Tx, series string, start time.Time, mid float64) error {
+	bucket, err := tx.Bucket(candlesBucket).CreateBucketIfNotExists([]byte(series))
+	if err != nil {
+		return err
+	}
+
+	key := timeKey(start)
+	candle := Candle{Time: start.UTC(), Open: mid, High: mid, Low: mid}
+	if data := bucket.Get(key); data != nil {
+		if err := json.Unmarshal(data, &candle); err != nil {
+			return err
+		}
+	}
+
+	candle.High = max(candle.High, mid)
+	candle.Low = min(candle.Low, mid)
+	candle.Close = mid
+	candle.Samples++
+
+	data, err := json.Marshal(candle)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+// Candles returns the candles of a symbol for a venue, or Aggregate, between
+// from and to, oldest first. A zero from or to leaves that end open and at
+// most limit candles are returned
+func (s *Store) Candles(symbol, venue, interval string, from, to time.Time, limit int) ([]Candle, error) {
+	if _, ok := Intervals[interval]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownInterval, interval)
+	}
+
+	candles := []Candle{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(candlesBucket).Bucket([]byte(seriesName(symbol, venue, interval)))
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.Seek(timeKey(from)); k != nil && len(candles) < limit; k, v = c.Next() {
+			var candle Candle
+			if err := json.Unmarshal(v, &candle); err != nil {
+				return err
+			}
+			if !to.IsZero() && candle.Time.After(to) {
+				break
+			}
+			candles = append(candles, candle)
+		}
+		return nil
+	})
+
+	return candles, err
+}
+
+// Prune deletes the samples of every symbol taken before the given time,
+// returning how many were deleted. Candles are kept
+func (s *Store) Prune(before time.Time) (int, error) {
+	deleted := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		samples := tx.Bucket(samplesBucket)
+		return samples.ForEachBucket(func(symbol []byte) error {
+			bucket := samples.Bucket(symbol)
+
+			// Collect first, deleting while iterating skips keys
+			stale := [][]byte{}
+			c := bucket.Cursor()
+			for k, _ := c.First(); k != nil && bytes.Compare(k, timeKey(before)) < 0; k, _ = c.Next() {
+				stale = append(stale, bytes.Clone(k))
+			}
+			for _, k := range stale {
+				if err := bucket.Delete(k); err != nil {
+					return err
+				}
+			}
+			deleted += len(stale)
+			return nil
+		})
+	})
+	return deleted, err
+}
+
+// seriesName names the candle bucket of a symbol, venue and interval
+func seriesName(symbol, venue, interval string) string {
+	return symbol + "/" + venue + "/" + interval
+}
+
+// timeKey encodes a time as big endian nanoseconds so keys sort by time, the
+// zero time sorts first
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	if !t.IsZero() {
+		binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	}
+	return key
+}
+
+// Recorder samples the top of book of a list of symbols on an interval and
+// stores the samples
+type Recorder struct {
+	orderService *order.OrderService
+	store        *Store
+	symbols      []string
+	interval     time.Duration
+	retention    time.Duration
+	logger       *slog.Logger
+}
+
+// NewRecorder creates a new Recorder sampling symbols every interval
+func NewRecorder(orderService *order.OrderService, store *Store, symbols []string, interval time.Duration) *Recorder {
+	return &Recorder{orderService: orderService, store: store, symbols: symbols, interval: interval, logger: logging.Discard()}
+}
+
+// SetLogger sets the logger sampling failures are reported to, nothing is
+// logged by default
+func (r *Recorder) SetLogger(logger *slog.Logger) {
+	r.logger = logging.OrDiscard(logger)
+}
+
+// SetRetention sets how long samples are kept, they are kept forever by
+// default. Candles are kept whatever the retention
+func (r *Recorder) SetRetention(retention time.Duration) {
+	r.retention = retention
+}
+
+// Run samples on every interval until the context is cancelled, pruning
+// samples past their retention every hour
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var pruned time.Time
+	for {
+		now := time.Now()
+		r.Sample(ctx, now)
+		if now.Sub(pruned) >= pruneInterval {
+			r.Prune(ctx, now)
+			pruned = now
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample takes one sample of every symbol, stamped with the given time
+func (r *Recorder) Sample(ctx context.Context, now time.Time) {
+	for _, symbol := range r.symbols {
+		market, err := r.orderService.Market(ctx, symbol)
+		if err != nil {
+			r.logger.WarnContext(ctx, "failed to sample market", "symbol", symbol, "error", err)
+			continue
+		}
+
+		sample := &Sample{
+			Time:    now.UTC(),
+			Symbol:  market.Symbol,
+			Venues:  []VenuePrice{},
+			BestBid: market.BestBid.Price,
+			BestAsk: market.BestAsk.Price,
+		}
+		for _, venue := range market.Venues {
+			if venue.Error == "" {
+				sample.Venues = append(sample.Venues, VenuePrice{Exchange: venue.Exchange, Bid: venue.Bid, Ask: venue.Ask})
+			}
+		}
+
+		if err := r.store.Add(sample); err != nil {
+			r.logger.ErrorContext(ctx, "failed to store sample", "symbol", symbol, "error", err)
+		}
+	}
+}
+
+// Prune deletes the samples older than the retention as of now
+func (r *Recorder) Prune(ctx context.Context, now time.Time) {
+	if r.retention <= 0 {
+		return
+	}
+
+	deleted, err := r.store.Prune(now.Add(-r.retention))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to prune samples", "error", err)
+		return
+	}
+	if deleted > 0 {
+		r.logger.DebugContext(ctx, "pruned samples", "deleted", deleted, "retention", r.retention.String())
+	}
+}
@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/config"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+// backend answers the CLI's commands, either in process or through a server
+type backend interface {
+	Quote(ctx context.Context, side order.Side, symbol string, amount float64) (*Quote, error)
+	Market(ctx context.Context, symbol string) (*order.Market, error)
+	// Watch calls fn with every best bid/offer change of the symbols until
+	// ctx is done
+	Watch(ctx context.Context, symbols []string, fn func(bbo.Event) error) error
+}
+
+// Quote is a quote as printed by the CLI
+type Quote struct {
+	Side      order.Side `json:"side"`
+	Symbol    string     `json:"symbol"`
+	Amount    float64    `json:"amount"`
+	Price     float64    `json:"price"`
+	USDAmount float64    `json:"usdAmount"`
+	Exchanges []string   `json:"exchanges"`
+	// QuoteID is only set by servers that keep a quote history
+	QuoteID string `json:"quoteId,omitempty"`
+}
+
+// local runs an OrderService in process, configured like the server
+type local struct {
+	orderService *order.OrderService
+	cfg          *config.Config
+}
+
+// newLocal builds an OrderService from the config file at path, or from the
+// defaults and the environment when path is empty. Exchange requests are
+// logged to w when logLevel is set
+func newLocal(path, logLevel string, w io.Writer) (*local, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logging.Discard()
+	if logLevel != "" {
+		if logger, err = logging.New(w, logLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	exchanges, err := cfg.Exchanges(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	orderService := order.NewOrderService(exchanges...)
+	orderService.SetLogger(logger)
+	orderService.SetIntermediates(cfg.Routing.Intermediates...)
+	orderService.SetPriceImprovementBps(cfg.Routing.PriceImprovementBps)
+	orderService.SetSymbolAliases(cfg.SymbolAliases())
+	orderService.SetMaxAmount(cfg.Symbols.MaxAmount)
+	orderService.SetSymbolRulesTTL(cfg.Symbols.RulesTTL)
+
+	return &local{orderService: orderService, cfg: cfg}, nil
+}
+
+// Quote asks the OrderService for a quote
+func (l *local) Quote(ctx context.Context, side order.Side, symbol string, amount float64) (*Quote, error) {
+	quote, err := l.orderService.Quote(ctx, order.QuoteRequest{Side: side, Symbol: symbol, Amount: amount})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Quote{
+		Side:      quote.Side,
+		Symbol:    quote.Symbol,
+		Amount:    quote.Amount,
+		Price:     quote.Price,
+		USDAmount: quote.USDAmount,
+		Exchanges: quote.Exchanges,
+		QuoteID:   quote.ID,
+	}, nil
+}
+
+// Market asks the OrderService for the top of book
+func (l *local) Market(ctx context.Context, symbol string) (*order.Market, error) {
+	return l.orderService.Market(ctx, symbol)
+}
+
+// Watch polls the symbols at the configured stream interval
+func (l *local) Watch(ctx context.Context, symbols []string, fn func(bbo.Event) error) error {
+	poller := bbo.NewPoller(l.orderService, l.cfg.Stream.Interval)
+	events, unsubscribe := poller.Subscribe(symbols...)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go poller.Run(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// remote talks to a running server over its REST API
+type remote struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// newRemote creates a backend for the server at baseURL, sending apiKey when
+// it is set
+func newRemote(baseURL, apiKey string) *remote {
+	return &remote{baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, client: &http.Client{}}
+}
+
+// Quote calls /buy or /sell
+func (r *remote) Quote(ctx context.Context, side order.Side, symbol string, amount float64) (*Quote, error) {
+	query := url.Values{"symbol": {symbol}, "amount": {strconv.FormatFloat(amount, 'f', -1, 64)}}
+
+	var response struct {
+		Amount    float64  `json:"amount"`
+		Coin      string   `json:"coin"`
+		USDAmount float64  `json:"usdAmount"`
+		Exchange  []string `json:"exchange"`
+		QuoteID   string   `json:"quoteId"`
+	}
+	if err := r.get(ctx, "/"+string(side)+"?"+query.Encode(), &response); err != nil {
+		return nil, err
+	}
+
+	quote := &Quote{
+		Side:      side,
+		Symbol:    response.Coin,
+		Amount:    response.Amount,
+		USDAmount: response.USDAmount,
+		Exchanges: response.Exchange,
+		QuoteID:   response.QuoteID,
+	}
+	if quote.Amount > 0 {
+		quote.Price = quote.USDAmount / quote.Amount
+	}
+	return quote, nil
+}
+
+// Market calls /v1/markets/:symbol
+func (r *remote) Market(ctx context.Context, symbol string) (*order.Market, error) {
+	var market order.Market
+	if err := r.get(ctx, "/v1/markets/"+url.PathEscape(symbol), &market); err != nil {
+		return nil, err
+	}
+	return &market, nil
+}
+
+// Watch follows the /v1/stream/bbo Server-Sent Events stream
+func (r *remote) Watch(ctx context.Context, symbols []string, fn func(bbo.Event) error) error {
+	resp, err := r.do(ctx, "/v1/stream/bbo?"+url.Values{"symbols": {strings.Join(symbols, ",")}}.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+
+		var event bbo.Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	// The stream ends with an error when ctx is cancelled
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream closed by the server")
+}
+
+// get calls path and decodes the JSON response into v
+func (r *remote) get(ctx context.Context, path string, v any) error {
+	resp, err := r.do(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// do sends a GET request, turning error responses into errors carrying the
+// server's message and code
+func (r *remote) do(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if r.apiKey != "" {
+		req.Header.Set(auth.KeyHeader, r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var failure struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil || failure.Error == "" {
+		return nil, fmt.Errorf("server answered %s", resp.Status)
+	}
+	if failure.Code != "" {
+		return nil, fmt.Errorf("%s (%s)", failure.Error, failure.Code)
+	}
+	return nil, fmt.Errorf("%s", failure.Error)
+}
@@ -0,0 +1,181 @@
+// Package cli implements the triumph command line client. It quotes and
+// watches markets either in process, through an OrderService talking to the
+// exchanges directly, or against a running server
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+const usage = `Usage: triumph [flags] <command> [args]
+
+Commands:
+  quote buy|sell SYMBOL AMOUNT   quote buying or selling AMOUNT of SYMBOL
+  market SYMBOL                  show the top of book of SYMBOL on every exchange
+  venues status [SYMBOL]         show which exchanges answer for SYMBOL, BTC by default
+  watch SYMBOL...                follow best bid/offer changes until interrupted
+
+The exchanges are queried directly unless -server is set.
+
+Flags:
+`
+
+// usageError is returned for malformed command lines
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+// errVenuesDown is returned by venues status when an exchange didn't answer,
+// so scripts can check the exit code
+var errVenuesDown = errors.New("venues down")
+
+// Run runs the command line in args, without the program name, and returns
+// the exit code: 0 on success, 1 when the command failed and 2 for usage
+// errors
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("triumph", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", os.Getenv("CONFIG_FILE"), "path to the server's YAML or TOML config file, used to query the exchanges directly")
+	server := flags.String("server", os.Getenv("TRIUMPH_SERVER"), "URL of a running server to query instead of the exchanges, e.g. http://localhost:4000")
+	apiKey := flags.String("api-key", os.Getenv("TRIUMPH_API_KEY"), "API key sent to the server")
+	format := flags.String("o", "table", "output format: table, json or csv")
+	timeout := flags.Duration("timeout", 30*time.Second, "how long quote, market and venues commands may take")
+	verbose := flags.Bool("v", false, "log every exchange request to stderr when querying the exchanges directly")
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	out, err := newOutput(*format, stdout)
+	if err == nil {
+		err = checkArgs(flags.Args())
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "triumph: %v\n\n", err)
+		flags.Usage()
+		return 2
+	}
+
+	var b backend
+	if *server != "" {
+		b = newRemote(*server, *apiKey)
+	} else {
+		logLevel := ""
+		if *verbose {
+			logLevel = "debug"
+		}
+		if b, err = newLocal(*configPath, logLevel, stderr); err != nil {
+			fmt.Fprintf(stderr, "triumph: %v\n", err)
+			return 1
+		}
+	}
+
+	err = run(ctx, b, out, flags.Args(), *timeout)
+	if err != nil {
+		fmt.Fprintf(stderr, "triumph: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// checkArgs validates the command and its arguments before anything is
+// queried
+func checkArgs(args []string) error {
+	if len(args) == 0 {
+		return usageError{"missing command"}
+	}
+
+	switch args[0] {
+	case "quote":
+		if len(args) != 4 {
+			return usageError{"usage: quote buy|sell SYMBOL AMOUNT"}
+		}
+		if side := order.Side(args[1]); side != order.SideBuy && side != order.SideSell {
+			return usageError{fmt.Sprintf("invalid side %q, expected buy or sell", args[1])}
+		}
+		if _, err := strconv.ParseFloat(args[3], 64); err != nil {
+			return usageError{fmt.Sprintf("invalid amount %q", args[3])}
+		}
+	case "market":
+		if len(args) != 2 {
+			return usageError{"usage: market SYMBOL"}
+		}
+	case "venues":
+		if len(args) < 2 || len(args) > 3 || args[1] != "status" {
+			return usageError{"usage: venues status [SYMBOL]"}
+		}
+	case "watch":
+		if len(args) < 2 {
+			return usageError{"usage: watch SYMBOL..."}
+		}
+	default:
+		return usageError{fmt.Sprintf("unknown command %q", args[0])}
+	}
+	return nil
+}
+
+// run executes a command checked by checkArgs
+func run(ctx context.Context, b backend, out *output, args []string, timeout time.Duration) error {
+	if args[0] == "watch" {
+		return b.Watch(ctx, args[1:], out.event)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch args[0] {
+	case "quote":
+		amount, _ := strconv.ParseFloat(args[3], 64)
+		quote, err := b.Quote(ctx, order.Side(args[1]), args[2], amount)
+		if err != nil {
+			return err
+		}
+		return out.quote(quote)
+	case "market":
+		market, err := b.Market(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return out.market(market)
+	default:
+		symbol := "BTC"
+		if len(args) == 3 {
+			symbol = args[2]
+		}
+		market, err := b.Market(ctx, symbol)
+		if err != nil {
+			return err
+		}
+
+		venues := venueStatuses(market)
+		if err := out.venues(venues); err != nil {
+			return err
+		}
+		down := 0
+		for _, v := range venues {
+			if v.Status != statusUp {
+				down++
+			}
+		}
+		if down > 0 {
+			return fmt.Errorf("%w: %d of %d", errVenuesDown, down, len(venues))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// Venue statuses reported by venues status
+const (
+	statusUp   = "up"
+	statusDown = "down"
+)
+
+// VenueStatus is whether a venue answered for a symbol
+type VenueStatus struct {
+	Exchange string `json:"exchange"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// output prints command results as aligned tables, JSON or CSV
+type output struct {
+	format string
+	w      io.Writer
+	// watching is set once the header of a watch has been printed
+	watching bool
+}
+
+// newOutput returns an output printing in format to w
+func newOutput(format string, w io.Writer) (*output, error) {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return &output{format: format, w: w}, nil
+	default:
+		return nil, usageError{fmt.Sprintf("invalid output format %q, expected table, json or csv", format)}
+	}
+}
+
+// quote prints a quote
+func (o *output) quote(q *Quote) error {
+	header := []string{"SIDE", "SYMBOL", "AMOUNT", "PRICE", "USD AMOUNT", "EXCHANGES", "QUOTE ID"}
+	row := []string{string(q.Side), q.Symbol, number(q.Amount), number(q.Price), number(q.USDAmount), strings.Join(q.Exchanges, ","), q.QuoteID}
+	return o.write(q, header, [][]string{row})
+}
+
+// market prints the venues of a market followed by the best bid and ask
+// across them
+func (o *output) market(m *order.Market) error {
+	header := []string{"EXCHANGE", "BID", "ASK", "MID", "SPREAD BPS", "ERROR"}
+	rows := [][]string{}
+	for _, v := range m.Venues {
+		rows = append(rows, []string{v.Exchange, number(v.Bid), number(v.Ask), number(v.Mid), number(v.SpreadBps), v.Error})
+	}
+	rows = append(rows, []string{"best", number(m.BestBid.Price), number(m.BestAsk.Price), number(m.MidPrice), number(m.SpreadBps), ""})
+	return o.write(m, header, rows)
+}
+
+// venues prints venue statuses
+func (o *output) venues(venues []VenueStatus) error {
+	header := []string{"EXCHANGE", "STATUS", "ERROR"}
+	rows := [][]string{}
+	for _, v := range venues {
+		rows = append(rows, []string{v.Exchange, v.Status, v.Error})
+	}
+	return o.write(venues, header, rows)
+}
+
+// event prints a best bid/offer change. Tables and CSV get a header before
+// the first event, JSON is printed one event per line
+func (o *output) event(e bbo.Event) error {
+	if o.format == formatJSON {
+		return json.NewEncoder(o.w).Encode(e)
+	}
+
+	header := []string{"TIME", "SYMBOL", "SEQUENCE", "BID", "BID EXCHANGES", "ASK", "ASK EXCHANGES", "SPREAD BPS"}
+	row := []string{
+		e.Time.Format(time.RFC3339), e.Symbol, strconv.FormatUint(e.Sequence, 10),
+		number(e.BestBid.Price), strings.Join(e.BestBid.Exchanges, ","),
+		number(e.BestAsk.Price), strings.Join(e.BestAsk.Exchanges, ","),
+		number(e.SpreadBps),
+	}
+
+	rows := [][]string{row}
+	if o.watching {
+		header = nil
+	}
+	o.watching = true
+	return o.rows(header, rows)
+}
+
+// write prints v as indented JSON, or the rows under header
+func (o *output) write(v any, header []string, rows [][]string) error {
+	if o.format == formatJSON {
+		encoder := json.NewEncoder(o.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	return o.rows(header, rows)
+}
+
+// rows prints the rows as a table or CSV, preceded by header unless it is nil
+func (o *output) rows(header []string, rows [][]string) error {
+	if header != nil {
+		rows = append([][]string{header}, rows...)
+	}
+
+	if o.format == formatCSV {
+		writer := csv.NewWriter(o.w)
+		writer.WriteAll(rows)
+		return writer.Error()
+	}
+
+	// Columns are padded to a minimum width so the rows of a watch, which
+	// are flushed one at a time, mostly line up
+	writer := tabwriter.NewWriter(o.w, 12, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// venueStatuses reports every venue of a market as up or down
+func venueStatuses(m *order.Market) []VenueStatus {
+	venues := make([]VenueStatus, 0, len(m.Venues))
+	for _, v := range m.Venues {
+		status := VenueStatus{Exchange: v.Exchange, Status: statusUp}
+		if v.Error != "" {
+			status.Status, status.Error = statusDown, v.Error
+		}
+		venues = append(venues, status)
+	}
+	return venues
+}
+
+// number formats a price or amount, leaving unknown values blank
+func number(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
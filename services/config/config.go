@@ -0,0 +1,728 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the effective configuration of the service. It is built from
+// the defaults, then the config file, then the environment
+type Config struct {
+	// Listen is the address the HTTP server listens on
+	Listen   string `yaml:"listen" toml:"listen" env:"LISTEN_ADDR"`
+	LogLevel string `yaml:"logLevel" toml:"logLevel" env:"LOG_LEVEL"`
+	// GRPCListen is the address the gRPC server listens on
+	GRPCListen string `yaml:"grpcListen" toml:"grpcListen" env:"GRPC_LISTEN_ADDR"`
+	// ShutdownTimeout is how long in-flight requests are given to finish
+	// once the server is asked to stop
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" toml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+
+	// EnabledVenues are the exchanges quotes are routed to
+	EnabledVenues []string `yaml:"enabledVenues" toml:"enabledVenues" env:"VENUES"`
+	// Venues are keyed by exchange name, their settings can be overridden
+	// with <NAME>_ prefixed environment variables, e.g. KRAKEN_API_KEY
+	Venues map[string]Venue `yaml:"venues" toml:"venues"`
+
+	Readiness Readiness `yaml:"readiness" toml:"readiness"`
+	Routing   Routing   `yaml:"routing" toml:"routing"`
+	Cache     Cache     `yaml:"cache" toml:"cache"`
+	Symbols   Symbols   `yaml:"symbols" toml:"symbols"`
+	RateLimit RateLimit `yaml:"rateLimit" toml:"rateLimit"`
+	Features  Features  `yaml:"features" toml:"features"`
+	Arbitrage Arbitrage `yaml:"arbitrage" toml:"arbitrage"`
+	Candles   Candles   `yaml:"candles" toml:"candles"`
+	Stream    Stream    `yaml:"stream" toml:"stream"`
+	Alerts    Alerts    `yaml:"alerts" toml:"alerts"`
+	Storage   Storage   `yaml:"storage" toml:"storage"`
+	Tracing   Tracing   `yaml:"tracing" toml:"tracing"`
+}
+
+// Venue configures one exchange
+type Venue struct {
+	// Adapter is the registered exchange adapter serving the venue, the venue
+	// name when unset
+	Adapter string `yaml:"adapter,omitempty" toml:"adapter,omitempty" env:"ADAPTER"`
+	// MarketURL is the base URL of the public market data API
+	MarketURL string `yaml:"marketURL" toml:"marketURL" env:"MARKET_URL"`
+	// TradeURL is the base URL of the private trading API
+	TradeURL string        `yaml:"tradeURL" toml:"tradeURL" env:"TRADE_URL"`
+	TakerFee float64       `yaml:"takerFee" toml:"takerFee" env:"TAKER_FEE"`
+	Timeout  time.Duration `yaml:"timeout" toml:"timeout" env:"TIMEOUT"`
+	// APIKey and APISecret enable order placement, they are better left out
+	// of the file and set through the environment
+	APIKey    string `yaml:"apiKey" toml:"apiKey" env:"API_KEY" secret:"true"`
+	APISecret string `yaml:"apiSecret" toml:"apiSecret" env:"API_SECRET" secret:"true"`
+	// Live submits orders on venues without a sandbox, they are only
+	// validated otherwise
+	Live bool `yaml:"live" toml:"live" env:"LIVE"`
+	// REST describes the order book endpoint of venues served by the generic
+	// rest adapter
+	REST *exchange.RESTConfig `yaml:"rest,omitempty" toml:"rest,omitempty"`
+}
+
+// AdapterName returns the adapter serving the venue called name
+func (v Venue) AdapterName(name string) string {
+	if v.Adapter != "" {
+		return v.Adapter
+	}
+	return name
+}
+
+// Readiness configures when the service reports itself ready for traffic
+type Readiness struct {
+	// MinVenues is how many venues must have answered successfully
+	MinVenues int `yaml:"minVenues" toml:"minVenues" env:"READINESS_MIN_VENUES"`
+	// ProbeSymbol is requested from the venues until enough have answered
+	ProbeSymbol string `yaml:"probeSymbol" toml:"probeSymbol" env:"READINESS_PROBE_SYMBOL"`
+	// Window is how long a venue's last successful answer counts
+	Window time.Duration `yaml:"window" toml:"window" env:"READINESS_WINDOW"`
+	// ProbeInterval is the least time between two probes, checks in between
+	// report the venues known to answer
+	ProbeInterval time.Duration `yaml:"probeInterval" toml:"probeInterval" env:"READINESS_PROBE_INTERVAL"`
+}
+
+// Routing configures how quotes pick a venue
+type Routing struct {
+	// Intermediates price symbols a venue doesn't list against USD
+	Intermediates []string `yaml:"intermediates" toml:"intermediates" env:"ROUTING_INTERMEDIATES"`
+	// PriceImprovementBps is how much better another venue must be to win
+	// over a client's preferred venue
+	PriceImprovementBps float64 `yaml:"priceImprovementBps" toml:"priceImprovementBps" env:"ROUTING_PRICE_IMPROVEMENT_BPS"`
+}
+
+// Cache configures how long venue prices are reused
+type Cache struct {
+	// PriceTTL is how long a venue's prices are reused, zero disables caching
+	PriceTTL time.Duration `yaml:"priceTTL" toml:"priceTTL" env:"CACHE_PRICE_TTL"`
+}
+
+// Symbols configures how request symbols and amounts are validated
+type Symbols struct {
+	// Aliases map alternative tickers to the canonical one, as ALIAS=SYMBOL
+	Aliases []string `yaml:"aliases" toml:"aliases" env:"SYMBOL_ALIASES"`
+	// MaxAmount rejects larger amounts whatever the venues allow, zero
+	// disables the cap
+	MaxAmount float64 `yaml:"maxAmount" toml:"maxAmount" env:"SYMBOL_MAX_AMOUNT"`
+	// RulesTTL is how long a venue's size limits for a symbol are reused
+	RulesTTL time.Duration `yaml:"rulesTTL" toml:"rulesTTL" env:"SYMBOL_RULES_TTL"`
+}
+
+// RateLimit limits the requests each client IP can make per window
+type RateLimit struct {
+	// Requests allowed per window, zero disables rate limiting
+	Requests int           `yaml:"requests" toml:"requests" env:"RATE_LIMIT_REQUESTS"`
+	Window   time.Duration `yaml:"window" toml:"window" env:"RATE_LIMIT_WINDOW"`
+}
+
+// Features toggles optional parts of the service
+type Features struct {
+	PaperTrading bool `yaml:"paperTrading" toml:"paperTrading" env:"FEATURE_PAPER_TRADING"`
+	Arbitrage    bool `yaml:"arbitrage" toml:"arbitrage" env:"FEATURE_ARBITRAGE"`
+	Candles      bool `yaml:"candles" toml:"candles" env:"FEATURE_CANDLES"`
+	QuoteHistory bool `yaml:"quoteHistory" toml:"quoteHistory" env:"FEATURE_QUOTE_HISTORY"`
+	Metrics      bool `yaml:"metrics" toml:"metrics" env:"FEATURE_METRICS"`
+	Stream       bool `yaml:"stream" toml:"stream" env:"FEATURE_STREAM"`
+	Alerts       bool `yaml:"alerts" toml:"alerts" env:"FEATURE_ALERTS"`
+	GRPC         bool `yaml:"grpc" toml:"grpc" env:"FEATURE_GRPC"`
+	// Auth requires an API key on every API endpoint
+	Auth bool `yaml:"auth" toml:"auth" env:"FEATURE_AUTH"`
+}
+
+// Arbitrage configures the arbitrage scanner
+type Arbitrage struct {
+	Symbols    []string      `yaml:"symbols" toml:"symbols" env:"ARBITRAGE_SYMBOLS"`
+	Interval   time.Duration `yaml:"interval" toml:"interval" env:"ARBITRAGE_INTERVAL"`
+	MinEdgeBps float64       `yaml:"minEdgeBps" toml:"minEdgeBps" env:"ARBITRAGE_MIN_EDGE_BPS"`
+}
+
+// Candles configures the price recorder
+type Candles struct {
+	Symbols  []string      `yaml:"symbols" toml:"symbols" env:"CANDLE_SYMBOLS"`
+	Interval time.Duration `yaml:"interval" toml:"interval" env:"CANDLE_INTERVAL"`
+	// Retention is how long raw samples are kept once rolled up into
+	// candles, zero keeps them forever
+	Retention time.Duration `yaml:"retention" toml:"retention" env:"CANDLE_RETENTION"`
+}
+
+// Stream configures the shared poller behind the live price streams
+type Stream struct {
+	// Interval is how often the subscribed symbols are polled
+	Interval time.Duration `yaml:"interval" toml:"interval" env:"STREAM_INTERVAL"`
+	// MaxSymbols caps how many symbols a single stream can subscribe to
+	MaxSymbols int `yaml:"maxSymbols" toml:"maxSymbols" env:"STREAM_MAX_SYMBOLS"`
+}
+
+// Alerts configures the alert engine and its webhook deliveries
+type Alerts struct {
+	// Interval is how often every alert is evaluated
+	Interval time.Duration `yaml:"interval" toml:"interval" env:"ALERTS_INTERVAL"`
+	// MaxAttempts is how many times a webhook delivery is attempted before
+	// it is moved to the dead letters
+	MaxAttempts int `yaml:"maxAttempts" toml:"maxAttempts" env:"ALERTS_MAX_ATTEMPTS"`
+	// Backoff is the wait after the first failed attempt, it doubles after
+	// every following one
+	Backoff time.Duration `yaml:"backoff" toml:"backoff" env:"ALERTS_BACKOFF"`
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"ALERTS_TIMEOUT"`
+	// AllowPrivateNetworks lets webhooks be delivered to loopback, private
+	// and link local addresses
+	AllowPrivateNetworks bool `yaml:"allowPrivateNetworks" toml:"allowPrivateNetworks" env:"ALERTS_ALLOW_PRIVATE_NETWORKS"`
+}
+
+// Storage configures where the local databases are kept
+type Storage struct {
+	Quotes  string `yaml:"quotes" toml:"quotes" env:"STORAGE_QUOTES"`
+	Orders  string `yaml:"orders" toml:"orders" env:"STORAGE_ORDERS"`
+	Ledger  string `yaml:"ledger" toml:"ledger" env:"STORAGE_LEDGER"`
+	Candles string `yaml:"candles" toml:"candles" env:"STORAGE_CANDLES"`
+	Keys    string `yaml:"keys" toml:"keys" env:"STORAGE_KEYS"`
+	Alerts  string `yaml:"alerts" toml:"alerts" env:"STORAGE_ALERTS"`
+	// Venues keeps the venue changes made through the admin API across
+	// restarts, they only last until the next restart when empty
+	Venues string `yaml:"venues" toml:"venues" env:"STORAGE_VENUES"`
+}
+
+// Tracing configures where traces are exported
+type Tracing struct {
+	// Exporter is "otlp", "stdout", "file" or empty to disable tracing
+	Exporter string `yaml:"exporter" toml:"exporter" env:"TRACE_EXPORTER"`
+	File     string `yaml:"file" toml:"file" env:"TRACE_FILE"`
+}
+
+// The accepted trace exporters
+var traceExporters = []string{"", "otlp", "stdout", "file"}
+
+// defaultVenueTimeout applies to venues added by the config file that don't
+// set a timeout
+const defaultVenueTimeout = 10 * time.Second
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	symbols := []string{"BTC", "ETH", "SOL", "DOGE"}
+
+	return &Config{
+		Listen:          ":4000",
+		GRPCListen:      ":4001",
+		LogLevel:        "info",
+		ShutdownTimeout: 15 * time.Second,
+		EnabledVenues:   []string{"coinbase", "kraken"},
+		Venues: map[string]Venue{
+			"coinbase": {
+				MarketURL: "https://api.exchange.coinbase.com",
+				TradeURL:  "https://api-sandbox.coinbase.com",
+				TakerFee:  0.006,
+				Timeout:   10 * time.Second,
+			},
+			"kraken": {
+				MarketURL: "https://api.kraken.com",
+				TradeURL:  "https://api.kraken.com",
+				TakerFee:  0.004,
+				Timeout:   10 * time.Second,
+			},
+		},
+		Readiness: Readiness{MinVenues: 1, ProbeSymbol: "BTC", Window: 5 * time.Minute, ProbeInterval: 10 * time.Second},
+		Routing: Routing{
+			Intermediates:       []string{"USDT", "USDC", "BTC", "ETH"},
+			PriceImprovementBps: 5,
+		},
+		Symbols: Symbols{
+			Aliases:   []string{"XBT=BTC", "XDG=DOGE"},
+			MaxAmount: 1e9,
+			RulesTTL:  time.Hour,
+		},
+		RateLimit: RateLimit{Window: time.Minute},
+		Features: Features{
+			PaperTrading: true,
+			Arbitrage:    true,
+			Candles:      true,
+			QuoteHistory: true,
+			Metrics:      true,
+			Stream:       true,
+			Alerts:       true,
+			GRPC:         true,
+			Auth:         true,
+		},
+		Arbitrage: Arbitrage{Symbols: symbols, Interval: 10 * time.Second},
+		Candles:   Candles{Symbols: symbols, Interval: 5 * time.Second, Retention: 7 * 24 * time.Hour},
+		Stream:    Stream{Interval: time.Second, MaxSymbols: 20},
+		Alerts: Alerts{
+			Interval:    5 * time.Second,
+			MaxAttempts: 5,
+			Backoff:     time.Second,
+			Timeout:     5 * time.Second,
+		},
+		Storage: Storage{
+			Quotes:  "quotes.db",
+			Orders:  "orders.db",
+			Ledger:  "ledger.db",
+			Candles: "candles.db",
+			Keys:    "keys.db",
+			Alerts:  "alerts.db",
+			Venues:  "venues.db",
+		},
+		Tracing: Tracing{File: "traces.json"},
+	}
+}
+
+// Load builds the configuration from the defaults, the file at path when it
+// is set and the environment, then validates it. Files ending in .toml are
+// read as TOML, anything else as YAML
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readFile merges the config file at path over cfg, unknown keys are errors
+func (cfg *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config: %w", err)
+	}
+	defer f.Close()
+
+	// Decode into the venues separately so a venue in the file inherits the
+	// defaults of the fields it leaves out
+	defaults := cfg.Venues
+	cfg.Venues = nil
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		meta, err := toml.NewDecoder(f).Decode(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to read config %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("failed to read config %s: unknown key %s", path, undecoded[0])
+		}
+	} else {
+		decoder := yaml.NewDecoder(f)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read config %s: %w", path, err)
+		}
+	}
+
+	venues := defaults
+	for name, venue := range cfg.Venues {
+		base, ok := defaults[name]
+		if !ok {
+			base = Venue{Timeout: defaultVenueTimeout}
+		}
+		venues[name] = mergeVenue(base, venue)
+	}
+	cfg.Venues = venues
+	return nil
+}
+
+// mergeVenue fills the unset fields of venue from its defaults, Live is taken
+// as written
+func mergeVenue(defaults, venue Venue) Venue {
+	if venue.MarketURL == "" {
+		venue.MarketURL = defaults.MarketURL
+	}
+	if venue.TradeURL == "" {
+		venue.TradeURL = defaults.TradeURL
+	}
+	if venue.TakerFee == 0 {
+		venue.TakerFee = defaults.TakerFee
+	}
+	if venue.Timeout == 0 {
+		venue.Timeout = defaults.Timeout
+	}
+	if venue.APIKey == "" {
+		venue.APIKey = defaults.APIKey
+	}
+	if venue.APISecret == "" {
+		venue.APISecret = defaults.APISecret
+	}
+	if venue.Adapter == "" {
+		venue.Adapter = defaults.Adapter
+	}
+	if venue.REST == nil {
+		venue.REST = defaults.REST
+	}
+	return venue
+}
+
+// applyEnv overrides cfg with the environment variables named by the env
+// tags, venue settings are prefixed with the upper case venue name
+func (cfg *Config) applyEnv(lookup func(string) (string, bool)) error {
+	if err := setFromEnv(reflect.ValueOf(cfg).Elem(), "", lookup); err != nil {
+		return err
+	}
+
+	for name, venue := range cfg.Venues {
+		prefix := strings.ToUpper(name) + "_"
+		if err := setFromEnv(reflect.ValueOf(&venue).Elem(), prefix, lookup); err != nil {
+			return err
+		}
+		cfg.Venues[name] = venue
+	}
+	return nil
+}
+
+// setFromEnv sets the tagged fields of the struct v, recursing into nested
+// structs
+func setFromEnv(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := setFromEnv(value, prefix, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		raw, ok := lookup(prefix + name)
+		if !ok {
+			continue
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", prefix+name, err)
+		}
+	}
+	return nil
+}
+
+// setValue parses raw into v according to its type
+func setValue(v reflect.Value, raw string) error {
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		list := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Validate checks the configuration, reporting every problem found
+func (cfg *Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if cfg.Listen == "" {
+		fail("listen", "must be set")
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		fail("logLevel", "must be one of debug, info, warn or error, got %q", cfg.LogLevel)
+	}
+
+	adapters := exchange.Adapters()
+	for _, name := range cfg.VenueNames() {
+		venue := cfg.Venues[name]
+		adapter := venue.AdapterName(name)
+		if !contains(adapters, adapter) {
+			fail("venues."+name+".adapter", "unknown adapter %q, expected one of %s", adapter, strings.Join(adapters, ", "))
+			continue
+		}
+		if adapter == "rest" && venue.REST == nil {
+			fail("venues."+name+".rest", "must be set for the rest adapter")
+		} else if adapter == "rest" {
+			if err := venue.REST.Validate(); err != nil {
+				fail("venues."+name+".rest", "%v", err)
+			}
+		}
+	}
+
+	if len(cfg.EnabledVenues) == 0 {
+		fail("enabledVenues", "at least one venue must be enabled")
+	}
+	for _, name := range cfg.EnabledVenues {
+		venue, ok := cfg.Venues[name]
+		field := "venues." + name
+		if !ok {
+			fail("enabledVenues", "unknown exchange %q, expected one of %s", name, strings.Join(cfg.VenueNames(), ", "))
+			continue
+		}
+
+		if err := validURL(venue.MarketURL); err != nil {
+			fail(field+".marketURL", "%v", err)
+		}
+		// Only venues that trade need a trading API
+		if venue.TradeURL != "" || venue.APIKey != "" {
+			if err := validURL(venue.TradeURL); err != nil {
+				fail(field+".tradeURL", "%v", err)
+			}
+		}
+		if venue.TakerFee < 0 || venue.TakerFee >= 1 {
+			fail(field+".takerFee", "must be a fraction between 0 and 1, got %g", venue.TakerFee)
+		}
+		if venue.Timeout <= 0 {
+			fail(field+".timeout", "must be positive")
+		}
+		if (venue.APIKey == "") != (venue.APISecret == "") {
+			fail(field, "apiKey and apiSecret must be set together")
+		}
+	}
+
+	if cfg.Features.GRPC && cfg.GRPCListen == "" {
+		fail("grpcListen", "must be set")
+	} else if cfg.Features.GRPC && cfg.GRPCListen == cfg.Listen {
+		fail("grpcListen", "must differ from listen")
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		fail("shutdownTimeout", "must be positive")
+	}
+	if cfg.Readiness.MinVenues < 0 || cfg.Readiness.MinVenues > len(cfg.EnabledVenues) {
+		fail("readiness.minVenues", "must be between 0 and the %d enabled venues", len(cfg.EnabledVenues))
+	}
+	if cfg.Readiness.MinVenues > 0 && cfg.Readiness.ProbeSymbol == "" {
+		fail("readiness.probeSymbol", "must be set")
+	}
+	if cfg.Readiness.Window <= 0 {
+		fail("readiness.window", "must be positive")
+	}
+	if cfg.Readiness.ProbeInterval < 0 {
+		fail("readiness.probeInterval", "must not be negative")
+	}
+	if cfg.Routing.PriceImprovementBps < 0 {
+		fail("routing.priceImprovementBps", "must not be negative")
+	}
+	if cfg.Cache.PriceTTL < 0 {
+		fail("cache.priceTTL", "must not be negative")
+	}
+	for _, alias := range cfg.Symbols.Aliases {
+		if from, to, ok := strings.Cut(alias, "="); !ok || strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			fail("symbols.aliases", "must be written as ALIAS=SYMBOL, got %q", alias)
+		}
+	}
+	if cfg.Symbols.MaxAmount < 0 {
+		fail("symbols.maxAmount", "must not be negative")
+	}
+	if cfg.Symbols.RulesTTL <= 0 {
+		fail("symbols.rulesTTL", "must be positive")
+	}
+	if cfg.RateLimit.Requests < 0 {
+		fail("rateLimit.requests", "must not be negative")
+	}
+	if cfg.RateLimit.Requests > 0 && cfg.RateLimit.Window <= 0 {
+		fail("rateLimit.window", "must be positive")
+	}
+	if cfg.Features.Arbitrage && cfg.Arbitrage.Interval <= 0 {
+		fail("arbitrage.interval", "must be positive")
+	}
+	if cfg.Features.Candles && cfg.Candles.Interval <= 0 {
+		fail("candles.interval", "must be positive")
+	}
+	if cfg.Candles.Retention < 0 {
+		fail("candles.retention", "must not be negative")
+	}
+	// The best bid/offer poller backs gRPC watches as well as the streams
+	if cfg.Features.Stream || cfg.Features.GRPC {
+		if cfg.Stream.Interval <= 0 {
+			fail("stream.interval", "must be positive")
+		}
+		if cfg.Stream.MaxSymbols <= 0 {
+			fail("stream.maxSymbols", "must be positive")
+		}
+	}
+	if cfg.Features.Alerts {
+		if cfg.Alerts.Interval <= 0 {
+			fail("alerts.interval", "must be positive")
+		}
+		if cfg.Alerts.MaxAttempts <= 0 {
+			fail("alerts.maxAttempts", "must be positive")
+		}
+		if cfg.Alerts.Backoff < 0 {
+			fail("alerts.backoff", "must not be negative")
+		}
+		if cfg.Alerts.Timeout <= 0 {
+			fail("alerts.timeout", "must be positive")
+		}
+	}
+	if cfg.Features.PaperTrading && !cfg.Features.QuoteHistory {
+		fail("features.paperTrading", "needs features.quoteHistory to look up accepted quotes")
+	}
+	if !contains(traceExporters, cfg.Tracing.Exporter) {
+		fail("tracing.exporter", "must be one of otlp, stdout or file, got %q", cfg.Tracing.Exporter)
+	}
+	if cfg.Tracing.Exporter == "file" && cfg.Tracing.File == "" {
+		fail("tracing.file", "must be set for the file exporter")
+	}
+
+	for field, path := range map[string]string{
+		"storage.quotes":  cfg.Storage.Quotes,
+		"storage.orders":  cfg.Storage.Orders,
+		"storage.ledger":  cfg.Storage.Ledger,
+		"storage.candles": cfg.Storage.Candles,
+		"storage.keys":    cfg.Storage.Keys,
+		"storage.alerts":  cfg.Storage.Alerts,
+	} {
+		if path == "" {
+			fail(field, "must be set")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+}
+
+// VenueNames returns the configured venue names in order
+func (cfg *Config) VenueNames() []string {
+	names := make([]string, 0, len(cfg.Venues))
+	for name := range cfg.Venues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Exchanges creates the enabled venues with their registered adapters, in
+// the order they are enabled
+func (cfg *Config) Exchanges(logger *slog.Logger) ([]exchange.Exchange, error) {
+	exchanges := make([]exchange.Exchange, 0, len(cfg.EnabledVenues))
+	for _, name := range cfg.EnabledVenues {
+		ex, err := cfg.Venues[name].Exchange(name, logger)
+		if err != nil {
+			return nil, err
+		}
+		exchanges = append(exchanges, ex)
+	}
+	return exchanges, nil
+}
+
+// Exchange creates the venue called name with its registered adapter
+func (v Venue) Exchange(name string, logger *slog.Logger) (exchange.Exchange, error) {
+	return exchange.New(v.AdapterName(name), exchange.Settings{
+		Name:      name,
+		MarketURL: v.MarketURL,
+		Timeout:   v.Timeout,
+		TakerFee:  v.TakerFee,
+		Logger:    logger,
+		REST:      v.REST,
+	})
+}
+
+// SymbolAliases returns the configured aliases as a map from alias to symbol
+func (cfg *Config) SymbolAliases() map[string]string {
+	aliases := make(map[string]string, len(cfg.Symbols.Aliases))
+	for _, alias := range cfg.Symbols.Aliases {
+		if from, to, ok := strings.Cut(alias, "="); ok {
+			aliases[strings.TrimSpace(from)] = strings.TrimSpace(to)
+		}
+	}
+	return aliases
+}
+
+// Redacted returns a copy of the configuration with its secrets replaced,
+// safe to print
+func (cfg *Config) Redacted() *Config {
+	redacted := *cfg
+	redacted.Venues = map[string]Venue{}
+	for name, venue := range cfg.Venues {
+		redactSecrets(reflect.ValueOf(&venue).Elem())
+		redacted.Venues[name] = venue
+	}
+	return &redacted
+}
+
+// redactSecrets replaces the set string fields of v tagged as secret
+func redactSecrets(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if field.Tag.Get("secret") == "true" && value.String() != "" {
+			value.SetString("REDACTED")
+		}
+	}
+}
+
+// Write writes the configuration as YAML
+func (cfg *Config) Write(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// validURL checks that raw is an absolute http(s) URL
+func validURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q", raw)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http or https URL, got %q", raw)
+	}
+	return nil
+}
+
+// contains reports whether list contains value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package exchange
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Level is a single price level of an order book
+type Level struct {
+	Price float64 `json:"price"`
+	Size  float64 `json:"size"`
+}
+
+// OrderBook holds both sides of an order book, each sorted best price first
+type OrderBook struct {
+	Bids []Level `json:"bids"`
+	Asks []Level `json:"asks"`
+}
+
+// parseLevels converts raw [price, size, ...] entries into levels, keeping at
+// most depth of them. Both Coinbase and Kraken encode price and size as strings
+func parseLevels(raw [][]any, depth int) ([]Level, error) {
+	if depth > 0 && len(raw) > depth {
+		raw = raw[:depth]
+	}
+
+	levels := make([]Level, 0, len(raw))
+	for _, entry := range raw {
+		if len(entry) < 2 {
+			return nil, fmt.Errorf("malformed level %v", entry)
+		}
+
+		price, err := parseNumber(entry[0])
+		if err != nil {
+			return nil, fmt.Errorf("price: %w", err)
+		}
+		size, err := parseNumber(entry[1])
+		if err != nil {
+			return nil, fmt.Errorf("size: %w", err)
+		}
+
+		levels = append(levels, Level{Price: price, Size: size})
+	}
+
+	return levels, nil
+}
+
+// parseNumber accepts either a JSON string or a JSON number
+func parseNumber(v any) (float64, error) {
+	switch n := v.(type) {
+	case string:
+		return strconv.ParseFloat(n, 64)
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("unexpected type %T", v)
+	}
+}
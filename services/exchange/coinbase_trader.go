@@ -0,0 +1,318 @@
+package exchange
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coinbase Advanced Trade API hosts. The sandbox answers with static
+// responses and never touches funds
+const (
+	CoinbaseSandboxURL = "https://api-sandbox.coinbase.com"
+	CoinbaseLiveURL    = "https://api.coinbase.com"
+)
+
+// CoinbaseTrader implements the Trader interface for Coinbase Advanced Trade.
+// Requests are signed with a JWT when the secret is a PEM encoded EC private
+// key, as issued for CDP API keys, and with a legacy HMAC signature otherwise
+type CoinbaseTrader struct {
+	// Name is the venue name reported by GetName, defaults to coinbase
+	Name string
+	// BaseURL defaults to the sandbox
+	BaseURL string
+	// KeyName is the API key name, or the legacy API key
+	KeyName string
+	// Secret is the PEM encoded EC private key, or the legacy API secret
+	Secret string
+	Client *http.Client
+	// Logger receives a debug record of every request, nothing is logged
+	// when unset
+	Logger *slog.Logger
+}
+
+// GetName returns the name of the exchange
+func (c *CoinbaseTrader) GetName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return "coinbase"
+}
+
+// PlaceOrder places an order on Coinbase
+func (c *CoinbaseTrader) PlaceOrder(ctx context.Context, req OrderRequest) (*OrderStatus, error) {
+	configuration := map[string]any{}
+	switch req.Type {
+	case OrderTypeMarket:
+		configuration["market_market_ioc"] = map[string]string{"base_size": formatAmount(req.Amount)}
+	case OrderTypeLimit:
+		configuration["limit_limit_gtc"] = map[string]string{
+			"base_size":   formatAmount(req.Amount),
+			"limit_price": formatAmount(req.LimitPrice),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported order type %q", req.Type)
+	}
+
+	body := map[string]any{
+		"client_order_id":     req.ClientOrderID,
+		"product_id":          req.Symbol + "-USD",
+		"side":                strings.ToUpper(req.Side),
+		"order_configuration": configuration,
+	}
+
+	var response struct {
+		Success         bool `json:"success"`
+		SuccessResponse struct {
+			OrderID       string `json:"order_id"`
+			ClientOrderID string `json:"client_order_id"`
+		} `json:"success_response"`
+		ErrorResponse struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		} `json:"error_response"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v3/brokerage/orders", body, &response); err != nil {
+		return nil, err
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("%w: coinbase: %s %s", ErrOrderRejected, response.ErrorResponse.Error, response.ErrorResponse.Message)
+	}
+
+	return &OrderStatus{
+		ID:            response.SuccessResponse.OrderID,
+		ClientOrderID: response.SuccessResponse.ClientOrderID,
+		Exchange:      c.GetName(),
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		State:         OrderStatePending,
+	}, nil
+}
+
+// CancelOrder cancels an order on Coinbase
+func (c *CoinbaseTrader) CancelOrder(ctx context.Context, id string) error {
+	var response struct {
+		Results []struct {
+			Success       bool   `json:"success"`
+			FailureReason string `json:"failure_reason"`
+		} `json:"results"`
+	}
+	body := map[string]any{"order_ids": []string{id}}
+	if err := c.do(ctx, http.MethodPost, "/api/v3/brokerage/orders/batch_cancel", body, &response); err != nil {
+		return err
+	}
+
+	if len(response.Results) == 0 {
+		return fmt.Errorf("coinbase returned no cancel result for %s", id)
+	}
+	if !response.Results[0].Success {
+		return fmt.Errorf("%w: coinbase: %s", ErrOrderRejected, response.Results[0].FailureReason)
+	}
+	return nil
+}
+
+// GetOrder returns the status of an order on Coinbase
+func (c *CoinbaseTrader) GetOrder(ctx context.Context, id string) (*OrderStatus, error) {
+	var response struct {
+		Order struct {
+			OrderID            string `json:"order_id"`
+			ClientOrderID      string `json:"client_order_id"`
+			ProductID          string `json:"product_id"`
+			Side               string `json:"side"`
+			Status             string `json:"status"`
+			FilledSize         string `json:"filled_size"`
+			AverageFilledPrice string `json:"average_filled_price"`
+		} `json:"order"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v3/brokerage/orders/historical/"+url.PathEscape(id), nil, &response); err != nil {
+		return nil, err
+	}
+
+	order := response.Order
+	status := &OrderStatus{
+		ID:            order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Exchange:      c.GetName(),
+		Symbol:        strings.TrimSuffix(order.ProductID, "-USD"),
+		Side:          strings.ToLower(order.Side),
+		State:         coinbaseState(order.Status),
+	}
+	status.FilledAmount, _ = strconv.ParseFloat(order.FilledSize, 64)
+	status.AvgPrice, _ = strconv.ParseFloat(order.AverageFilledPrice, 64)
+
+	return status, nil
+}
+
+// coinbaseState normalizes a Coinbase order status
+func coinbaseState(status string) OrderState {
+	switch status {
+	case "OPEN", "QUEUED", "CANCEL_QUEUED":
+		return OrderStateOpen
+	case "FILLED":
+		return OrderStateFilled
+	case "CANCELLED":
+		return OrderStateCancelled
+	case "EXPIRED":
+		return OrderStateExpired
+	case "FAILED":
+		return OrderStateRejected
+	default:
+		return OrderStatePending
+	}
+}
+
+// do sends a signed request to Coinbase and decodes the JSON response
+func (c *CoinbaseTrader) do(ctx context.Context, method, path string, body any, v any) error {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = CoinbaseSandboxURL
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.sign(req, path, payload); err != nil {
+		return fmt.Errorf("failed to sign coinbase request: %w", err)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := send(client, req, c.GetName(), c.Logger)
+	if err != nil {
+		return fmt.Errorf("failed to send request to coinbase: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var failure struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return fmt.Errorf("coinbase %s %s failed with %d: %s %s", method, path, resp.StatusCode, failure.Error, failure.Message)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode coinbase response: %w", err)
+	}
+	return nil
+}
+
+// sign adds the authentication headers to a request
+func (c *CoinbaseTrader) sign(req *http.Request, path string, payload []byte) error {
+	if key, err := parseECKey(c.Secret); err == nil {
+		token, err := coinbaseJWT(key, c.KeyName, req.Method+" "+req.URL.Host+path, time.Now())
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+
+	// Legacy keys sign timestamp + method + path + body with HMAC-SHA256
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(c.Secret))
+	mac.Write([]byte(timestamp + req.Method + path + string(payload)))
+
+	req.Header.Set("CB-ACCESS-KEY", c.KeyName)
+	req.Header.Set("CB-ACCESS-SIGN", hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("CB-ACCESS-TIMESTAMP", timestamp)
+	return nil
+}
+
+// coinbaseJWT builds the short lived ES256 JWT Coinbase expects for a request,
+// uri is the method, host and path of the request
+func coinbaseJWT(key *ecdsa.PrivateKey, keyName, uri string, now time.Time) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg":   "ES256",
+		"typ":   "JWT",
+		"kid":   keyName,
+		"nonce": hex.EncodeToString(nonce),
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss": "cdp",
+		"sub": keyName,
+		"nbf": now.Unix(),
+		"exp": now.Add(2 * time.Minute).Unix(),
+		"uri": uri,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	// ES256 signatures are the fixed width concatenation of r and s
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseECKey parses a PEM encoded EC private key in either SEC 1 or PKCS #8
+// form. Secrets stored in env vars often have their newlines escaped
+func parseECKey(secret string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(secret, `\n`, "\n")))
+	if block == nil {
+		return nil, fmt.Errorf("secret is not PEM encoded")
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("secret is not an EC private key")
+	}
+	return ecKey, nil
+}
+
+// formatAmount formats an amount without exponent or trailing zeros
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
@@ -2,102 +2,209 @@ package exchange
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"encoding/json"
-	"time"
-	"strconv"
+	"net/url"
 	"strings"
+	"time"
 )
 
 // Exchange defines an interface for interacting with cryptocurrency exchanges
 type Exchange interface {
 	// GetPrices retrives the buy and sell prices from an exchange
-	// It takes a context and symbol returning a buy price, sell price, error 
+	// It takes a context and symbol returning a buy price, sell price, error
 	GetPrices(ctx context.Context, symbol string) (float64, float64, error)
 	// Get the name of the current exchange
 	GetName() string
 }
 
+// BookProvider is implemented by exchanges that can return order book depth
+// in addition to the top of book prices
+type BookProvider interface {
+	// GetOrderBook retrieves up to depth levels per side of the order book
+	GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error)
+}
+
+// PairBookProvider is implemented by exchanges that can return the order book
+// of pairs quoted in currencies other than USD
+type PairBookProvider interface {
+	// GetPairOrderBook retrieves up to depth levels per side of the base/quote
+	// order book, it returns ErrPairNotFound when the venue doesn't list the pair
+	GetPairOrderBook(ctx context.Context, base, quote string, depth int) (*OrderBook, error)
+}
+
+// ErrPairNotFound is returned when an exchange doesn't list a pair
+var ErrPairNotFound = errors.New("pair not found")
+
+// FeeProvider is implemented by exchanges that charge a taker fee
+type FeeProvider interface {
+	// GetTakerFee returns the taker fee as a fraction of the traded notional
+	GetTakerFee() float64
+}
+
+// Default taker fees for the lowest volume tier of each venue
+const (
+	coinbaseTakerFee = 0.006
+	krakenTakerFee   = 0.004
+)
+
+// Public market data APIs of each venue
+const (
+	CoinbaseMarketURL = "https://api.exchange.coinbase.com"
+	KrakenMarketURL   = "https://api.kraken.com"
+)
+
+// DefaultTimeout bounds every exchange API request unless overridden
+const DefaultTimeout = 10 * time.Second
+
 // CoinbaseExchange implements the Exchange interface for Coinbase
-type CoinbaseExchange struct{}
+type CoinbaseExchange struct {
+	// Name is the venue name reported by GetName, defaults to coinbase
+	Name string
+	// BaseURL defaults to CoinbaseMarketURL
+	BaseURL string
+	// Timeout defaults to DefaultTimeout
+	Timeout time.Duration
+	// TakerFee overrides the default taker fee when set
+	TakerFee float64
+	// Logger receives a debug record of every request, nothing is logged
+	// when unset
+	Logger *slog.Logger
+}
 
 // GetPrices retrieves the price for a given symbol from Coinbase
 func (c *CoinbaseExchange) GetPrices(ctx context.Context, symbol string) (float64, float64, error) {
+	book, err := c.GetOrderBook(ctx, symbol, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Bid represents the price someone else is willing to pay, this is our sell value
+	// Ask represents the price someone else is asking for, this is our buy value
+	return book.Asks[0].Price, book.Bids[0].Price, nil
+}
+
+// GetOrderBook retrieves the order book for a given symbol from Coinbase
+func (c *CoinbaseExchange) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	return c.GetPairOrderBook(ctx, symbol, "USD", depth)
+}
+
+// GetPairOrderBook retrieves the order book for a given pair from Coinbase
+func (c *CoinbaseExchange) GetPairOrderBook(ctx context.Context, base, quote string, depth int) (*OrderBook, error) {
 	// Construct the Coinbase API URL
-	// url := fmt.Sprintf("https://api.coinbase.com/v2/prices/%s/spot", symbol)
-	url := fmt.Sprintf("https://api.exchange.coinbase.com/products/%s-USD/book", symbol)
+	// Level 1 only returns the best bid and ask, level 2 returns the top 50 levels
+	level := 1
+	if depth > 1 {
+		level = 2
+	}
+	// Symbols come from clients, escape them so they can't change the path
+	endpoint := fmt.Sprintf("%s/products/%s/book?level=%d", orDefault(c.BaseURL, CoinbaseMarketURL), url.PathEscape(base+"-"+quote), level)
 
 	// Create a new HTTP client with a timeout
-	client := http.Client{Timeout: 10 * time.Second}
+	client := http.Client{Timeout: timeout(c.Timeout)}
 
 	// Send the request to the Coinbase API
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := send(&client, req, c.GetName(), c.Logger)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get price from coinbase: %w", err)
+		return nil, fmt.Errorf("failed to get price from coinbase: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Coinbase answers unknown products with a 404
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("coinbase %s-%s: %w", base, quote, ErrPairNotFound)
+	}
+
 	// Decode the JSON response
-	// The only fields we're intrested in are the two prices
+	// The only fields we're intrested in are the two sides of the book
 	var coinbaseResponse struct {
 		Bids [][]any `json:"bids"`
 		Asks [][]any `json:"asks"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&coinbaseResponse); err != nil {
-		return 0, 0, fmt.Errorf("failed to decode coinbase response: %w", err)
+		return nil, fmt.Errorf("failed to decode coinbase response: %w", err)
 	}
 
 	// Make sure bid and ask data are present
 	if len(coinbaseResponse.Bids) == 0 || len(coinbaseResponse.Bids[0]) == 0 {
-		return 0, 0, fmt.Errorf("Failed to find bid prices in coinbase response")
+		return nil, fmt.Errorf("Failed to find bid prices in coinbase response")
 	}
 	if len(coinbaseResponse.Asks) == 0 || len(coinbaseResponse.Asks[0]) == 0 {
-		return 0, 0, fmt.Errorf("Failed to find ask prices in coinbase response")
+		return nil, fmt.Errorf("Failed to find ask prices in coinbase response")
 	}
 
-	// Convert the prices to float64:
-	// Bid represents the price someone else is willing to pay, this is our sell value
-	bid, err := strconv.ParseFloat(coinbaseResponse.Bids[0][0].(string), 64)
+	bids, err := parseLevels(coinbaseResponse.Bids, depth)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to parse bid price string from coinbase response: %w", err)
+		return nil, fmt.Errorf("failed to parse bid from coinbase response: %w", err)
 	}
-
-	// Ask represents the price someone else is asking for, this is our buy value
-	ask, err := strconv.ParseFloat(coinbaseResponse.Asks[0][0].(string), 64)
+	asks, err := parseLevels(coinbaseResponse.Asks, depth)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to parse ask price string from coinbase response: %w", err)
+		return nil, fmt.Errorf("failed to parse ask from coinbase response: %w", err)
 	}
 
-	return ask, bid, nil
+	return &OrderBook{Bids: bids, Asks: asks}, nil
 }
 
 // KrakenExchange implements the Exchange interface for Kraken
-type KrakenExchange struct{}
+type KrakenExchange struct {
+	// Name is the venue name reported by GetName, defaults to kraken
+	Name string
+	// BaseURL defaults to KrakenMarketURL
+	BaseURL string
+	// Timeout defaults to DefaultTimeout
+	Timeout time.Duration
+	// TakerFee overrides the default taker fee when set
+	TakerFee float64
+	// Logger receives a debug record of every request, nothing is logged
+	// when unset
+	Logger *slog.Logger
+}
 
 // GetPrices retrieves the price for a given symbol from Kraken
 func (k *KrakenExchange) GetPrices(ctx context.Context, symbol string) (float64, float64, error) {
+	book, err := k.GetOrderBook(ctx, symbol, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return book.Asks[0].Price, book.Bids[0].Price, nil
+}
+
+// GetOrderBook retrieves the order book for a given symbol from Kraken
+func (k *KrakenExchange) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	return k.GetPairOrderBook(ctx, symbol, "USD", depth)
+}
+
+// GetPairOrderBook retrieves the order book for a given pair from Kraken
+func (k *KrakenExchange) GetPairOrderBook(ctx context.Context, base, quote string, depth int) (*OrderBook, error) {
+	if depth < 1 {
+		depth = 1
+	}
+
 	// Construct the Kraken API URL
-	url := fmt.Sprintf("https://api.kraken.com/0/public/Depth?pair=%sUSD&count=1", symbol)
+	endpoint := fmt.Sprintf("%s/0/public/Depth?pair=%s&count=%d", orDefault(k.BaseURL, KrakenMarketURL), url.QueryEscape(base+quote), depth)
 
 	// Create a new HTTP client with a timeout
-	client := http.Client{Timeout: 10 * time.Second}
+	client := http.Client{Timeout: timeout(k.Timeout)}
 
 	// Send the request to the Kraken API
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := send(&client, req, k.GetName(), k.Logger)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get price from kraken: %w", err)
+		return nil, fmt.Errorf("failed to get price from kraken: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -110,57 +217,98 @@ func (k *KrakenExchange) GetPrices(ctx context.Context, symbol string) (float64,
 	var krakenResponse struct {
 		Error []string `json:"error"`
 		/*
-		At first it looked like the kraken response was following
-		pattern X{symbol}Z{currency}, however when calling with
-		symbol BTC the response was XXBTZUSD, since we can't rely
-		on knowing the key we will just use a map and grab the
-		first element
+			At first it looked like the kraken response was following
+			pattern X{symbol}Z{currency}, however when calling with
+			symbol BTC the response was XXBTZUSD, since we can't rely
+			on knowing the key we will just use a map and grab the
+			first element
 		*/
 		Result map[string]ResultBlock `json:"result"`
 	}
 
 	// Decode the JSON response
 	if err := json.NewDecoder(resp.Body).Decode(&krakenResponse); err != nil {
-		return 0, 0, fmt.Errorf("failed to decode kraken response: %w", err)
+		return nil, fmt.Errorf("failed to decode kraken response: %w", err)
 	}
 
+	// Kraken answers unknown pairs with an EQuery:Unknown asset pair error
+	for _, e := range krakenResponse.Error {
+		if strings.Contains(e, "Unknown asset pair") {
+			return nil, fmt.Errorf("kraken %s%s: %w", base, quote, ErrPairNotFound)
+		}
+	}
 	if len(krakenResponse.Error) != 0 {
-		return 0, 0, fmt.Errorf("Kraken price fetch failed with errors: %s", strings.Join(krakenResponse.Error, ", "))
+		return nil, fmt.Errorf("Kraken price fetch failed with errors: %s", strings.Join(krakenResponse.Error, ", "))
 	}
 
-	var ask, bid float64
-
 	for _, aResult := range krakenResponse.Result {
 		// Make sure bid and ask data are present
 		if len(aResult.Bids) == 0 || len(aResult.Bids[0]) == 0 {
-			return 0, 0, fmt.Errorf("Failed to find bid prices in kraken response")
+			return nil, fmt.Errorf("Failed to find bid prices in kraken response")
 		}
 		if len(aResult.Asks) == 0 || len(aResult.Asks[0]) == 0 {
-			return 0, 0, fmt.Errorf("Failed to find ask prices in kraken response")
+			return nil, fmt.Errorf("Failed to find ask prices in kraken response")
 		}
 
-		bid, err = strconv.ParseFloat(aResult.Bids[0][0].(string), 64)
+		bids, err := parseLevels(aResult.Bids, depth)
 		if err != nil {
-			return 0, 0, fmt.Errorf("failed to parse bid price string from kraken response: %w", err)
+			return nil, fmt.Errorf("failed to parse bid from kraken response: %w", err)
 		}
-
-		ask, err = strconv.ParseFloat(aResult.Asks[0][0].(string), 64)
+		asks, err := parseLevels(aResult.Asks, depth)
 		if err != nil {
-			return 0, 0, fmt.Errorf("failed to parse ask price string from kraken response: %w", err)
+			return nil, fmt.Errorf("failed to parse ask from kraken response: %w", err)
 		}
-	}
 
+		return &OrderBook{Bids: bids, Asks: asks}, nil
+	}
 
-	return ask, bid, nil
+	return nil, fmt.Errorf("Failed to find a result in kraken response")
 }
 
 // GetName returns the name of the exchange
 func (c *CoinbaseExchange) GetName() string {
+	if c.Name != "" {
+		return c.Name
+	}
 	return "coinbase"
 }
 
 // GetName returns the name of the exchange
 func (k *KrakenExchange) GetName() string {
+	if k.Name != "" {
+		return k.Name
+	}
 	return "kraken"
 }
 
+// GetTakerFee returns the taker fee charged by Coinbase
+func (c *CoinbaseExchange) GetTakerFee() float64 {
+	if c.TakerFee > 0 {
+		return c.TakerFee
+	}
+	return coinbaseTakerFee
+}
+
+// GetTakerFee returns the taker fee charged by Kraken
+func (k *KrakenExchange) GetTakerFee() float64 {
+	if k.TakerFee > 0 {
+		return k.TakerFee
+	}
+	return krakenTakerFee
+}
+
+// orDefault returns url without a trailing slash, or fallback when it is empty
+func orDefault(url, fallback string) string {
+	if url == "" {
+		return fallback
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+// timeout returns d, or DefaultTimeout when it is unset
+func timeout(d time.Duration) time.Duration {
+	if d <= 0 {
+		return DefaultTimeout
+	}
+	return d
+}
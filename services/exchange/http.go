@@ -0,0 +1,94 @@
+package exchange
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// send performs an exchange API request as a client span carrying the venue,
+// the response status and the number of bytes sent and received. The span
+// ends and the request is logged at debug level when the response body is
+// closed
+func send(client *http.Client, req *http.Request, venue string, logger *slog.Logger) (*http.Response, error) {
+	start := time.Now()
+	tracer := otel.Tracer("github.com/SmMistry/triumph-project/services/exchange")
+	_, span := tracer.Start(req.Context(), req.Method+" "+venue,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("venue", venue),
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.String()),
+			semconv.HTTPRequestBodySize(int(max(req.ContentLength, 0))),
+		),
+	)
+
+	logger = logging.OrDiscard(logger).With(
+		slog.String("exchange", venue),
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+	)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		logger.DebugContext(req.Context(), "exchange request failed",
+			slog.Any("error", err),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	resp.Body = &tracedBody{
+		ReadCloser: resp.Body,
+		req:        req,
+		status:     resp.StatusCode,
+		start:      start,
+		span:       span,
+		logger:     logger,
+	}
+	return resp, nil
+}
+
+// tracedBody counts the bytes read from a response body, ending its span and
+// logging the request when closed
+type tracedBody struct {
+	io.ReadCloser
+	req    *http.Request
+	status int
+	start  time.Time
+	span   trace.Span
+	logger *slog.Logger
+	read   int
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	b.span.SetAttributes(semconv.HTTPResponseBodySize(b.read))
+	b.span.End()
+	b.logger.DebugContext(b.req.Context(), "exchange request",
+		slog.Int("status", b.status),
+		slog.Int("bytes", b.read),
+		slog.Float64("duration_ms", float64(time.Since(b.start).Microseconds())/1000),
+	)
+	return b.ReadCloser.Close()
+}
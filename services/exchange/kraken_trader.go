@@ -0,0 +1,244 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KrakenLiveURL is the Kraken REST API host. Kraken has no spot sandbox, so
+// KrakenTrader only validates orders unless Live is set
+const KrakenLiveURL = "https://api.kraken.com"
+
+// KrakenTrader implements the Trader interface for Kraken. Requests are signed
+// with API-Sign, an HMAC-SHA512 of the path and a SHA-256 of the nonce and
+// form body, keyed with the base64 decoded secret
+type KrakenTrader struct {
+	// Name is the venue name reported by GetName, defaults to kraken
+	Name    string
+	BaseURL string
+	Key     string
+	// Secret is the base64 encoded private key
+	Secret string
+	// Live submits orders, when unset orders are only validated by Kraken
+	Live   bool
+	Client *http.Client
+	// Logger receives a debug record of every request, nothing is logged
+	// when unset
+	Logger *slog.Logger
+
+	mu        sync.Mutex
+	lastNonce int64
+}
+
+// GetName returns the name of the exchange
+func (k *KrakenTrader) GetName() string {
+	if k.Name != "" {
+		return k.Name
+	}
+	return "kraken"
+}
+
+// PlaceOrder places an order on Kraken
+func (k *KrakenTrader) PlaceOrder(ctx context.Context, req OrderRequest) (*OrderStatus, error) {
+	form := url.Values{}
+	form.Set("pair", req.Symbol+"USD")
+	form.Set("type", strings.ToLower(req.Side))
+	form.Set("ordertype", string(req.Type))
+	form.Set("volume", formatAmount(req.Amount))
+	if req.Type == OrderTypeLimit {
+		form.Set("price", formatAmount(req.LimitPrice))
+	}
+	if req.ClientOrderID != "" {
+		form.Set("cl_ord_id", req.ClientOrderID)
+	}
+	if !k.Live {
+		form.Set("validate", "true")
+	}
+
+	var result struct {
+		TxID []string `json:"txid"`
+	}
+	if err := k.do(ctx, "/0/private/AddOrder", form, &result); err != nil {
+		return nil, err
+	}
+
+	status := &OrderStatus{
+		ClientOrderID: req.ClientOrderID,
+		Exchange:      k.GetName(),
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		State:         OrderStatePending,
+	}
+	if !k.Live {
+		status.State = OrderStateValidated
+	}
+	if len(result.TxID) > 0 {
+		status.ID = result.TxID[0]
+	}
+	return status, nil
+}
+
+// CancelOrder cancels an order on Kraken
+func (k *KrakenTrader) CancelOrder(ctx context.Context, id string) error {
+	form := url.Values{}
+	form.Set("txid", id)
+
+	var result struct {
+		Count int `json:"count"`
+	}
+	if err := k.do(ctx, "/0/private/CancelOrder", form, &result); err != nil {
+		return err
+	}
+	if result.Count == 0 {
+		return fmt.Errorf("%w: kraken cancelled no orders for %s", ErrOrderRejected, id)
+	}
+	return nil
+}
+
+// GetOrder returns the status of an order on Kraken
+func (k *KrakenTrader) GetOrder(ctx context.Context, id string) (*OrderStatus, error) {
+	form := url.Values{}
+	form.Set("txid", id)
+
+	var result map[string]struct {
+		Status  string `json:"status"`
+		ClOrdID string `json:"cl_ord_id"`
+		VolExec string `json:"vol_exec"`
+		Price   string `json:"price"`
+		Descr   struct {
+			Pair string `json:"pair"`
+			Type string `json:"type"`
+		} `json:"descr"`
+	}
+	if err := k.do(ctx, "/0/private/QueryOrders", form, &result); err != nil {
+		return nil, err
+	}
+
+	order, ok := result[id]
+	if !ok {
+		return nil, fmt.Errorf("kraken returned no order %s", id)
+	}
+
+	status := &OrderStatus{
+		ID:            id,
+		ClientOrderID: order.ClOrdID,
+		Exchange:      k.GetName(),
+		Symbol:        strings.TrimSuffix(order.Descr.Pair, "USD"),
+		Side:          order.Descr.Type,
+		State:         krakenState(order.Status),
+	}
+	status.FilledAmount, _ = strconv.ParseFloat(order.VolExec, 64)
+	status.AvgPrice, _ = strconv.ParseFloat(order.Price, 64)
+
+	return status, nil
+}
+
+// krakenState normalizes a Kraken order status. Kraken reports filled orders
+// as closed
+func krakenState(status string) OrderState {
+	switch status {
+	case "open":
+		return OrderStateOpen
+	case "closed":
+		return OrderStateFilled
+	case "canceled":
+		return OrderStateCancelled
+	case "expired":
+		return OrderStateExpired
+	default:
+		return OrderStatePending
+	}
+}
+
+// do sends a signed request to Kraken and decodes the result of the response
+func (k *KrakenTrader) do(ctx context.Context, path string, form url.Values, v any) error {
+	baseURL := k.BaseURL
+	if baseURL == "" {
+		baseURL = KrakenLiveURL
+	}
+
+	nonce := k.nonce()
+	form.Set("nonce", nonce)
+	body := form.Encode()
+
+	signature, err := krakenSignature(k.Secret, path, nonce, body)
+	if err != nil {
+		return fmt.Errorf("failed to sign kraken request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+path, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("API-Key", k.Key)
+	req.Header.Set("API-Sign", signature)
+
+	client := k.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := send(client, req, k.GetName(), k.Logger)
+	if err != nil {
+		return fmt.Errorf("failed to send request to kraken: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode kraken response: %w", err)
+	}
+	if len(response.Error) != 0 {
+		return fmt.Errorf("%w: kraken: %s", ErrOrderRejected, strings.Join(response.Error, ", "))
+	}
+
+	if err := json.Unmarshal(response.Result, v); err != nil {
+		return fmt.Errorf("failed to decode kraken result: %w", err)
+	}
+	return nil
+}
+
+// nonce returns a strictly increasing nonce, Kraken rejects any nonce that
+// isn't larger than the previous one for the key
+func (k *KrakenTrader) nonce() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	nonce := time.Now().UnixMicro()
+	if nonce <= k.lastNonce {
+		nonce = k.lastNonce + 1
+	}
+	k.lastNonce = nonce
+
+	return strconv.FormatInt(nonce, 10)
+}
+
+// krakenSignature computes API-Sign for a request
+func krakenSignature(secret, path, nonce, body string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("secret is not base64: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(nonce + body))
+	mac := hmac.New(sha512.New, key)
+	mac.Write([]byte(path))
+	mac.Write(digest[:])
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
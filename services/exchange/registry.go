@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownAdapter is returned when no adapter is registered under a name
+var ErrUnknownAdapter = errors.New("unknown adapter")
+
+// Settings configure an exchange created through the registry
+type Settings struct {
+	// Name is the venue name, reported by the exchange's GetName
+	Name string
+	// MarketURL is the base URL of the public market data API, adapters fall
+	// back to their own default when it is empty
+	MarketURL string
+	Timeout   time.Duration
+	TakerFee  float64
+	// Logger receives a debug record of every request, nothing is logged
+	// when unset
+	Logger *slog.Logger
+	// REST configures the generic rest adapter, other adapters ignore it
+	REST *RESTConfig
+}
+
+// Factory creates an exchange from its settings
+type Factory func(settings Settings) (Exchange, error)
+
+// registry holds the factories of every adapter by name
+var registry = struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}{factories: map[string]Factory{}}
+
+// The adapters of this package
+func init() {
+	Register("coinbase", func(s Settings) (Exchange, error) {
+		return &CoinbaseExchange{Name: s.Name, BaseURL: s.MarketURL, Timeout: s.Timeout, TakerFee: s.TakerFee, Logger: s.Logger}, nil
+	})
+	Register("kraken", func(s Settings) (Exchange, error) {
+		return &KrakenExchange{Name: s.Name, BaseURL: s.MarketURL, Timeout: s.Timeout, TakerFee: s.TakerFee, Logger: s.Logger}, nil
+	})
+	Register("rest", func(s Settings) (Exchange, error) {
+		return NewRESTExchange(s)
+	})
+}
+
+// Register makes an adapter available under name. Adapters register from the
+// init function of their package, Register panics when name is taken
+func Register(name string, factory Factory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if factory == nil {
+		panic("exchange: Register factory is nil")
+	}
+	if _, ok := registry.factories[name]; ok {
+		panic("exchange: Register called twice for adapter " + name)
+	}
+	registry.factories[name] = factory
+}
+
+// New creates an exchange with the named adapter
+func New(adapter string, settings Settings) (Exchange, error) {
+	registry.mu.RLock()
+	factory, ok := registry.factories[adapter]
+	registry.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownAdapter, adapter)
+	}
+	ex, err := factory(settings)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", settings.Name, err)
+	}
+	return ex, nil
+}
+
+// Adapters returns the names of the registered adapters, sorted
+func Adapters() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,370 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RESTConfig declares how the generic rest adapter reads the order book of a
+// venue with a public REST/JSON API
+type RESTConfig struct {
+	// BookURL is the order book endpoint, absolute or relative to the market
+	// URL. {symbol} and {depth} are replaced, e.g. /api/v2/order_book/{symbol}
+	BookURL string `yaml:"bookURL" toml:"bookURL"`
+	// Symbol formats {symbol} from {base} and {quote}, "{base}{quote}" when
+	// unset
+	Symbol string `yaml:"symbol,omitempty" toml:"symbol,omitempty"`
+	// LowerCase writes symbols in lower case
+	LowerCase bool `yaml:"lowerCase,omitempty" toml:"lowerCase,omitempty"`
+	// Bids and Asks select the arrays of levels in the response, e.g.
+	// "data.bids" or "result.*.asks", where * matches the first entry
+	Bids string `yaml:"bids" toml:"bids"`
+	Asks string `yaml:"asks" toml:"asks"`
+	// Price and Size select the price and size of a level, "[0]" and "[1]"
+	// when unset to read [price, size] arrays, or field names for objects
+	Price string `yaml:"price,omitempty" toml:"price,omitempty"`
+	Size  string `yaml:"size,omitempty" toml:"size,omitempty"`
+}
+
+// Validate checks that the config can be used to read an order book
+func (c *RESTConfig) Validate() error {
+	_, err := c.compile()
+	return err
+}
+
+// restSelectors are the compiled selectors of a RESTConfig
+type restSelectors struct {
+	bids, asks, price, size selector
+}
+
+// compile parses the selectors of the config
+func (c *RESTConfig) compile() (*restSelectors, error) {
+	if c.BookURL == "" {
+		return nil, errors.New("bookURL must be set")
+	}
+	if c.Symbol != "" && !strings.Contains(c.Symbol, "{base}") {
+		return nil, fmt.Errorf("symbol must contain {base}, got %q", c.Symbol)
+	}
+
+	s := &restSelectors{}
+	for _, field := range []struct {
+		name, raw, fallback string
+		dst                 *selector
+	}{
+		{"bids", c.Bids, "", &s.bids},
+		{"asks", c.Asks, "", &s.asks},
+		{"price", c.Price, "[0]", &s.price},
+		{"size", c.Size, "[1]", &s.size},
+	} {
+		raw := field.raw
+		if raw == "" {
+			raw = field.fallback
+		}
+		if raw == "" {
+			return nil, fmt.Errorf("%s must be set", field.name)
+		}
+		sel, err := parseSelector(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+		*field.dst = sel
+	}
+	return s, nil
+}
+
+// RESTExchange reads order books from any venue described by a RESTConfig
+type RESTExchange struct {
+	name      string
+	baseURL   string
+	timeout   time.Duration
+	takerFee  float64
+	logger    *slog.Logger
+	config    RESTConfig
+	selectors *restSelectors
+}
+
+// NewRESTExchange creates a generic rest exchange, settings.REST must be set
+func NewRESTExchange(settings Settings) (*RESTExchange, error) {
+	if settings.REST == nil {
+		return nil, errors.New("the rest adapter needs a rest config")
+	}
+	selectors, err := settings.REST.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RESTExchange{
+		name:      settings.Name,
+		baseURL:   strings.TrimSuffix(settings.MarketURL, "/"),
+		timeout:   settings.Timeout,
+		takerFee:  settings.TakerFee,
+		logger:    settings.Logger,
+		config:    *settings.REST,
+		selectors: selectors,
+	}, nil
+}
+
+// GetName returns the venue name the exchange was configured with
+func (r *RESTExchange) GetName() string {
+	return r.name
+}
+
+// GetTakerFee returns the configured taker fee
+func (r *RESTExchange) GetTakerFee() float64 {
+	return r.takerFee
+}
+
+// GetPrices retrieves the best ask and bid for a symbol
+func (r *RESTExchange) GetPrices(ctx context.Context, symbol string) (float64, float64, error) {
+	book, err := r.GetOrderBook(ctx, symbol, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	return book.Asks[0].Price, book.Bids[0].Price, nil
+}
+
+// GetOrderBook retrieves the order book of a symbol against USD
+func (r *RESTExchange) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	return r.GetPairOrderBook(ctx, symbol, "USD", depth)
+}
+
+// GetPairOrderBook retrieves the order book of a pair
+func (r *RESTExchange) GetPairOrderBook(ctx context.Context, base, quote string, depth int) (*OrderBook, error) {
+	if depth < 1 {
+		depth = 1
+	}
+
+	client := http.Client{Timeout: timeout(r.timeout)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.bookURL(base, quote, depth), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := send(&client, req, r.name, r.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price from %s: %w", r.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s %s-%s: %w", r.name, base, quote, ErrPairNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s order book request failed with status %d", r.name, resp.StatusCode)
+	}
+
+	// Numbers are kept as written so prices don't lose precision
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	var body any
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", r.name, err)
+	}
+
+	bids, err := r.levels(body, r.selectors.bids, depth, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bids from %s response: %w", r.name, err)
+	}
+	asks, err := r.levels(body, r.selectors.asks, depth, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asks from %s response: %w", r.name, err)
+	}
+
+	return &OrderBook{Bids: bids, Asks: asks}, nil
+}
+
+// bookURL expands the BookURL template for a pair
+func (r *RESTExchange) bookURL(base, quote string, depth int) string {
+	format := r.config.Symbol
+	if format == "" {
+		format = "{base}{quote}"
+	}
+	symbol := strings.NewReplacer("{base}", base, "{quote}", quote).Replace(format)
+	if r.config.LowerCase {
+		symbol = strings.ToLower(symbol)
+	}
+
+	// Symbols come from clients, escape them so they can't change the URL
+	endpoint := strings.NewReplacer(
+		"{symbol}", url.PathEscape(symbol),
+		"{depth}", strconv.Itoa(depth),
+	).Replace(r.config.BookURL)
+	if strings.HasPrefix(endpoint, "/") {
+		endpoint = r.baseURL + endpoint
+	}
+	return endpoint
+}
+
+// levels reads one side of the book, sorted best price first and cut to
+// depth
+func (r *RESTExchange) levels(body any, sel selector, depth int, descending bool) ([]Level, error) {
+	value, err := sel.find(body)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an array", sel)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%s is empty", sel)
+	}
+
+	levels := make([]Level, 0, len(raw))
+	for _, entry := range raw {
+		price, err := r.selectors.price.number(entry)
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.selectors.size.number(entry)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, Level{Price: price, Size: size})
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price > levels[j].Price
+		}
+		return levels[i].Price < levels[j].Price
+	})
+	if len(levels) > depth {
+		levels = levels[:depth]
+	}
+	return levels, nil
+}
+
+// selector is a parsed JSONPath-style selector such as $.result.*.bids[0]
+type selector struct {
+	raw   string
+	steps []step
+}
+
+// step is one key, array index or * wildcard of a selector
+type step struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseSelector parses dot separated keys with optional [n] indexes, an
+// optional leading $ and * wildcards matching the first entry of an object
+// or array
+func parseSelector(raw string) (selector, error) {
+	path := strings.TrimPrefix(strings.TrimPrefix(raw, "$"), ".")
+	sel := selector{raw: raw}
+
+	for _, segment := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(segment, "[")
+		if rest != "" {
+			rest = "[" + rest
+		}
+		switch {
+		case key == "*":
+			sel.steps = append(sel.steps, step{wildcard: true})
+		case key != "":
+			sel.steps = append(sel.steps, step{key: key})
+		case rest == "":
+			return selector{}, fmt.Errorf("empty key in %q", raw)
+		}
+
+		for rest != "" {
+			inside, after, ok := strings.Cut(rest[1:], "]")
+			if !strings.HasPrefix(rest, "[") || !ok {
+				return selector{}, fmt.Errorf("malformed index in %q", raw)
+			}
+			if inside == "*" {
+				sel.steps = append(sel.steps, step{wildcard: true})
+			} else {
+				index, err := strconv.Atoi(inside)
+				if err != nil || index < 0 {
+					return selector{}, fmt.Errorf("invalid index %q in %q", inside, raw)
+				}
+				sel.steps = append(sel.steps, step{index: index, isIndex: true})
+			}
+			rest = after
+		}
+	}
+
+	return sel, nil
+}
+
+// String returns the selector as written
+func (s selector) String() string {
+	return s.raw
+}
+
+// find returns the value the selector points to in v
+func (s selector) find(v any) (any, error) {
+	for _, st := range s.steps {
+		switch node := v.(type) {
+		case map[string]any:
+			if st.isIndex {
+				return nil, fmt.Errorf("%s: expected an array, got an object", s)
+			}
+			if st.wildcard {
+				keys := make([]string, 0, len(node))
+				for key := range node {
+					keys = append(keys, key)
+				}
+				if len(keys) == 0 {
+					return nil, fmt.Errorf("%s: object is empty", s)
+				}
+				sort.Strings(keys)
+				v = node[keys[0]]
+				continue
+			}
+			value, ok := node[st.key]
+			if !ok {
+				return nil, fmt.Errorf("%s: key %q not found", s, st.key)
+			}
+			v = value
+		case []any:
+			if !st.isIndex && !st.wildcard {
+				return nil, fmt.Errorf("%s: expected an object, got an array", s)
+			}
+			if st.index >= len(node) {
+				return nil, fmt.Errorf("%s: index %d out of range", s, st.index)
+			}
+			v = node[st.index]
+		default:
+			return nil, fmt.Errorf("%s: nothing to select in %v", s, v)
+		}
+	}
+	return v, nil
+}
+
+// number returns the number the selector points to in v, venues write them
+// either as JSON numbers or strings
+func (s selector) number(v any) (float64, error) {
+	value, err := s.find(v)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n := value.(type) {
+	case json.Number:
+		return n.Float64()
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", s, err)
+		}
+		return f, nil
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%s: %v is not a number", s, value)
+	}
+}
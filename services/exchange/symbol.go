@@ -0,0 +1,136 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SymbolInfoProvider is implemented by exchanges that publish the order size
+// rules of the pairs they list
+type SymbolInfoProvider interface {
+	// GetSymbolInfo returns the size rules of symbol against USD, it returns
+	// ErrPairNotFound when the venue doesn't list the pair
+	GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error)
+}
+
+// SymbolInfo holds the order size rules of a pair, in units of the base
+// asset. Zero means the venue doesn't publish that rule
+type SymbolInfo struct {
+	MinSize float64 `json:"minSize"`
+	MaxSize float64 `json:"maxSize"`
+	// LotSize is the increment order sizes must be a multiple of
+	LotSize float64 `json:"lotSize"`
+}
+
+// GetSymbolInfo retrieves the size rules of a symbol from Coinbase
+func (c *CoinbaseExchange) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	endpoint := fmt.Sprintf("%s/products/%s", orDefault(c.BaseURL, CoinbaseMarketURL), url.PathEscape(symbol+"-USD"))
+
+	client := http.Client{Timeout: timeout(c.Timeout)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := send(&client, req, c.GetName(), c.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product from coinbase: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("coinbase %s-USD: %w", symbol, ErrPairNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coinbase product request failed with status %d", resp.StatusCode)
+	}
+
+	// Newer products no longer carry base_min_size and base_max_size, they
+	// are decoded when present
+	var product struct {
+		BaseMinSize   string `json:"base_min_size"`
+		BaseMaxSize   string `json:"base_max_size"`
+		BaseIncrement string `json:"base_increment"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+		return nil, fmt.Errorf("failed to decode coinbase response: %w", err)
+	}
+
+	info := &SymbolInfo{}
+	for _, field := range []struct {
+		raw string
+		dst *float64
+	}{
+		{product.BaseMinSize, &info.MinSize},
+		{product.BaseMaxSize, &info.MaxSize},
+		{product.BaseIncrement, &info.LotSize},
+	} {
+		if field.raw == "" {
+			continue
+		}
+		if *field.dst, err = strconv.ParseFloat(field.raw, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse coinbase product: %w", err)
+		}
+	}
+
+	return info, nil
+}
+
+// GetSymbolInfo retrieves the size rules of a symbol from Kraken
+func (k *KrakenExchange) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	endpoint := fmt.Sprintf("%s/0/public/AssetPairs?pair=%s", orDefault(k.BaseURL, KrakenMarketURL), url.QueryEscape(symbol+"USD"))
+
+	client := http.Client{Timeout: timeout(k.Timeout)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := send(&client, req, k.GetName(), k.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset pair from kraken: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Like Depth, the result is keyed by Kraken's own pair name
+	var krakenResponse struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			LotDecimals *int   `json:"lot_decimals"`
+			OrderMin    string `json:"ordermin"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&krakenResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode kraken response: %w", err)
+	}
+
+	for _, e := range krakenResponse.Error {
+		if strings.Contains(e, "Unknown asset pair") {
+			return nil, fmt.Errorf("kraken %sUSD: %w", symbol, ErrPairNotFound)
+		}
+	}
+	if len(krakenResponse.Error) != 0 {
+		return nil, fmt.Errorf("Kraken asset pair fetch failed with errors: %s", strings.Join(krakenResponse.Error, ", "))
+	}
+
+	for _, pair := range krakenResponse.Result {
+		info := &SymbolInfo{}
+		if pair.LotDecimals != nil {
+			info.LotSize = math.Pow10(-*pair.LotDecimals)
+		}
+		if pair.OrderMin != "" {
+			if info.MinSize, err = strconv.ParseFloat(pair.OrderMin, 64); err != nil {
+				return nil, fmt.Errorf("failed to parse kraken asset pair: %w", err)
+			}
+		}
+		return info, nil
+	}
+
+	return nil, fmt.Errorf("kraken %sUSD: %w", symbol, ErrPairNotFound)
+}
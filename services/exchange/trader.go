@@ -0,0 +1,73 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+)
+
+// Trader is implemented by exchanges that can place live orders through
+// their private, authenticated APIs
+type Trader interface {
+	// PlaceOrder submits an order and returns its initial status
+	PlaceOrder(ctx context.Context, req OrderRequest) (*OrderStatus, error)
+	// CancelOrder cancels an open order by its exchange order ID
+	CancelOrder(ctx context.Context, id string) error
+	// GetOrder returns the current status of an order by its exchange order ID
+	GetOrder(ctx context.Context, id string) (*OrderStatus, error)
+	// Get the name of the current exchange
+	GetName() string
+}
+
+// OrderType is the execution style of an order
+type OrderType string
+
+const (
+	OrderTypeMarket OrderType = "market"
+	OrderTypeLimit  OrderType = "limit"
+)
+
+// OrderRequest describes an order to place on an exchange
+type OrderRequest struct {
+	// ClientOrderID lets the caller find the order again, it should be unique
+	ClientOrderID string
+	Symbol        string
+	// Side is either buy or sell
+	Side   string
+	Type   OrderType
+	Amount float64
+	// LimitPrice is only used by limit orders
+	LimitPrice float64
+}
+
+// OrderState is the normalized state of an order on an exchange
+type OrderState string
+
+const (
+	OrderStatePending   OrderState = "pending"
+	OrderStateOpen      OrderState = "open"
+	OrderStateFilled    OrderState = "filled"
+	OrderStateCancelled OrderState = "cancelled"
+	OrderStateRejected  OrderState = "rejected"
+	OrderStateExpired   OrderState = "expired"
+	// OrderStateValidated is returned for orders that were checked by the
+	// exchange but, being in validate only mode, never submitted
+	OrderStateValidated OrderState = "validated"
+)
+
+// OrderStatus is the state of an order as reported by an exchange
+type OrderStatus struct {
+	ID            string     `json:"id"`
+	ClientOrderID string     `json:"clientOrderId,omitempty"`
+	Exchange      string     `json:"exchange"`
+	Symbol        string     `json:"symbol,omitempty"`
+	Side          string     `json:"side,omitempty"`
+	State         OrderState `json:"state"`
+	FilledAmount  float64    `json:"filledAmount"`
+	AvgPrice      float64    `json:"avgPrice,omitempty"`
+}
+
+// ErrNoCredentials is reported for venues configured without credentials
+var ErrNoCredentials = errors.New("no credentials")
+
+// ErrOrderRejected wraps the reason an exchange gave for rejecting a request
+var ErrOrderRejected = errors.New("order rejected")
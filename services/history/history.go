@@ -0,0 +1,232 @@
+package history
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/order"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets used by the store. Quotes are keyed by timestamp then ID so a
+// cursor walks them in time order, bySymbol holds one bucket per symbol with
+// the same keys and byID maps each ID to its key
+var (
+	quotesBucket   = []byte("quotes")
+	bySymbolBucket = []byte("by_symbol")
+	byIDBucket     = []byte("by_id")
+)
+
+// Paging limits for Query
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// ErrNotFound is returned when a quote isn't in the store
+var ErrNotFound = errors.New("quote not found")
+
+// ErrInvalidCursor is returned when a cursor wasn't produced by Query
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Store persists quote records in a local bbolt database
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens, or creates, the store at the given path
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open quote history %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{quotesBucket, bySymbolBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		if tx.Bucket(byIDBucket) != nil {
+			return nil
+		}
+
+		// Index the quotes recorded before IDs were indexed
+		byID, err := tx.CreateBucket(byIDBucket)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(quotesBucket).ForEach(func(k, _ []byte) error {
+			if len(k) == 8 {
+				return nil
+			}
+			return byID.Put(k[8:], k)
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize quote history: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// RecordQuote stores a quote record, it implements order.Recorder
+func (s *Store) RecordQuote(record *order.QuoteRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	key := recordKey(record.Timestamp, record.ID)
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(quotesBucket).Put(key, data); err != nil {
+			return err
+		}
+		if record.ID != "" {
+			if err := tx.Bucket(byIDBucket).Put([]byte(record.ID), key); err != nil {
+				return err
+			}
+		}
+
+		// Quotes rejected before naming a symbol are only kept by time
+		if record.Request.Symbol == "" {
+			return nil
+		}
+		symbols, err := tx.Bucket(bySymbolBucket).CreateBucketIfNotExists([]byte(record.Request.Symbol))
+		if err != nil {
+			return err
+		}
+		return symbols.Put(key, nil)
+	})
+}
+
+// Get returns the quote record with the given ID
+func (s *Store) Get(id string) (*order.QuoteRecord, error) {
+	var record *order.QuoteRecord
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		key := tx.Bucket(byIDBucket).Get([]byte(id))
+		if key == nil {
+			return ErrNotFound
+		}
+		data := tx.Bucket(quotesBucket).Get(key)
+		if data == nil {
+			return ErrNotFound
+		}
+
+		record = &order.QuoteRecord{}
+		return json.Unmarshal(data, record)
+	})
+
+	return record, err
+}
+
+// Query filters the quote history, the zero value matches everything
+type Query struct {
+	Symbol string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	// Cursor continues a previous query from where its page ended
+	Cursor string
+}
+
+// Page is a page of quote records, in time order
+type Page struct {
+	Quotes []*order.QuoteRecord `json:"quotes"`
+	// Next is the cursor for the following page, empty on the last page
+	Next string `json:"next,omitempty"`
+}
+
+// Query returns a page of the quote history matching the query
+func (s *Store) Query(q Query) (*Page, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	// Start from the cursor when there is one, the From time otherwise
+	start := recordKey(q.From, "")
+	skipStart := false
+	if q.Cursor != "" {
+		cursor, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+		if err != nil || len(cursor) < 8 {
+			return nil, ErrInvalidCursor
+		}
+		start, skipStart = cursor, true
+	}
+
+	page := &Page{Quotes: []*order.QuoteRecord{}}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		quotes := tx.Bucket(quotesBucket)
+		index := quotes
+		if q.Symbol != "" {
+			index = tx.Bucket(bySymbolBucket).Bucket([]byte(q.Symbol))
+			if index == nil {
+				return nil
+			}
+		}
+
+		c := index.Cursor()
+		k, _ := c.Seek(start)
+		if skipStart && k != nil && string(k) == string(start) {
+			k, _ = c.Next()
+		}
+
+		for ; k != nil; k, _ = c.Next() {
+			if !q.To.IsZero() && keyTime(k).After(q.To) {
+				break
+			}
+			if len(page.Quotes) == limit {
+				page.Next = base64.RawURLEncoding.EncodeToString(lastKey(page))
+				break
+			}
+
+			record := &order.QuoteRecord{}
+			if err := json.Unmarshal(quotes.Get(k), record); err != nil {
+				return err
+			}
+			page.Quotes = append(page.Quotes, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// recordKey orders records by timestamp, big endian so byte order matches
+// time order, then by ID
+func recordKey(t time.Time, id string) []byte {
+	key := make([]byte, 8, 8+len(id))
+	if !t.IsZero() {
+		binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	}
+	return append(key, id...)
+}
+
+// keyTime returns the timestamp encoded in a record key
+func keyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[:8])))
+}
+
+// lastKey returns the key of the last record of a page
+func lastKey(page *Page) []byte {
+	last := page.Quotes[len(page.Quotes)-1]
+	return recordKey(last.Timestamp, last.ID)
+}
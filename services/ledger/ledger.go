@@ -0,0 +1,427 @@
+package ledger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Cash is the asset balances are settled in
+const Cash = "USD"
+
+// QuoteTTL is how long a quote can be accepted for after it was produced
+const QuoteTTL = 30 * time.Second
+
+// Buckets used by the ledger
+var (
+	transactionsBucket = []byte("transactions")
+	balancesBucket     = []byte("balances")
+	positionsBucket    = []byte("positions")
+	acceptedBucket     = []byte("accepted")
+)
+
+var (
+	// ErrInsufficientFunds is returned when an account can't pay for a fill
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrQuoteExpired is returned when a quote is older than QuoteTTL
+	ErrQuoteExpired = errors.New("quote expired")
+	// ErrQuoteAccepted is returned when a quote was already filled
+	ErrQuoteAccepted = errors.New("quote already accepted")
+	// ErrQuoteFailed is returned when accepting a quote that didn't route
+	ErrQuoteFailed = errors.New("quote has no venue to fill against")
+	// ErrInvalidAmount is returned for deposits that aren't positive
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// QuoteLookup finds a previously produced quote, it is implemented by
+// history.Store
+type QuoteLookup interface {
+	Get(id string) (*order.QuoteRecord, error)
+}
+
+// Entry moves an amount of an asset into, when positive, or out of, when
+// negative, a book account. The entries of a transaction sum to zero per asset
+type Entry struct {
+	Account string  `json:"account"`
+	Asset   string  `json:"asset"`
+	Amount  float64 `json:"amount"`
+}
+
+// Transaction is a balanced set of entries
+type Transaction struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Account string    `json:"account"`
+	Type    string    `json:"type"`
+	QuoteID string    `json:"quoteId,omitempty"`
+	Entries []Entry   `json:"entries"`
+}
+
+// Transaction types
+const (
+	TypeDeposit = "deposit"
+	TypeFill    = "fill"
+)
+
+// Position is the holding of one asset by an account, tracked at average cost
+type Position struct {
+	Symbol   string  `json:"symbol"`
+	Quantity float64 `json:"quantity"`
+	// CostBasis is what the current quantity cost, fees included
+	CostBasis   float64 `json:"costBasis"`
+	RealizedPnL float64 `json:"realizedPnl"`
+}
+
+// Fill is the simulated execution of an accepted quote
+type Fill struct {
+	Transaction *Transaction `json:"transaction"`
+	Exchange    string       `json:"exchange"`
+	Side        order.Side   `json:"side"`
+	Symbol      string       `json:"symbol"`
+	Amount      float64      `json:"amount"`
+	AvgPrice    float64      `json:"avgPrice"`
+	Fee         float64      `json:"fee"`
+}
+
+// Ledger is a double entry paper trading ledger kept in a local bbolt
+// database. Every client account's holdings are mirrored by the venue it
+// traded with, or by equity for deposits, so the books always balance
+type Ledger struct {
+	db           *bolt.DB
+	orderService *order.OrderService
+	quotes       QuoteLookup
+}
+
+// Open opens, or creates, the ledger at the given path
+func Open(path string, orderService *order.OrderService, quotes QuoteLookup) (*Ledger, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ledger %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{transactionsBucket, balancesBucket, positionsBucket, acceptedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize ledger: %w", err)
+	}
+
+	return &Ledger{db: db, orderService: orderService, quotes: quotes}, nil
+}
+
+// Close closes the underlying database
+func (l *Ledger) Close() error {
+	return l.db.Close()
+}
+
+// Deposit credits an account with paper funds
+func (l *Ledger) Deposit(account, asset string, amount float64) (*Transaction, error) {
+	if !(amount > 0) || math.IsInf(amount, 0) {
+		return nil, ErrInvalidAmount
+	}
+
+	txn := newTransaction(account, TypeDeposit)
+	txn.Entries = []Entry{
+		{Account: clientAccount(account), Asset: asset, Amount: amount},
+		{Account: "equity:" + account, Asset: asset, Amount: -amount},
+	}
+
+	err := l.db.Update(func(tx *bolt.Tx) error {
+		if asset != Cash {
+			// Deposited coins enter the position at zero cost
+			pos, err := getPosition(tx, account, asset)
+			if err != nil {
+				return err
+			}
+			pos.Quantity += amount
+			if err := putPosition(tx, account, pos); err != nil {
+				return err
+			}
+		}
+		return post(tx, txn)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// Accept simulates filling a quote against the book of the venue it was
+// routed to and books the result against the account
+func (l *Ledger) Accept(ctx context.Context, account, quoteID string) (*Fill, error) {
+	record, err := l.quotes.Get(quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Error != "" || len(record.Exchanges) == 0 {
+		return nil, ErrQuoteFailed
+	}
+	if time.Since(record.Timestamp) > QuoteTTL {
+		return nil, ErrQuoteExpired
+	}
+
+	// Fill against the current book of the venue the quote was routed to
+	venue := record.Exchanges[0]
+	req := record.Request
+	quote, err := l.orderService.Quote(ctx, order.QuoteRequest{
+		Side:           req.Side,
+		Symbol:         req.Symbol,
+		Amount:         req.Amount,
+		MaxSlippageBps: req.MaxSlippageBps,
+		AllowPartial:   req.AllowPartial,
+		Venues:         []string{venue},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fill := &Fill{Exchange: venue, Side: req.Side, Symbol: req.Symbol, Amount: req.Amount, AvgPrice: quote.Price}
+	notional := quote.USDAmount
+	if quote.Fill != nil {
+		fill.Amount = quote.Fill.FilledAmount
+		fill.AvgPrice = quote.Fill.AvgPrice
+		notional = quote.Fill.USDAmount
+	}
+	fill.Fee = notional * quote.Fee
+
+	txn := newTransaction(account, TypeFill)
+	txn.QuoteID = quoteID
+	client, counterparty := clientAccount(account), "venue:"+venue
+	if req.Side == order.SideBuy {
+		txn.Entries = []Entry{
+			{Account: client, Asset: req.Symbol, Amount: fill.Amount},
+			{Account: counterparty, Asset: req.Symbol, Amount: -fill.Amount},
+			{Account: client, Asset: Cash, Amount: -(notional + fill.Fee)},
+			{Account: counterparty, Asset: Cash, Amount: notional},
+			{Account: "fees:" + venue, Asset: Cash, Amount: fill.Fee},
+		}
+	} else {
+		txn.Entries = []Entry{
+			{Account: client, Asset: req.Symbol, Amount: -fill.Amount},
+			{Account: counterparty, Asset: req.Symbol, Amount: fill.Amount},
+			{Account: client, Asset: Cash, Amount: notional - fill.Fee},
+			{Account: counterparty, Asset: Cash, Amount: -notional},
+			{Account: "fees:" + venue, Asset: Cash, Amount: fill.Fee},
+		}
+	}
+	fill.Transaction = txn
+
+	err = l.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(acceptedBucket).Get([]byte(quoteID)) != nil {
+			return ErrQuoteAccepted
+		}
+
+		// Make sure the account can pay for the fill
+		for _, entry := range txn.Entries {
+			if entry.Account == client && entry.Amount < 0 && getBalance(tx, client, entry.Asset)+entry.Amount < 0 {
+				return fmt.Errorf("%w: %s %s", ErrInsufficientFunds, account, entry.Asset)
+			}
+		}
+
+		pos, err := getPosition(tx, account, req.Symbol)
+		if err != nil {
+			return err
+		}
+		if req.Side == order.SideBuy {
+			pos.Quantity += fill.Amount
+			pos.CostBasis += notional + fill.Fee
+		} else {
+			avgCost := 0.0
+			if pos.Quantity > 0 {
+				avgCost = pos.CostBasis / pos.Quantity
+			}
+			pos.RealizedPnL += notional - fill.Fee - avgCost*fill.Amount
+			pos.CostBasis -= avgCost * fill.Amount
+			pos.Quantity -= fill.Amount
+		}
+		if err := putPosition(tx, account, pos); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(acceptedBucket).Put([]byte(quoteID), []byte(txn.ID)); err != nil {
+			return err
+		}
+		return post(tx, txn)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fill, nil
+}
+
+// PositionValue is a position marked to the market
+type PositionValue struct {
+	Position
+	AvgCost       float64 `json:"avgCost"`
+	MarketPrice   float64 `json:"marketPrice"`
+	MarketValue   float64 `json:"marketValue"`
+	UnrealizedPnL float64 `json:"unrealizedPnl"`
+	// Error is set when the position couldn't be marked to the market
+	Error string `json:"error,omitempty"`
+}
+
+// Account is the state of a client account
+type Account struct {
+	ID            string             `json:"id"`
+	Balances      map[string]float64 `json:"balances"`
+	Positions     []PositionValue    `json:"positions"`
+	RealizedPnL   float64            `json:"realizedPnl"`
+	UnrealizedPnL float64            `json:"unrealizedPnl"`
+}
+
+// Account returns the balances and positions of an account. Positions are
+// marked at the best bid across every venue, what they could be sold for
+func (l *Ledger) Account(ctx context.Context, id string) (*Account, error) {
+	account := &Account{ID: id, Balances: map[string]float64{}, Positions: []PositionValue{}}
+	positions := []Position{}
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		prefix := []byte(clientAccount(id) + "|")
+		c := tx.Bucket(balancesBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && strings.HasPrefix(string(k), string(prefix)); k, v = c.Next() {
+			account.Balances[strings.TrimPrefix(string(k), string(prefix))] = decodeFloat(v)
+		}
+
+		prefix = []byte(id + "|")
+		c = tx.Bucket(positionsBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && strings.HasPrefix(string(k), string(prefix)); k, v = c.Next() {
+			var pos Position
+			if err := json.Unmarshal(v, &pos); err != nil {
+				return err
+			}
+			positions = append(positions, pos)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	for _, pos := range positions {
+		value := PositionValue{Position: pos}
+		if pos.Quantity != 0 {
+			value.AvgCost = pos.CostBasis / pos.Quantity
+
+			market, err := l.orderService.Market(ctx, pos.Symbol)
+			if err != nil {
+				value.Error = err.Error()
+			} else {
+				value.MarketPrice = market.BestBid.Price
+				value.MarketValue = pos.Quantity * value.MarketPrice
+				value.UnrealizedPnL = value.MarketValue - pos.CostBasis
+			}
+		}
+
+		account.RealizedPnL += value.RealizedPnL
+		account.UnrealizedPnL += value.UnrealizedPnL
+		account.Positions = append(account.Positions, value)
+	}
+
+	return account, nil
+}
+
+// newTransaction creates an empty transaction for an account
+func newTransaction(account, kind string) *Transaction {
+	return &Transaction{ID: uuid.NewString(), Time: time.Now().UTC(), Account: account, Type: kind}
+}
+
+// clientAccount names the book account holding a client's assets
+func clientAccount(id string) string {
+	return "client:" + id
+}
+
+// post checks a transaction balances, stores it and applies its entries
+func post(tx *bolt.Tx, txn *Transaction) error {
+	sums := map[string]float64{}
+	for _, entry := range txn.Entries {
+		sums[entry.Asset] += entry.Amount
+	}
+	for asset, sum := range sums {
+		if math.Abs(sum) > 1e-9 {
+			return fmt.Errorf("transaction %s doesn't balance: %g %s", txn.ID, sum, asset)
+		}
+	}
+
+	data, err := json.Marshal(txn)
+	if err != nil {
+		return err
+	}
+	key := make([]byte, 8, 8+len(txn.ID))
+	binary.BigEndian.PutUint64(key, uint64(txn.Time.UnixNano()))
+	if err := tx.Bucket(transactionsBucket).Put(append(key, txn.ID...), data); err != nil {
+		return err
+	}
+
+	balances := tx.Bucket(balancesBucket)
+	for _, entry := range txn.Entries {
+		balance := getBalance(tx, entry.Account, entry.Asset) + entry.Amount
+		if err := balances.Put(balanceKey(entry.Account, entry.Asset), encodeFloat(balance)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getBalance returns the balance of an asset in a book account
+func getBalance(tx *bolt.Tx, account, asset string) float64 {
+	return decodeFloat(tx.Bucket(balancesBucket).Get(balanceKey(account, asset)))
+}
+
+// balanceKey is the key of an asset balance in a book account
+func balanceKey(account, asset string) []byte {
+	return []byte(account + "|" + asset)
+}
+
+// getPosition returns an account's position in a symbol, empty when the
+// account never held it
+func getPosition(tx *bolt.Tx, account, symbol string) (*Position, error) {
+	pos := &Position{Symbol: symbol}
+	if data := tx.Bucket(positionsBucket).Get([]byte(account + "|" + symbol)); data != nil {
+		if err := json.Unmarshal(data, pos); err != nil {
+			return nil, err
+		}
+	}
+	return pos, nil
+}
+
+// putPosition stores an account's position
+func putPosition(tx *bolt.Tx, account string, pos *Position) error {
+	data, err := json.Marshal(pos)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(positionsBucket).Put([]byte(account+"|"+pos.Symbol), data)
+}
+
+// encodeFloat and decodeFloat store balances as their IEEE 754 bits
+func encodeFloat(f float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(f))
+	return b
+}
+
+func decodeFloat(b []byte) float64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(b))
+}
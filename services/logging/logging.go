@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request, it is propagated when the
+// caller sends one and generated otherwise
+const RequestIDHeader = "X-Request-ID"
+
+// Longest request ID accepted from a caller, longer ones are replaced
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// New creates a JSON logger writing to w at the given level, one of debug,
+// info, warn or error. Every record logged with a request's context carries
+// its request ID
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l})
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Discard returns a logger that drops every record, used by default so
+// services stay quiet unless a logger is injected
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+// OrDiscard returns logger, or a discarding logger when it is nil
+func OrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return Discard()
+	}
+	return logger
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request ID carried by the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Middleware assigns every request an ID, echoes it in the response headers
+// and logs the request once it has been handled
+func Middleware(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		id := strings.TrimSpace(c.Get(RequestIDHeader))
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+		c.Set(RequestIDHeader, id)
+		ctx := WithRequestID(c.UserContext(), id)
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// Errors returned by handlers are turned into responses later on, use
+		// the status they will be sent with
+		status := c.Response().StatusCode()
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(ctx, level, "request",
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.String("route", c.Route().Path),
+			slog.Int("status", status),
+			slog.Int("bytes", len(c.Response().Body())),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
+
+		return err
+	}
+}
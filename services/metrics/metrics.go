@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Upstream error types
+const (
+	ErrorTimeout  = "timeout"
+	ErrorNotFound = "not_found"
+	ErrorDecode   = "decode"
+	ErrorUpstream = "upstream"
+)
+
+// Metrics holds the service's Prometheus collectors. It implements
+// order.Observer for the upstream and routing metrics
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	upstreamDuration *prometheus.HistogramVec
+	upstreamErrors   *prometheus.CounterVec
+	upstreamTimeouts *prometheus.CounterVec
+
+	venueWins     *prometheus.CounterVec
+	venuesSkipped prometheus.Histogram
+}
+
+// New creates a new Metrics with its own registry, which also includes the Go
+// runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests handled, by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency, by route, method and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "exchange_request_duration_seconds",
+			Help:    "Latency of price requests to each exchange.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"exchange"}),
+		upstreamErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "exchange_errors_total",
+			Help: "Failed price requests to each exchange, by error type.",
+		}, []string{"exchange", "type"}),
+		upstreamTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "exchange_timeouts_total",
+			Help: "Price requests to each exchange that timed out.",
+		}, []string{"exchange"}),
+		venueWins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "quote_venue_wins_total",
+			Help: "Quotes routed to each exchange, by side.",
+		}, []string{"exchange", "side"}),
+		venuesSkipped: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "quote_venues_skipped",
+			Help:    "Exchanges skipped per quote because they failed to answer.",
+			Buckets: []float64{0, 1, 2, 3, 5, 8},
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.requestDuration,
+		m.upstreamDuration, m.upstreamErrors, m.upstreamTimeouts,
+		m.venueWins, m.venuesSkipped,
+	)
+
+	return m
+}
+
+// Middleware records the count and latency of every request by its route
+// pattern, so path parameters don't explode the label cardinality
+func (m *Metrics) Middleware(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	// Errors returned by handlers are turned into responses later on, use
+	// the status they will be sent with
+	status := c.Response().StatusCode()
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+	} else if err != nil {
+		status = fiber.StatusInternalServerError
+	}
+
+	labels := prometheus.Labels{
+		"route":  c.Route().Path,
+		"method": c.Method(),
+		"status": strconv.Itoa(status),
+	}
+	m.requests.With(labels).Inc()
+	m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+
+	return err
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// ObserveVenue records the latency and outcome of a price request
+func (m *Metrics) ObserveVenue(name string, latency time.Duration, err error) {
+	m.upstreamDuration.WithLabelValues(name).Observe(latency.Seconds())
+	if err == nil {
+		return
+	}
+
+	kind := errorType(err)
+	m.upstreamErrors.WithLabelValues(name, kind).Inc()
+	if kind == ErrorTimeout {
+		m.upstreamTimeouts.WithLabelValues(name).Inc()
+	}
+}
+
+// ObserveQuote records which venues won a quote and how many were skipped
+func (m *Metrics) ObserveQuote(side order.Side, exchanges []string, skipped int) {
+	for _, name := range exchanges {
+		m.venueWins.WithLabelValues(name, string(side)).Inc()
+	}
+	m.venuesSkipped.Observe(float64(skipped))
+}
+
+// errorType classifies an upstream error
+func errorType(err error) string {
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.Is(err, exchange.ErrPairNotFound):
+		return ErrorNotFound
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ErrorDecode
+	default:
+		return ErrorUpstream
+	}
+}
@@ -0,0 +1,204 @@
+// Package openapi serves the API's OpenAPI document and validates requests
+// against it, so the documentation and the behaviour can't drift apart
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// document is the API's OpenAPI 3 document
+//
+//go:embed openapi.yaml
+var document []byte
+
+// The documentation page and event streams are validated as plain text, like
+// text/plain bodies
+func init() {
+	openapi3filter.RegisterBodyDecoder(fiber.MIMETextHTML, plainBody)
+	openapi3filter.RegisterBodyDecoder("text/event-stream", plainBody)
+}
+
+// Spec is the loaded OpenAPI document
+type Spec struct {
+	doc    *openapi3.T
+	router routers.Router
+	json   []byte
+}
+
+// Load parses and validates the embedded OpenAPI document
+func Load() (*Spec, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI document: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route OpenAPI document: %w", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Spec{doc: doc, router: router, json: data}, nil
+}
+
+// JSON returns the document as JSON
+func (s *Spec) JSON() []byte {
+	return s.json
+}
+
+// Operations returns every documented operation as "METHOD /path", with path
+// parameters written the way Fiber routes them, e.g. "GET /v1/orders/:id"
+func (s *Spec) Operations() []string {
+	operations := []string{}
+	for path, item := range s.doc.Paths.Map() {
+		for method := range item.Operations() {
+			operations = append(operations, method+" "+fiberPath(path))
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+// Middleware rejects requests to documented operations that don't conform to
+// the document with a 400. Undocumented routes are passed through, API keys
+// are left to the auth middleware
+func (s *Spec) Middleware(c *fiber.Ctx) error {
+	req, err := adaptor.ConvertRequest(c, false)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request", "code": "invalid_request"})
+	}
+
+	route, pathParams, err := s.router.FindRoute(req)
+	if err != nil {
+		return c.Next()
+	}
+
+	err = openapi3filter.ValidateRequest(c.UserContext(), &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+			SkipSettingDefaults: true,
+		},
+	})
+	if err != nil {
+		message, code := describe(err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": message, "code": code})
+	}
+	return c.Next()
+}
+
+// ValidateResponse checks that a response to a documented operation conforms
+// to the document
+func (s *Spec) ValidateResponse(req *http.Request, status int, header http.Header, body []byte) error {
+	route, pathParams, err := s.router.FindRoute(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status: status,
+		Header: header,
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+		},
+	}
+	input.SetBodyBytes(body)
+	return openapi3filter.ValidateResponse(req.Context(), input)
+}
+
+// fieldCodes are the error codes of the fields whose failures get their own,
+// the same ones the handlers send
+var fieldCodes = map[string]string{
+	"amount":              "invalid_amount",
+	"symbol":              "invalid_symbol",
+	"limit":               "invalid_limit",
+	"interval":            "invalid_interval",
+	"side":                "invalid_side",
+	"maxSlippageBps":      "invalid_max_slippage",
+	"priceImprovementBps": "invalid_price_improvement",
+}
+
+// describe turns a validation failure into an error message in the style of
+// the handlers', e.g. "invalid amount: number must be more than 0", and its
+// error code
+func describe(err error) (string, string) {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return err.Error(), "invalid_request"
+	}
+
+	reason := requestErr.Reason
+	if requestErr.Err != nil {
+		reason = requestErr.Err.Error()
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(requestErr.Err, &schemaErr) {
+		reason = schemaErr.Reason
+		if pointer := schemaErr.JSONPointer(); requestErr.RequestBody != nil && len(pointer) > 0 {
+			return fmt.Sprintf("invalid %s: %s", strings.Join(pointer, "."), reason), fieldCode(pointer[0])
+		}
+	}
+
+	switch {
+	case requestErr.Parameter != nil:
+		return fmt.Sprintf("invalid %s: %s", requestErr.Parameter.Name, reason), fieldCode(requestErr.Parameter.Name)
+	case requestErr.RequestBody != nil:
+		return "invalid request body: " + reason, "invalid_request"
+	default:
+		return reason, "invalid_request"
+	}
+}
+
+// fieldCode returns the error code of a failure on field
+func fieldCode(field string) string {
+	if code, ok := fieldCodes[field]; ok {
+		return code
+	}
+	return "invalid_request"
+}
+
+// plainBody decodes a body as a string
+func plainBody(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (any, error) {
+	data, err := io.ReadAll(body)
+	return string(data), err
+}
+
+// fiberPath converts an OpenAPI path template to a Fiber route
+func fiberPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + strings.Trim(segment, "{}")
+		}
+	}
+	return strings.Join(segments, "/")
+}
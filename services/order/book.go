@@ -0,0 +1,59 @@
+package order
+
+import (
+	"context"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+)
+
+// VenueBook is the order book of a single venue along with its taker fee
+type VenueBook struct {
+	Exchange string
+	// Book only holds the top of book, with zero sizes, for venues that
+	// don't provide depth
+	Book *exchange.OrderBook
+	// Fee is compounded across both legs for synthetic routes
+	Fee   float64
+	Route *Route
+	Err   error
+}
+
+// Books returns the order book of every enabled venue for a symbol, in the
+// same order as the exchanges the service was created with
+func (o *OrderService) Books(ctx context.Context, symbol string) []VenueBook {
+	exchanges := o.enabled()
+	results := o.fetchFrom(ctx, exchanges, symbol, bookDepth)
+
+	books := make([]VenueBook, len(results))
+	for i, r := range results {
+		books[i] = VenueBook{Exchange: r.name, Fee: takerFee(exchanges[i]), Route: r.route, Err: r.err}
+		if r.err != nil {
+			continue
+		}
+		if r.route != nil {
+			books[i].Fee = compoundFee(books[i].Fee)
+		}
+
+		books[i].Book = r.book
+		if books[i].Book == nil {
+			books[i].Book = &exchange.OrderBook{
+				Bids: []exchange.Level{{Price: r.bid}},
+				Asks: []exchange.Level{{Price: r.ask}},
+			}
+		}
+	}
+
+	return books
+}
+
+// takerFee returns the taker fee of an exchange, or zero when it doesn't
+// report one
+func takerFee(ex exchange.Exchange) float64 {
+	if ex == nil {
+		return 0
+	}
+	if fp, ok := ex.(exchange.FeeProvider); ok {
+		return fp.GetTakerFee()
+	}
+	return 0
+}
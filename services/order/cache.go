@@ -0,0 +1,79 @@
+package order
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the cache, expired entries are swept once it grows
+// past this size
+const maxCacheEntries = 1024
+
+// priceCache keeps recent venue results for a short while so bursts of
+// requests for the same symbol don't all reach the exchanges
+type priceCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	exchange string
+	symbol   string
+	depth    int
+}
+
+type cacheEntry struct {
+	result  venueResult
+	expires time.Time
+}
+
+// SetCacheTTL caches successful venue results for ttl, zero disables caching
+func (o *OrderService) SetCacheTTL(ttl time.Duration) {
+	o.cache.mu.Lock()
+	defer o.cache.mu.Unlock()
+
+	o.cache.ttl = ttl
+	o.cache.entries = map[cacheKey]cacheEntry{}
+}
+
+// get returns a cached result that hasn't expired yet
+func (c *priceCache) get(exchange, symbol string, depth int) (venueResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 {
+		return venueResult{}, false
+	}
+
+	key := cacheKey{exchange, symbol, depth}
+	entry, ok := c.entries[key]
+	if !ok {
+		return venueResult{}, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return venueResult{}, false
+	}
+	return entry.result, true
+}
+
+// put caches a successful result
+func (c *priceCache) put(exchange, symbol string, depth int, result venueResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 || result.err != nil {
+		return
+	}
+
+	now := time.Now()
+	if len(c.entries) >= maxCacheEntries {
+		for key, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+	}
+	c.entries[cacheKey{exchange, symbol, depth}] = cacheEntry{result: result, expires: now.Add(c.ttl)}
+}
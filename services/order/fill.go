@@ -0,0 +1,75 @@
+package order
+
+import "github.com/SmMistry/triumph-project/services/exchange"
+
+// Fill is the estimated result of walking a venue's book for a quote
+type Fill struct {
+	// AvgPrice is the volume weighted price of the filled amount
+	AvgPrice float64
+	// FilledAmount is how much of the requested amount the book can absorb
+	FilledAmount float64
+	// USDAmount is the total cost, or proceeds, of the filled amount
+	USDAmount float64
+	// SlippageBps is how far AvgPrice is from the top of book
+	SlippageBps float64
+	// PriceImpactBps is how far AvgPrice is from the consensus mid
+	PriceImpactBps float64
+	// Partial is set when FilledAmount is less than the requested amount
+	Partial bool
+}
+
+// bookSide returns the levels a client on the given side would trade against
+func bookSide(book *exchange.OrderBook, side Side) []exchange.Level {
+	if side == SideSell {
+		return book.Bids
+	}
+	return book.Asks
+}
+
+// estimateFill walks the levels, best first, until amount is filled. When
+// maxSlippageBps is set, levels priced further than that from the top of book
+// are left untouched and the fill is reported as partial
+func estimateFill(levels []exchange.Level, side Side, amount, maxSlippageBps, mid float64) *Fill {
+	fill := &Fill{}
+	if len(levels) == 0 {
+		fill.Partial = true
+		return fill
+	}
+
+	top := levels[0].Price
+	remaining := amount
+	for _, level := range levels {
+		if remaining <= 0 {
+			break
+		}
+		if maxSlippageBps > 0 && bps(side, top, level.Price) > maxSlippageBps {
+			break
+		}
+
+		size := min(level.Size, remaining)
+		fill.FilledAmount += size
+		fill.USDAmount += size * level.Price
+		remaining -= size
+	}
+
+	fill.Partial = remaining > 0
+	if fill.FilledAmount == 0 {
+		return fill
+	}
+
+	fill.AvgPrice = fill.USDAmount / fill.FilledAmount
+	fill.SlippageBps = bps(side, top, fill.AvgPrice)
+	if mid > 0 {
+		fill.PriceImpactBps = bps(side, mid, fill.AvgPrice)
+	}
+
+	return fill
+}
+
+// bps returns how much worse price is than ref for the side, in basis points
+func bps(side Side, ref, price float64) float64 {
+	if side == SideSell {
+		return (ref - price) / ref * 1e4
+	}
+	return (price - ref) / ref * 1e4
+}
@@ -0,0 +1,76 @@
+package order
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultAnswerWindow is how long an answer counts towards readiness unless
+// overridden
+const defaultAnswerWindow = 5 * time.Minute
+
+// venueHealth remembers when each venue last answered successfully
+type venueHealth struct {
+	mu       sync.Mutex
+	window   time.Duration
+	answered map[string]time.Time
+}
+
+// track records the venues that answered
+func (h *venueHealth) track(results []venueResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for _, r := range results {
+		if r.err != nil || r.cached {
+			continue
+		}
+		if h.answered == nil {
+			h.answered = map[string]time.Time{}
+		}
+		h.answered[r.name] = now
+	}
+}
+
+// SetAnswerWindow sets how long a venue's last successful answer counts
+// towards readiness, five minutes by default
+func (o *OrderService) SetAnswerWindow(window time.Duration) {
+	o.health.mu.Lock()
+	defer o.health.mu.Unlock()
+
+	o.health.window = window
+}
+
+// AnsweredVenues returns the enabled venues that have answered successfully
+// within the answer window, in order
+func (o *OrderService) AnsweredVenues() []string {
+	enabled := o.enabled()
+
+	o.health.mu.Lock()
+	defer o.health.mu.Unlock()
+
+	window := o.health.window
+	if window <= 0 {
+		window = defaultAnswerWindow
+	}
+	since := time.Now().Add(-window)
+
+	names := make([]string, 0, len(enabled))
+	for _, ex := range enabled {
+		if answered, ok := o.health.answered[ex.GetName()]; ok && answered.After(since) {
+			names = append(names, ex.GetName())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Probe queries every venue for the top of book of symbol, so venues are
+// known to answer before any quote has been requested
+func (o *OrderService) Probe(ctx context.Context, symbol string) []string {
+	o.fetch(ctx, symbol, 0)
+	return o.AnsweredVenues()
+}
@@ -0,0 +1,155 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status is a stage of an order's lifecycle
+type Status string
+
+const (
+	StatusNew             Status = "new"
+	StatusRouted          Status = "routed"
+	StatusPartiallyFilled Status = "partially_filled"
+	StatusFilled          Status = "filled"
+	StatusCancelled       Status = "cancelled"
+	StatusRejected        Status = "rejected"
+	StatusExpired         Status = "expired"
+)
+
+// transitions lists the statuses each status may move to, terminal statuses
+// have none
+var transitions = map[Status][]Status{
+	StatusNew:             {StatusRouted, StatusRejected, StatusCancelled, StatusExpired},
+	StatusRouted:          {StatusPartiallyFilled, StatusFilled, StatusCancelled, StatusRejected, StatusExpired},
+	StatusPartiallyFilled: {StatusPartiallyFilled, StatusFilled, StatusCancelled, StatusExpired},
+}
+
+// ErrInvalidTransition is returned when an order can't move to a status
+var ErrInvalidTransition = errors.New("invalid order transition")
+
+// Terminal reports whether no further transitions are possible
+func (s Status) Terminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// Transition records an order moving from one status to another
+type Transition struct {
+	From   Status    `json:"from,omitempty"`
+	To     Status    `json:"to"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason"`
+}
+
+// Order is a client order. Orders split across venues have one child order
+// per venue, linked to the parent by ParentID
+type Order struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parentId,omitempty"`
+	// ClientOrderID is the idempotency key given by the client, if any
+	ClientOrderID string  `json:"clientOrderId,omitempty"`
+	Side          Side    `json:"side"`
+	Symbol        string  `json:"symbol"`
+	Amount        float64 `json:"amount"`
+	FilledAmount  float64 `json:"filledAmount"`
+	AvgPrice      float64 `json:"avgPrice,omitempty"`
+	// Exchange and ExchangeOrderID are only set on child orders
+	Exchange        string       `json:"exchange,omitempty"`
+	ExchangeOrderID string       `json:"exchangeOrderId,omitempty"`
+	Status          Status       `json:"status"`
+	Transitions     []Transition `json:"transitions"`
+	Children        []string     `json:"children,omitempty"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	UpdatedAt       time.Time    `json:"updatedAt"`
+}
+
+// newOrder creates an order in the new status
+func newOrder(id string, side Side, symbol string, amount float64, reason string, at time.Time) *Order {
+	return &Order{
+		ID:          id,
+		Side:        side,
+		Symbol:      symbol,
+		Amount:      amount,
+		Status:      StatusNew,
+		Transitions: []Transition{{To: StatusNew, At: at, Reason: reason}},
+		CreatedAt:   at,
+		UpdatedAt:   at,
+	}
+}
+
+// Transition moves the order to a new status, recording when and why
+func (o *Order) Transition(to Status, reason string, at time.Time) error {
+	allowed := false
+	for _, next := range transitions[o.Status] {
+		if next == to {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s from %s to %s", ErrInvalidTransition, o.ID, o.Status, to)
+	}
+
+	o.Transitions = append(o.Transitions, Transition{From: o.Status, To: to, At: at, Reason: reason})
+	o.Status = to
+	o.UpdatedAt = at
+	return nil
+}
+
+// rollUp derives a parent order's fill and status from its children
+func (o *Order) rollUp(children []*Order, at time.Time) error {
+	var filled, notional float64
+	counts := map[Status]int{}
+	for _, child := range children {
+		filled += child.FilledAmount
+		notional += child.FilledAmount * child.AvgPrice
+		counts[child.Status]++
+	}
+
+	previouslyFilled := o.FilledAmount
+	o.FilledAmount = filled
+	if filled > 0 {
+		o.AvgPrice = notional / filled
+	}
+
+	next := StatusRouted
+	switch {
+	case counts[StatusFilled] == len(children):
+		next = StatusFilled
+	case counts[StatusRejected] == len(children):
+		next = StatusRejected
+	case allTerminal(children):
+		// Whatever didn't fill is done for, report it as expired only when
+		// no venue was cancelled
+		next = StatusCancelled
+		if counts[StatusExpired] > 0 && counts[StatusCancelled] == 0 {
+			next = StatusExpired
+		}
+	case filled > 0:
+		next = StatusPartiallyFilled
+	}
+
+	if next == o.Status && (next != StatusPartiallyFilled || filled == previouslyFilled) {
+		return nil
+	}
+
+	reason := fmt.Sprintf("%g of %g filled across %d venues", filled, o.Amount, len(children))
+	if o.Status == StatusNew && next != StatusRouted && next != StatusRejected {
+		if err := o.Transition(StatusRouted, reason, at); err != nil {
+			return err
+		}
+	}
+	return o.Transition(next, reason, at)
+}
+
+// allTerminal reports whether every order is in a terminal status
+func allTerminal(orders []*Order) bool {
+	for _, o := range orders {
+		if !o.Status.Terminal() {
+			return false
+		}
+	}
+	return true
+}
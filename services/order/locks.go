@@ -0,0 +1,42 @@
+package order
+
+import "sync"
+
+// orderLocks hands out one lock per key, so work on one order waits only for
+// other work on the same order
+type orderLocks struct {
+	mu    sync.Mutex
+	locks map[string]*orderLock
+}
+
+// orderLock is the lock of one key, dropped once nobody holds or waits for it
+type orderLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks key, returning the func that unlocks it
+func (l *orderLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*orderLock{}
+	}
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &orderLock{}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if lock.refs--; lock.refs == 0 {
+			delete(l.locks, key)
+		}
+	}
+}
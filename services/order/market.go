@@ -0,0 +1,103 @@
+package order
+
+import (
+	"context"
+	"fmt"
+)
+
+// VenueMarket is the top of book summary for a single venue
+type VenueMarket struct {
+	Exchange  string  `json:"exchange"`
+	Bid       float64 `json:"bid,omitempty"`
+	Ask       float64 `json:"ask,omitempty"`
+	Mid       float64 `json:"mid,omitempty"`
+	Spread    float64 `json:"spread,omitempty"`
+	SpreadBps float64 `json:"spreadBps,omitempty"`
+	// BidSize and AskSize are only known for venues that provide depth
+	BidSize float64 `json:"bidSize,omitempty"`
+	AskSize float64 `json:"askSize,omitempty"`
+	// Route is set when the venue was priced synthetically
+	Route *Route `json:"route,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// BestPrice is the best price on one side of the aggregated book and the
+// venues quoting it
+type BestPrice struct {
+	Price     float64  `json:"price"`
+	Exchanges []string `json:"exchanges"`
+}
+
+// Market summarises the top of book for a symbol across every venue
+type Market struct {
+	Symbol  string        `json:"symbol"`
+	Venues  []VenueMarket `json:"venues"`
+	BestBid BestPrice     `json:"bestBid"`
+	BestAsk BestPrice     `json:"bestAsk"`
+	// Spread and SpreadBps are measured between BestBid and BestAsk, they go
+	// negative when the venues are crossed
+	Spread    float64 `json:"spread"`
+	SpreadBps float64 `json:"spreadBps"`
+	// MidPrice is the consensus mid across every venue that answered
+	MidPrice float64 `json:"midPrice"`
+}
+
+// Market returns the per venue and aggregated top of book for a symbol
+func (o *OrderService) Market(ctx context.Context, symbol string) (*Market, error) {
+	symbol, err := o.NormalizeSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	results := o.fetch(ctx, symbol, 1)
+
+	bestAsk, askExchanges := bestOf(results, SideBuy)
+	bestBid, bidExchanges := bestOf(results, SideSell)
+	if (bestAsk == nil || bestBid == nil) && allPairsNotFound(results) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSymbol, symbol)
+	}
+	if bestAsk == nil || bestBid == nil {
+		return nil, fmt.Errorf("failed to find best price for %s", symbol)
+	}
+
+	market := &Market{
+		Symbol:   symbol,
+		Venues:   make([]VenueMarket, 0, len(results)),
+		BestBid:  BestPrice{Price: bestBid.bid, Exchanges: bidExchanges},
+		BestAsk:  BestPrice{Price: bestAsk.ask, Exchanges: askExchanges},
+		MidPrice: consensusMid(results),
+	}
+	market.Spread, market.SpreadBps = spread(market.BestBid.Price, market.BestAsk.Price)
+
+	for _, r := range results {
+		venue := VenueMarket{Exchange: r.name}
+		if r.err != nil {
+			venue.Error = r.err.Error()
+			market.Venues = append(market.Venues, venue)
+			continue
+		}
+
+		venue.Bid = r.bid
+		venue.Ask = r.ask
+		venue.Mid = r.mid()
+		venue.Route = r.route
+		venue.Spread, venue.SpreadBps = spread(r.bid, r.ask)
+		if r.book != nil {
+			venue.BidSize = r.book.Bids[0].Size
+			venue.AskSize = r.book.Asks[0].Size
+		}
+		market.Venues = append(market.Venues, venue)
+	}
+
+	return market, nil
+}
+
+// spread returns the absolute spread between bid and ask and the same spread
+// in basis points of the mid
+func spread(bid, ask float64) (float64, float64) {
+	mid := (bid + ask) / 2
+	if mid == 0 {
+		return 0, 0
+	}
+	return ask - bid, (ask - bid) / mid * 1e4
+}
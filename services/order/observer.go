@@ -0,0 +1,47 @@
+package order
+
+import "time"
+
+// Observer receives measurements of the service's upstream calls and routing
+// decisions, typically to export them as metrics
+type Observer interface {
+	// ObserveVenue is called once for every exchange queried
+	ObserveVenue(exchange string, latency time.Duration, err error)
+	// ObserveQuote is called once for every quote that found a price, with
+	// the venues it was routed to and how many venues were skipped because
+	// they failed to answer
+	ObserveQuote(side Side, exchanges []string, skipped int)
+}
+
+// SetObserver sets the observer measurements are sent to
+func (o *OrderService) SetObserver(observer Observer) {
+	o.observer = observer
+}
+
+// observeVenues reports every venue result that reached the exchange to the
+// observer
+func (o *OrderService) observeVenues(results []venueResult) {
+	if o.observer == nil {
+		return
+	}
+	for _, r := range results {
+		if !r.cached {
+			o.observer.ObserveVenue(r.name, r.latency, r.err)
+		}
+	}
+}
+
+// observeQuote reports a quote's routing to the observer
+func (o *OrderService) observeQuote(quote *Quote, results []venueResult) {
+	if o.observer == nil || quote == nil {
+		return
+	}
+
+	skipped := 0
+	for _, r := range results {
+		if r.err != nil {
+			skipped++
+		}
+	}
+	o.observer.ObserveQuote(quote.Side, quote.Exchanges, skipped)
+}
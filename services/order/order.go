@@ -1,84 +1,373 @@
 package order
 
 import (
-	"fmt"
-	"log"
 	"context"
-	
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Side is the direction of a quote, from the client's point of view
+type Side string
+
+const (
+	SideBuy  Side = "buy"
+	SideSell Side = "sell"
 )
 
+// bookDepth is the number of levels per side requested from venues that can
+// return order book depth
+const bookDepth = 50
+
+// ErrInsufficientLiquidity is returned when the book can't fill the requested
+// amount within the requested slippage limit
+var ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+
 // OrderService handles order execution logic
 type OrderService struct {
-	exchanges []exchange.Exchange
+	venues              venueSet
+	intermediates       []string
+	priceImprovementBps float64
+	recorder            Recorder
+	orders              *OrderStore
+	traders             map[string]exchange.Trader
+	orderLocks          orderLocks
+	observer            Observer
+	logger              *slog.Logger
+	cache               priceCache
+	health              venueHealth
+	aliases             map[string]string
+	maxAmount           float64
+	rules               symbolRules
 }
 
 // NewOrderService creates a new OrderService with the given exchanges
 func NewOrderService(exchanges ...exchange.Exchange) *OrderService {
-	return &OrderService{exchanges: exchanges}
+	// Copied so replacing an exchange never writes to the caller's slice
+	return &OrderService{venues: venueSet{all: slices.Clone(exchanges)}, logger: logging.Discard()}
+}
+
+// SetLogger sets the logger the service reports upstream failures to, nothing
+// is logged by default
+func (o *OrderService) SetLogger(logger *slog.Logger) {
+	o.logger = logging.OrDiscard(logger)
+}
+
+// QuoteRequest describes a buy or sell quote
+type QuoteRequest struct {
+	Side   Side    `json:"side"`
+	Symbol string  `json:"symbol"`
+	Amount float64 `json:"amount"`
+	// MaxSlippageBps limits how far from the best price the fill may walk
+	// the book, zero means no limit
+	MaxSlippageBps float64 `json:"maxSlippageBps,omitempty"`
+	// AllowPartial caps the fill at the available size instead of rejecting
+	// the quote when MaxSlippageBps can't be honoured for the full amount
+	AllowPartial bool `json:"allowPartial,omitempty"`
+	// Venues limits routing to the named venues, empty means every venue
+	Venues []string `json:"venues,omitempty"`
+	// ExcludeVenues are never queried nor routed to
+	ExcludeVenues []string `json:"excludeVenues,omitempty"`
+	// PreferredVenue wins unless another venue improves on its price by at
+	// least PriceImprovementBps
+	PreferredVenue string `json:"preferredVenue,omitempty"`
+	// PriceImprovementBps overrides the service default when set
+	PriceImprovementBps float64 `json:"priceImprovementBps,omitempty"`
+}
+
+// Quote is the result of a QuoteRequest
+type Quote struct {
+	// ID is only set when the service has a Recorder
+	ID        string
+	Side      Side
+	Symbol    string
+	Amount    float64
+	Price     float64
+	USDAmount float64
+	Exchanges []string
+	// MidPrice is the consensus mid across every venue that answered
+	MidPrice float64
+	// Fill is the estimated fill on the first winning venue, it is nil when
+	// that venue does not provide order book depth
+	Fill *Fill
+	// Route is set when the first winning venue was priced synthetically
+	Route *Route
+	// Fee is the first winning venue's taker fee, compounded across both
+	// legs for synthetic routes
+	Fee float64
 }
 
 // Buy executes a buy order for the given amount and symbol
 func (o *OrderService) Buy(ctx context.Context, amount float64, symbol string) (float64, []string, error) {
-	bestPrice := 1e18 // Initialize with a very high value
+	quote, err := o.Quote(ctx, QuoteRequest{Side: SideBuy, Symbol: symbol, Amount: amount})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return quote.USDAmount, quote.Exchanges, nil
+}
+
+// Sell executes a sell order for the given amount and symbol
+func (o *OrderService) Sell(ctx context.Context, amount float64, symbol string) (float64, []string, error) {
+	quote, err := o.Quote(ctx, QuoteRequest{Side: SideSell, Symbol: symbol, Amount: amount})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return quote.USDAmount, quote.Exchanges, nil
+}
+
+// Quote finds the best venue for the request and estimates the fill against
+// that venue's book
+func (o *OrderService) Quote(ctx context.Context, req QuoteRequest) (quote *Quote, err error) {
+	start := time.Now()
+	ctx, span := tracer().Start(ctx, "OrderService.Quote", trace.WithAttributes(
+		attribute.String("side", string(req.Side)),
+		attribute.String("symbol", req.Symbol),
+		attribute.Float64("amount", req.Amount),
+	))
+
+	var results []venueResult
+	defer func() {
+		endQuoteSpan(span, quote, results, err)
+		o.observeQuote(quote, results)
+		o.record(ctx, start, req, results, quote, err)
+	}()
+
+	// The request is recorded as sent when its symbol is rejected
+	symbol, err := o.NormalizeSymbol(req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	req.Symbol = symbol
+
+	exchanges, err := o.selectExchanges(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop venues that can't trade the amount and round it to the lot size
+	exchanges, req.Amount, err = o.sizeFor(ctx, exchanges, req.Symbol, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	results = o.fetchFrom(ctx, exchanges, req.Symbol, bookDepth)
+
+	// Find the best price across the venues that answered
+	best, bestExchanges := bestOf(results, req.Side)
+
+	// If no best price was found, return an error
+	if best == nil && allPairsNotFound(results) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSymbol, req.Symbol)
+	}
+	if best == nil {
+		return nil, fmt.Errorf("failed to find best price for %s", req.Symbol)
+	}
+
+	// Keep the preferred venue unless the best price is meaningfully better
+	improvementBps := o.priceImprovementBps
+	if req.PriceImprovementBps > 0 {
+		improvementBps = req.PriceImprovementBps
+	}
+	if preferred := prefer(results, best, req.Side, req.PreferredVenue, improvementBps); preferred != best {
+		best, bestExchanges = preferred, []string{preferred.name}
+	}
+
+	bestPrice := best.price(req.Side)
+	quote = &Quote{
+		Side:      req.Side,
+		Symbol:    req.Symbol,
+		Amount:    req.Amount,
+		Price:     bestPrice,
+		USDAmount: req.Amount * bestPrice,
+		Exchanges: bestExchanges,
+		MidPrice:  consensusMid(results),
+		Route:     best.route,
+		Fee:       takerFee(o.exchange(best.name)),
+	}
+	if best.route != nil {
+		quote.Fee = compoundFee(quote.Fee)
+	}
+
+	// Estimate the fill when the winning venue gave us depth
+	if best.book != nil {
+		fill := estimateFill(bookSide(best.book, req.Side), req.Side, req.Amount, req.MaxSlippageBps, quote.MidPrice)
+		if fill.Partial && req.MaxSlippageBps > 0 && (!req.AllowPartial || fill.FilledAmount == 0) {
+			return nil, fmt.Errorf("%w: %s can fill %g of %g %s within %g bps",
+				ErrInsufficientLiquidity, best.name, fill.FilledAmount, req.Amount, req.Symbol, req.MaxSlippageBps)
+		}
+		if fill.Partial && req.AllowPartial && fill.FilledAmount > 0 {
+			// The quote covers only what the book can fill
+			quote.Amount = fill.FilledAmount
+			quote.USDAmount = fill.USDAmount
+		}
+		quote.Fill = fill
+	}
+
+	return quote, nil
+}
+
+// bestOf returns the venue with the best price for the side along with the
+// names of every venue quoting that same price. Failed venues are skipped
+func bestOf(results []venueResult, side Side) (*venueResult, []string) {
+	var best *venueResult
 	bestExchanges := []string{}
 
-	// Iterate over the exchanges to find the best price
-	for _, exchange := range o.exchanges {
-		price, _, err := exchange.GetPrices(ctx, symbol)
-		if err != nil {
-			log.Printf("failed to get price from exchange: %v", err)
+	for i := range results {
+		r := &results[i]
+		if r.err != nil {
 			continue
 		}
-		// log.Printf("Found price: %v for exchange %s", price, exchange.GetName())
 
-		if price < bestPrice {
-			bestPrice = price
-			bestExchanges = []string{exchange.GetName()}
-		} else if price == bestPrice {
-			bestExchanges = append(bestExchanges, exchange.GetName())
+		price := r.price(side)
+		if best == nil || side.better(price, best.price(side)) {
+			best = r
+			bestExchanges = []string{r.name}
+		} else if price == best.price(side) {
+			bestExchanges = append(bestExchanges, r.name)
 		}
 	}
 
-	// If no best price was found, return an error
-	if bestPrice == 1e18 {
-		return 0, nil, fmt.Errorf("failed to find best price for %s", symbol)
+	return best, bestExchanges
+}
+
+// better reports whether price a is better than price b for the side
+func (s Side) better(a, b float64) bool {
+	if s == SideSell {
+		return a > b
 	}
+	return a < b
+}
 
-	// Calculate the USD amount
-	usdAmount := amount * bestPrice
+// venueResult holds the outcome of querying a single exchange
+type venueResult struct {
+	name    string
+	ask     float64
+	bid     float64
+	book    *exchange.OrderBook
+	route   *Route
+	err     error
+	latency time.Duration
+	// cached is set when the result was served from the cache
+	cached bool
+}
 
-	return usdAmount, bestExchanges, nil
+// price returns the price the client would trade at for the side
+func (r *venueResult) price(side Side) float64 {
+	if side == SideSell {
+		return r.bid
+	}
+	return r.ask
 }
 
-// Sell executes a sell order for the given amount and symbol
-func (o *OrderService) Sell(ctx context.Context, amount float64, symbol string) (float64, []string, error) {
-	bestPrice := 0.0 
-	bestExchanges := []string{}
+// mid returns the midpoint between the venue's bid and ask
+func (r *venueResult) mid() float64 {
+	return (r.ask + r.bid) / 2
+}
 
-	// Iterate over the exchanges to find the best price
-	for _, exchange := range o.exchanges {
-		_, price, err := exchange.GetPrices(ctx, symbol)
-		if err != nil {
-			log.Printf("failed to get price from exchange: %v", err)
-			continue
+// fetch queries every enabled exchange concurrently. Venues that can return
+// order book depth are asked for up to depth levels, the rest only for their
+// top of book. Results are returned in the same order as the exchanges
+func (o *OrderService) fetch(ctx context.Context, symbol string, depth int) []venueResult {
+	return o.fetchFrom(ctx, o.enabled(), symbol, depth)
+}
+
+// fetchFrom is fetch limited to the given exchanges
+func (o *OrderService) fetchFrom(ctx context.Context, exchanges []exchange.Exchange, symbol string, depth int) []venueResult {
+	results := make([]venueResult, len(exchanges))
+
+	var wg sync.WaitGroup
+	for i, ex := range exchanges {
+		wg.Add(1)
+		go func(i int, ex exchange.Exchange) {
+			defer wg.Done()
+			results[i] = o.query(ctx, ex, symbol, depth)
+		}(i, ex)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r.err != nil {
+			o.logger.WarnContext(ctx, "failed to get price from exchange", "exchange", r.name, "symbol", symbol, "error", r.err)
 		}
+	}
+	o.observeVenues(results)
+	o.health.track(results)
+
+	return results
+}
+
+// query fetches prices, and depth when available, from a single exchange.
+// Venues that don't list the symbol against USD are priced through a
+// synthetic route when they can return books for other pairs
+func (o *OrderService) query(ctx context.Context, ex exchange.Exchange, symbol string, depth int) (result venueResult) {
+	result.name = ex.GetName()
+	ctx, span := tracer().Start(ctx, "exchange.GetPrices", trace.WithAttributes(
+		attribute.String("venue", result.name),
+		attribute.String("symbol", symbol),
+	))
+
+	start := time.Now()
+	defer func() {
+		result.latency = time.Since(start)
+		endVenueSpan(span, result)
+	}()
+
+	if cached, ok := o.cache.get(result.name, symbol, depth); ok {
+		cached.cached = true
+		return cached
+	}
+	defer func() { o.cache.put(result.name, symbol, depth, result) }()
 
-		if price > bestPrice {
-			bestPrice = price
-			bestExchanges = []string{exchange.GetName()}
-		} else if price == bestPrice {
-			bestExchanges = append(bestExchanges, exchange.GetName())
+	if bp, ok := ex.(exchange.BookProvider); ok && depth > 0 {
+		book, err := bp.GetOrderBook(ctx, symbol, depth)
+
+		pp, ok := ex.(exchange.PairBookProvider)
+		if errors.Is(err, exchange.ErrPairNotFound) && ok && len(o.intermediates) > 0 {
+			book, result.route, err = o.synthetic(ctx, pp, symbol, depth)
+		}
+		if err != nil {
+			result.err = err
+			return result
 		}
+
+		result.book = book
+		result.ask = book.Asks[0].Price
+		result.bid = book.Bids[0].Price
+		return result
 	}
 
-	// If no best price was found, return an error
-	if bestPrice == 0.0 {
-		return 0, nil, fmt.Errorf("failed to find best price for %s", symbol)
+	result.ask, result.bid, result.err = ex.GetPrices(ctx, symbol)
+	return result
+}
+
+// consensusMid returns the median mid price across every venue that answered
+func consensusMid(results []venueResult) float64 {
+	mids := []float64{}
+	for _, r := range results {
+		if r.err == nil && r.ask > 0 && r.bid > 0 {
+			mids = append(mids, r.mid())
+		}
 	}
 
-	// Calculate the USD amount
-	usdAmount := amount * bestPrice
+	if len(mids) == 0 {
+		return 0
+	}
 
-	return usdAmount, bestExchanges, nil
+	sort.Float64s(mids)
+	n := len(mids)
+	if n%2 == 1 {
+		return mids[n/2]
+	}
+	return (mids[n/2-1] + mids[n/2]) / 2
 }
@@ -0,0 +1,460 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/google/uuid"
+)
+
+// ErrTradingDisabled is returned when orders are placed on a service without
+// an order store
+var ErrTradingDisabled = errors.New("order placement is not enabled")
+
+// PlaceRequest is a request to buy or sell through the venues' private APIs.
+// The quote fields filter and limit routing the same way they do for quotes
+type PlaceRequest struct {
+	QuoteRequest
+	// ClientOrderID is an optional idempotency key, placing an order with a
+	// key that was placed before returns the original order
+	ClientOrderID string
+}
+
+// SetOrderStore sets where orders are kept, orders can't be placed without one
+func (o *OrderService) SetOrderStore(store *OrderStore) {
+	o.orders = store
+}
+
+// SetTraders sets the traders orders are placed with, keyed by venue name.
+// Venues without a trader reject their child orders
+func (o *OrderService) SetTraders(traders ...exchange.Trader) {
+	o.traders = map[string]exchange.Trader{}
+	for _, trader := range traders {
+		o.traders[strings.ToLower(trader.GetName())] = trader
+	}
+}
+
+// PlaceOrder creates an order, splits it across venues by walking their books
+// best price first and places one market child order per venue. Orders whose
+// amount can't be fully placed within the venues' lot sizes are rejected.
+// Orders placed again with the same ClientOrderID return the original order
+// and its children without placing anything
+func (o *OrderService) PlaceOrder(ctx context.Context, req PlaceRequest) (*Order, []*Order, error) {
+	if o.orders == nil {
+		return nil, nil, ErrTradingDisabled
+	}
+
+	if req.ClientOrderID != "" {
+		// Placements with the same key are serialized so retries racing the
+		// original can't both reach the venues
+		unlock := o.orderLocks.lock("client:" + req.ClientOrderID)
+		defer unlock()
+
+		existing, err := o.orders.GetByClientOrderID(req.ClientOrderID)
+		if err == nil {
+			return o.loadOrder(existing.ID)
+		}
+		if !errors.Is(err, ErrOrderNotFound) {
+			return nil, nil, err
+		}
+	}
+
+	symbol, err := o.NormalizeSymbol(req.Symbol)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Symbol = symbol
+
+	exchanges, err := o.selectExchanges(req.QuoteRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exchanges, req.Amount, err = o.sizeFor(ctx, exchanges, req.Symbol, req.Amount); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	parent := newOrder(uuid.NewString(), req.Side, req.Symbol, req.Amount, "received", now)
+	parent.ClientOrderID = req.ClientOrderID
+
+	results := o.fetchFrom(ctx, exchanges, req.Symbol, bookDepth)
+	allocations := allocate(results, req.Side, req.Amount)
+	reason := fmt.Sprintf("failed to find best price for %s", req.Symbol)
+	if len(allocations) > 0 {
+		var leftover float64
+		allocations, leftover = fitLots(allocations, o.lotRules(ctx, exchanges, req.Symbol))
+		reason = fmt.Sprintf("%g %s doesn't fit the lot size of any venue", req.Amount, req.Symbol)
+		if leftover > 0 {
+			// Placing the rest would fill less than was asked for
+			allocations = nil
+			reason = fmt.Sprintf("%g of %g %s doesn't fit the lot size of any venue", leftover, req.Amount, req.Symbol)
+		}
+	}
+	if len(allocations) == 0 {
+		if err := parent.Transition(StatusRejected, reason, now); err != nil {
+			return nil, nil, err
+		}
+		return parent, nil, o.orders.Save(parent)
+	}
+
+	children := make([]*Order, 0, len(allocations))
+	for _, alloc := range allocations {
+		child := newOrder(uuid.NewString(), req.Side, req.Symbol, alloc.amount,
+			fmt.Sprintf("split from %s at %g", parent.ID, alloc.price), now)
+		child.ParentID = parent.ID
+		child.Exchange = alloc.venue
+
+		parent.Children = append(parent.Children, child.ID)
+		children = append(children, child)
+	}
+
+	// Keep the order before any venue sees it so a child that goes live can
+	// always be tracked, lookups wait until placement is done
+	unlock := o.orderLocks.lock(parent.ID)
+	defer unlock()
+	if err := o.orders.Save(append(children, parent)...); err != nil {
+		return nil, nil, err
+	}
+
+	for _, child := range children {
+		if err := o.submit(ctx, child); err != nil {
+			// The child may already be live on its venue, keep it so it can
+			// still be tracked and cancelled
+			o.logger.WarnContext(ctx, "failed to update child order", "order", child.ID, "exchange", child.Exchange, "error", err)
+		}
+		if err := o.orders.Save(child); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := parent.rollUp(children, time.Now().UTC()); err != nil {
+		return nil, nil, err
+	}
+	if err := o.orders.Save(parent); err != nil {
+		return nil, nil, err
+	}
+
+	return parent, children, nil
+}
+
+// submit places a child order with its venue's trader
+func (o *OrderService) submit(ctx context.Context, child *Order) error {
+	trader, ok := o.traders[strings.ToLower(child.Exchange)]
+	if !ok {
+		return child.Transition(StatusRejected, "no trader configured for "+child.Exchange, time.Now().UTC())
+	}
+
+	status, err := trader.PlaceOrder(ctx, exchange.OrderRequest{
+		ClientOrderID: child.ID,
+		Symbol:        child.Symbol,
+		Side:          string(child.Side),
+		Type:          exchange.OrderTypeMarket,
+		Amount:        child.Amount,
+	})
+	if err != nil {
+		return child.Transition(StatusRejected, err.Error(), time.Now().UTC())
+	}
+
+	child.ExchangeOrderID = status.ID
+	return child.apply(status, time.Now().UTC())
+}
+
+// apply moves a child order to match the state its venue reported
+func (o *Order) apply(status *exchange.OrderStatus, at time.Time) error {
+	reason := fmt.Sprintf("%s reported %s", o.Exchange, status.State)
+	filled := status.FilledAmount > o.FilledAmount
+	if status.FilledAmount > 0 {
+		o.FilledAmount = status.FilledAmount
+		o.AvgPrice = status.AvgPrice
+	}
+
+	next := o.Status
+	switch status.State {
+	case exchange.OrderStatePending, exchange.OrderStateOpen:
+		next = StatusRouted
+		if o.FilledAmount > 0 {
+			next = StatusPartiallyFilled
+		}
+	case exchange.OrderStateFilled:
+		next = StatusFilled
+	case exchange.OrderStateCancelled:
+		next = StatusCancelled
+	case exchange.OrderStateExpired:
+		next = StatusExpired
+	case exchange.OrderStateRejected:
+		next = StatusRejected
+	case exchange.OrderStateValidated:
+		// Validate only venues check the order but never submit it
+		next = StatusCancelled
+		reason = fmt.Sprintf("%s validated the order without submitting it", o.Exchange)
+	}
+
+	if next == o.Status && !(next == StatusPartiallyFilled && filled) {
+		return nil
+	}
+	if o.Status == StatusNew && next != StatusRouted && next != StatusRejected {
+		if err := o.Transition(StatusRouted, reason, at); err != nil {
+			return err
+		}
+	}
+	return o.Transition(next, reason, at)
+}
+
+// GetOrder returns an order and its children, refreshing any open child
+// orders from their venues first. Child order IDs return their parent
+func (o *OrderService) GetOrder(ctx context.Context, id string) (*Order, []*Order, error) {
+	if o.orders == nil {
+		return nil, nil, ErrTradingDisabled
+	}
+
+	parent, children, unlock, err := o.lockOrder(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	changed := false
+	for _, child := range children {
+		trader, ok := o.traders[strings.ToLower(child.Exchange)]
+		if !ok || child.Status.Terminal() || child.ExchangeOrderID == "" {
+			continue
+		}
+
+		status, err := trader.GetOrder(ctx, child.ExchangeOrderID)
+		if err != nil {
+			// Serve the last known state when the venue is unavailable
+			continue
+		}
+		if err := child.apply(status, time.Now().UTC()); err != nil {
+			// Keep the last known state when the venue reports one the
+			// order can't move to
+			o.logger.WarnContext(ctx, "failed to update child order", "order", child.ID, "exchange", child.Exchange, "error", err)
+			continue
+		}
+		changed = true
+	}
+
+	if changed {
+		if err := parent.rollUp(children, time.Now().UTC()); err != nil {
+			return nil, nil, err
+		}
+		if err := o.orders.Save(append(children, parent)...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return parent, children, nil
+}
+
+// CancelOrder cancels every open child order of an order
+func (o *OrderService) CancelOrder(ctx context.Context, id string) (*Order, []*Order, error) {
+	if o.orders == nil {
+		return nil, nil, ErrTradingDisabled
+	}
+
+	parent, children, unlock, err := o.lockOrder(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	if parent.Status.Terminal() {
+		return nil, nil, fmt.Errorf("%w: %s is already %s", ErrInvalidTransition, parent.ID, parent.Status)
+	}
+
+	for _, child := range children {
+		if child.Status.Terminal() {
+			continue
+		}
+
+		if trader, ok := o.traders[strings.ToLower(child.Exchange)]; ok && child.ExchangeOrderID != "" {
+			if err := trader.CancelOrder(ctx, child.ExchangeOrderID); err != nil {
+				return nil, nil, fmt.Errorf("failed to cancel %s on %s: %w", child.ID, child.Exchange, err)
+			}
+		}
+		if err := child.Transition(StatusCancelled, "cancelled by client", time.Now().UTC()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := parent.rollUp(children, time.Now().UTC()); err != nil {
+		return nil, nil, err
+	}
+	if err := o.orders.Save(append(children, parent)...); err != nil {
+		return nil, nil, err
+	}
+
+	return parent, children, nil
+}
+
+// lockOrder loads an order like loadOrder while holding the lock of its
+// parent, so updates of one order don't interleave while other orders are
+// free to reach their venues. The returned func releases the lock
+func (o *OrderService) lockOrder(id string) (*Order, []*Order, func(), error) {
+	order, err := o.orders.Get(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	parentID := order.ID
+	if order.ParentID != "" {
+		parentID = order.ParentID
+	}
+
+	unlock := o.orderLocks.lock(parentID)
+	parent, children, err := o.loadOrder(parentID)
+	if err != nil {
+		unlock()
+		return nil, nil, nil, err
+	}
+	return parent, children, unlock, nil
+}
+
+// loadOrder loads an order, or the parent of a child order, with its children
+func (o *OrderService) loadOrder(id string) (*Order, []*Order, error) {
+	parent, err := o.orders.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent.ParentID != "" {
+		if parent, err = o.orders.Get(parent.ParentID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	children := make([]*Order, 0, len(parent.Children))
+	for _, childID := range parent.Children {
+		child, err := o.orders.Get(childID)
+		if err != nil {
+			return nil, nil, err
+		}
+		children = append(children, child)
+	}
+
+	return parent, children, nil
+}
+
+// allocation is the share of an order routed to a single venue
+type allocation struct {
+	venue  string
+	amount float64
+	// price is the venue's best price when the order was split
+	price float64
+}
+
+// allocate splits amount across venues by taking the best priced levels of
+// all books first. Venues without depth offer unlimited size at their top
+// of book. Venues only priced through a synthetic route are left out, a
+// child order can only trade the symbol's USD pair
+func allocate(results []venueResult, side Side, amount float64) []allocation {
+	type level struct {
+		venue string
+		price float64
+		size  float64
+	}
+
+	levels := []level{}
+	for _, r := range results {
+		if r.err != nil || r.route != nil {
+			continue
+		}
+		if r.book == nil {
+			levels = append(levels, level{venue: r.name, price: r.price(side), size: math.Inf(1)})
+			continue
+		}
+		for _, l := range bookSide(r.book, side) {
+			levels = append(levels, level{venue: r.name, price: l.Price, size: l.Size})
+		}
+	}
+	sort.SliceStable(levels, func(i, j int) bool { return side.better(levels[i].price, levels[j].price) })
+
+	allocations := []allocation{}
+	index := map[string]int{}
+	remaining := amount
+	for _, l := range levels {
+		if remaining <= 0 {
+			break
+		}
+
+		size := min(l.size, remaining)
+		remaining -= size
+		if i, ok := index[l.venue]; ok {
+			allocations[i].amount += size
+			continue
+		}
+		index[l.venue] = len(allocations)
+		allocations = append(allocations, allocation{venue: l.venue, amount: size, price: l.price})
+	}
+
+	// Whatever the books couldn't absorb goes to the best venue
+	if remaining > 0 && len(allocations) > 0 {
+		allocations[0].amount += remaining
+	}
+
+	return allocations
+}
+
+// lotRules returns the size rules of each exchange for the symbol by venue
+// name, venues that publish none are left out
+func (o *OrderService) lotRules(ctx context.Context, exchanges []exchange.Exchange, symbol string) map[string]*exchange.SymbolInfo {
+	rules := map[string]*exchange.SymbolInfo{}
+	for _, v := range o.symbolInfo(ctx, exchanges, symbol) {
+		if v.info != nil {
+			rules[v.ex.GetName()] = v.info
+		}
+	}
+	return rules
+}
+
+// fitLots caps each allocation at its venue's maximum size and rounds it down
+// to its lot size, carrying what was cut off to the next venue in price
+// order. Allocations left below their venue's minimum size are carried whole.
+// Whatever is still carried at the end goes to the first venue whose lot size
+// it fits, or is returned as leftover when none does
+func fitLots(allocations []allocation, rules map[string]*exchange.SymbolInfo) ([]allocation, float64) {
+	fit := func(venue string, amount float64) float64 {
+		info := rules[venue]
+		if info == nil {
+			return amount
+		}
+		if info.MaxSize > 0 {
+			amount = min(amount, info.MaxSize)
+		}
+		if info.LotSize > 0 {
+			amount = roundDown(amount, info.LotSize)
+		}
+		if info.MinSize > 0 && amount < info.MinSize {
+			return 0
+		}
+		return amount
+	}
+
+	fitted := []allocation{}
+	carry := 0.0
+	for _, alloc := range allocations {
+		wanted := alloc.amount + carry
+		alloc.amount = fit(alloc.venue, wanted)
+		carry = wanted - alloc.amount
+		if alloc.amount > 0 {
+			fitted = append(fitted, alloc)
+		}
+	}
+
+	// Ignore the floating point noise left by the subtractions
+	if carry < 1e-9 {
+		return fitted, 0
+	}
+	for i := range fitted {
+		wanted := fitted[i].amount + carry
+		if amount := fit(fitted[i].venue, wanted); math.Abs(wanted-amount) < 1e-9 {
+			fitted[i].amount = amount
+			return fitted, 0
+		}
+	}
+	return fitted, carry
+}
@@ -0,0 +1,101 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recorder receives a record of every quote the service produces, including
+// the ones that failed
+type Recorder interface {
+	RecordQuote(record *QuoteRecord) error
+}
+
+// QuoteRecord captures everything that went into a quote for best execution
+// audits
+type QuoteRecord struct {
+	ID        string        `json:"id"`
+	Timestamp time.Time     `json:"timestamp"`
+	Request   QuoteRequest  `json:"request"`
+	Venues    []VenueStatus `json:"venues"`
+	// Exchanges are the venues the quote was routed to
+	Exchanges []string `json:"exchanges"`
+	Price     float64  `json:"price,omitempty"`
+	USDAmount float64  `json:"usdAmount,omitempty"`
+	LatencyMs float64  `json:"latencyMs"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// VenueStatus is the answer a single venue gave for a quote
+type VenueStatus struct {
+	Exchange  string  `json:"exchange"`
+	Status    string  `json:"status"`
+	Bid       float64 `json:"bid,omitempty"`
+	Ask       float64 `json:"ask,omitempty"`
+	Synthetic bool    `json:"synthetic,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Venue statuses
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// SetRecorder sets where quote records are sent, records aren't kept when
+// no recorder is set
+func (o *OrderService) SetRecorder(recorder Recorder) {
+	o.recorder = recorder
+}
+
+// record builds the record of a quote and hands it to the recorder. Failing
+// to record is logged rather than failing the quote
+func (o *OrderService) record(ctx context.Context, start time.Time, req QuoteRequest, results []venueResult, quote *Quote, err error) {
+	if o.recorder == nil {
+		return
+	}
+
+	record := &QuoteRecord{
+		ID:        uuid.NewString(),
+		Timestamp: start.UTC(),
+		Request:   req,
+		Venues:    make([]VenueStatus, 0, len(results)),
+		Exchanges: []string{},
+		LatencyMs: milliseconds(time.Since(start)),
+	}
+
+	for _, r := range results {
+		status := VenueStatus{Exchange: r.name, Status: StatusOK, LatencyMs: milliseconds(r.latency)}
+		if r.err != nil {
+			status.Status = StatusError
+			status.Error = r.err.Error()
+		} else {
+			status.Bid = r.bid
+			status.Ask = r.ask
+			status.Synthetic = r.route != nil
+		}
+		record.Venues = append(record.Venues, status)
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if quote != nil {
+		quote.ID = record.ID
+		record.Exchanges = quote.Exchanges
+		record.Price = quote.Price
+		record.USDAmount = quote.USDAmount
+	}
+
+	if err := o.recorder.RecordQuote(record); err != nil {
+		o.logger.ErrorContext(ctx, "failed to record quote", "quote_id", record.ID, "error", err)
+	}
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
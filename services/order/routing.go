@@ -0,0 +1,91 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+)
+
+// ErrUnknownVenue is returned when a request names a venue the service
+// doesn't route to
+var ErrUnknownVenue = errors.New("unknown venue")
+
+// ErrNoVenues is returned when a request's filters exclude every venue
+var ErrNoVenues = errors.New("no venues left to route to")
+
+// SetPriceImprovementBps sets how much better, in basis points, another
+// venue has to be to win over a request's preferred venue
+func (o *OrderService) SetPriceImprovementBps(bps float64) {
+	o.priceImprovementBps = bps
+}
+
+// selectExchanges returns the enabled exchanges a request may be routed to,
+// keeping the order the service was created with
+func (o *OrderService) selectExchanges(req QuoteRequest) ([]exchange.Exchange, error) {
+	for _, name := range slices.Concat(req.Venues, req.ExcludeVenues, []string{req.PreferredVenue}) {
+		if name != "" && o.exchange(name) == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownVenue, name)
+		}
+	}
+
+	selected := []exchange.Exchange{}
+	for _, ex := range o.enabled() {
+		name := ex.GetName()
+		if len(req.Venues) > 0 && !containsFold(req.Venues, name) {
+			continue
+		}
+		if containsFold(req.ExcludeVenues, name) {
+			continue
+		}
+		selected = append(selected, ex)
+	}
+
+	if len(selected) == 0 {
+		return nil, ErrNoVenues
+	}
+
+	return selected, nil
+}
+
+// exchange returns the exchange with the given name, enabled or not, or nil
+func (o *OrderService) exchange(name string) exchange.Exchange {
+	o.venues.mu.RLock()
+	defer o.venues.mu.RUnlock()
+
+	for _, ex := range o.venues.all {
+		if strings.EqualFold(ex.GetName(), name) {
+			return ex
+		}
+	}
+	return nil
+}
+
+// prefer returns the preferred venue's result instead of the best one unless
+// the best price improves on the preferred price by at least improvementBps
+func prefer(results []venueResult, best *venueResult, side Side, preferred string, improvementBps float64) *venueResult {
+	if preferred == "" || strings.EqualFold(best.name, preferred) {
+		return best
+	}
+
+	for i := range results {
+		r := &results[i]
+		if r.err != nil || !strings.EqualFold(r.name, preferred) {
+			continue
+		}
+
+		// A tie keeps the preferred venue even without a threshold
+		if improvement := bps(side, best.price(side), r.price(side)); improvement <= 0 || improvement < improvementBps {
+			return r
+		}
+	}
+
+	return best
+}
+
+// containsFold reports whether names contains name, ignoring case
+func containsFold(names []string, name string) bool {
+	return slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) })
+}
@@ -0,0 +1,113 @@
+package order
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// ordersBucket holds every order, parents and children, keyed by ID
+	ordersBucket = []byte("orders")
+	// clientOrdersBucket holds the ID of every parent order placed with a
+	// client order ID, keyed by client order ID
+	clientOrdersBucket = []byte("client_orders")
+)
+
+// ErrOrderNotFound is returned when an order isn't in the store
+var ErrOrderNotFound = errors.New("order not found")
+
+// OrderStore persists orders in a local bbolt database
+type OrderStore struct {
+	db *bolt.DB
+}
+
+// OpenOrderStore opens, or creates, the order store at the given path
+func OpenOrderStore(path string) (*OrderStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open order store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{ordersBucket, clientOrdersBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize order store: %w", err)
+	}
+
+	return &OrderStore{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *OrderStore) Close() error {
+	return s.db.Close()
+}
+
+// Save stores orders in a single transaction, so a parent and its children
+// are always consistent
+func (s *OrderStore) Save(orders ...*Order) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(ordersBucket)
+		for _, o := range orders {
+			data, err := json.Marshal(o)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(o.ID), data); err != nil {
+				return err
+			}
+			if o.ParentID == "" && o.ClientOrderID != "" {
+				if err := tx.Bucket(clientOrdersBucket).Put([]byte(o.ClientOrderID), []byte(o.ID)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Get returns the order with the given ID
+func (s *OrderStore) Get(id string) (*Order, error) {
+	var o *Order
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(ordersBucket).Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+		}
+		o = &Order{}
+		return json.Unmarshal(data, o)
+	})
+
+	return o, err
+}
+
+// GetByClientOrderID returns the parent order placed with the given client
+// order ID
+func (s *OrderStore) GetByClientOrderID(clientOrderID string) (*Order, error) {
+	var id string
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(clientOrdersBucket).Get([]byte(clientOrderID))
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrOrderNotFound, clientOrderID)
+		}
+		id = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(id)
+}
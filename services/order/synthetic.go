@@ -0,0 +1,117 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+)
+
+// Route describes how a venue priced a symbol it doesn't list against USD
+// directly, by trading through an intermediate asset
+type Route struct {
+	Synthetic bool   `json:"synthetic"`
+	Via       string `json:"via"`
+	Legs      []Leg  `json:"legs"`
+}
+
+// Leg is the top of book of one of the pairs a synthetic route trades
+type Leg struct {
+	Pair string  `json:"pair"`
+	Bid  float64 `json:"bid"`
+	Ask  float64 `json:"ask"`
+}
+
+// SetIntermediates sets the assets, in order of preference, used to build a
+// synthetic SYMBOL-USD book on venues that don't list the symbol against USD
+func (o *OrderService) SetIntermediates(assets ...string) {
+	o.intermediates = assets
+}
+
+// synthetic builds a SYMBOL-USD book from the SYMBOL-X and X-USD books of the
+// first intermediate X the venue lists both pairs for
+func (o *OrderService) synthetic(ctx context.Context, ex exchange.PairBookProvider, symbol string, depth int) (*exchange.OrderBook, *Route, error) {
+	for _, via := range o.intermediates {
+		if via == symbol {
+			continue
+		}
+
+		first, err := ex.GetPairOrderBook(ctx, symbol, via, depth)
+		if errors.Is(err, exchange.ErrPairNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		second, err := ex.GetPairOrderBook(ctx, via, "USD", depth)
+		if errors.Is(err, exchange.ErrPairNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		book := &exchange.OrderBook{
+			Bids: composeLevels(first.Bids, second.Bids),
+			Asks: composeLevels(first.Asks, second.Asks),
+		}
+		if len(book.Bids) == 0 || len(book.Asks) == 0 {
+			continue
+		}
+
+		route := &Route{
+			Synthetic: true,
+			Via:       via,
+			Legs: []Leg{
+				{Pair: symbol + "-" + via, Bid: first.Bids[0].Price, Ask: first.Asks[0].Price},
+				{Pair: via + "-USD", Bid: second.Bids[0].Price, Ask: second.Asks[0].Price},
+			},
+		}
+		return book, route, nil
+	}
+
+	return nil, nil, fmt.Errorf("no synthetic route for %s-USD: %w", symbol, exchange.ErrPairNotFound)
+}
+
+// composeLevels combines one side of a SYMBOL-X book with the same side of an
+// X-USD book into SYMBOL-USD levels. Trading size at a SYMBOL-X level needs,
+// or yields, size*price of X, which is then traded against the X-USD levels.
+// Both inputs are best first so the composed prices are too
+func composeLevels(first, second []exchange.Level) []exchange.Level {
+	levels := []exchange.Level{}
+	remaining := make([]float64, len(second))
+	for i, level := range second {
+		remaining[i] = level.Size
+	}
+
+	j := 0
+	for _, outer := range first {
+		if outer.Price <= 0 {
+			continue
+		}
+
+		// Amount of X this level trades
+		need := outer.Size * outer.Price
+		for need > 0 && j < len(second) {
+			size := min(need, remaining[j])
+			levels = append(levels, exchange.Level{
+				Price: outer.Price * second[j].Price,
+				Size:  size / outer.Price,
+			})
+			need -= size
+			remaining[j] -= size
+			if remaining[j] <= 0 {
+				j++
+			}
+		}
+	}
+
+	return levels
+}
+
+// compoundFee returns the fee of trading both legs of a synthetic route
+func compoundFee(fee float64) float64 {
+	return 1 - (1-fee)*(1-fee)
+}
@@ -0,0 +1,59 @@
+package order
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer returns the tracer for the service's spans, it is looked up on every
+// call so spans follow the global tracer provider
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/SmMistry/triumph-project/services/order")
+}
+
+// endQuoteSpan records the outcome of a quote on its span and ends it
+func endQuoteSpan(span trace.Span, quote *Quote, results []venueResult, err error) {
+	defer span.End()
+
+	skipped := 0
+	for _, r := range results {
+		if r.err != nil {
+			skipped++
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("venues.queried", len(results)),
+		attribute.Int("venues.skipped", skipped),
+	)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(
+		attribute.StringSlice("venues.selected", quote.Exchanges),
+		attribute.Float64("price", quote.Price),
+	)
+}
+
+// endVenueSpan records the outcome of a venue query on its span and ends it
+func endVenueSpan(span trace.Span, result venueResult) {
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Bool("synthetic", result.route != nil),
+		attribute.Bool("cached", result.cached),
+	)
+	if result.err != nil {
+		span.RecordError(result.err)
+		span.SetStatus(codes.Error, result.err.Error())
+		return
+	}
+	span.SetAttributes(
+		attribute.Float64("bid", result.bid),
+		attribute.Float64("ask", result.ask),
+	)
+}
@@ -0,0 +1,296 @@
+package order
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+)
+
+// Errors returned for requests whose amount or symbol is rejected
+var (
+	// ErrInvalidAmount is returned for amounts that aren't a finite number
+	// greater than 0
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrAmountTooSmall is returned when an amount is below the minimum size
+	// of every venue, or rounds down to nothing
+	ErrAmountTooSmall = errors.New("amount too small")
+	// ErrAmountTooLarge is returned when an amount is above the service limit
+	// or the maximum size of every venue
+	ErrAmountTooLarge = errors.New("amount too large")
+	// ErrInvalidSymbol is returned for empty or malformed symbols
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	// ErrUnknownSymbol is returned when no venue lists the symbol
+	ErrUnknownSymbol = errors.New("unknown symbol")
+)
+
+// symbolPattern is what a symbol must look like once normalized
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9]{1,16}$`)
+
+// defaultRulesTTL is how long venue size rules are reused unless overridden
+const defaultRulesTTL = time.Hour
+
+// symbolRules caches the size rules venues publish for each symbol
+type symbolRules struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[rulesKey]rulesEntry
+}
+
+type rulesKey struct {
+	exchange string
+	symbol   string
+}
+
+type rulesEntry struct {
+	// info is nil when the venue doesn't list the symbol
+	info    *exchange.SymbolInfo
+	expires time.Time
+}
+
+// venueRules pairs an exchange with its size rules for a symbol, info is nil
+// when the venue publishes none
+type venueRules struct {
+	ex   exchange.Exchange
+	info *exchange.SymbolInfo
+}
+
+// SetSymbolAliases maps alternative tickers to the canonical one, e.g. XBT to
+// BTC. Requests are normalized before they reach the venues
+func (o *OrderService) SetSymbolAliases(aliases map[string]string) {
+	o.aliases = make(map[string]string, len(aliases))
+	for alias, symbol := range aliases {
+		o.aliases[strings.ToUpper(alias)] = strings.ToUpper(symbol)
+	}
+}
+
+// SetMaxAmount rejects amounts above max whatever the venues allow, zero
+// disables the limit
+func (o *OrderService) SetMaxAmount(max float64) {
+	o.maxAmount = max
+}
+
+// SetSymbolRulesTTL sets how long a venue's size rules for a symbol are
+// reused, they are kept for an hour by default
+func (o *OrderService) SetSymbolRulesTTL(ttl time.Duration) {
+	o.rules.mu.Lock()
+	defer o.rules.mu.Unlock()
+
+	o.rules.ttl = ttl
+	o.rules.entries = map[rulesKey]rulesEntry{}
+}
+
+// NormalizeSymbol upper cases a symbol, drops a -USD or /USD suffix and
+// resolves aliases. Symbols that aren't 1 to 16 letters or digits are rejected
+// with ErrInvalidSymbol
+func (o *OrderService) NormalizeSymbol(symbol string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(symbol))
+	for _, sep := range []string{"-", "/"} {
+		if base, quote, ok := strings.Cut(normalized, sep); ok && quote == "USD" {
+			normalized = base
+		}
+	}
+
+	if normalized == "" {
+		return "", fmt.Errorf("%w: symbol is required", ErrInvalidSymbol)
+	}
+	if !symbolPattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: %q must be 1 to 16 letters or digits", ErrInvalidSymbol, symbol)
+	}
+	if canonical, ok := o.aliases[normalized]; ok {
+		normalized = canonical
+	}
+
+	return normalized, nil
+}
+
+// sizeFor checks amount against the service limit and the size rules of the
+// exchanges, then rounds it down to a lot size. It returns the exchanges
+// whose lot divides the rounded amount and that accept it along with it,
+// venues that publish no rules accept any amount
+func (o *OrderService) sizeFor(ctx context.Context, exchanges []exchange.Exchange, symbol string, amount float64) ([]exchange.Exchange, float64, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount <= 0 {
+		return nil, 0, fmt.Errorf("%w: must be a finite number greater than 0", ErrInvalidAmount)
+	}
+	if o.maxAmount > 0 && amount > o.maxAmount {
+		return nil, 0, fmt.Errorf("%w: %g %s is above the limit of %g", ErrAmountTooLarge, amount, symbol, o.maxAmount)
+	}
+
+	venues, err := accepting(o.symbolInfo(ctx, exchanges, symbol), symbol, amount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Round to the coarsest lot and keep the venues whose lot divides the
+	// result, lots that don't divide each other can't share one amount.
+	// Finer lots are tried when the venues left reject the rounded amount
+	lots := []float64{}
+	for _, v := range venues {
+		if v.info != nil && v.info.LotSize > 0 && !slices.Contains(lots, v.info.LotSize) {
+			lots = append(lots, v.info.LotSize)
+		}
+	}
+	slices.SortFunc(lots, func(a, b float64) int { return cmp.Compare(b, a) })
+
+	for _, lot := range lots {
+		rounded := roundDown(amount, lot)
+		if rounded == 0 {
+			err = fmt.Errorf("%w: rounds down to nothing with a lot size of %g %s", ErrAmountTooSmall, lot, symbol)
+			continue
+		}
+		fitting, fitErr := accepting(dividing(venues, rounded), symbol, rounded)
+		if fitErr != nil {
+			err = fitErr
+			continue
+		}
+		venues, amount, err = fitting, rounded, nil
+		break
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	selected := make([]exchange.Exchange, len(venues))
+	for i, v := range venues {
+		selected[i] = v.ex
+	}
+	return selected, amount, nil
+}
+
+// dividing returns the venues whose lot size divides amount, venues without
+// a lot size accept any amount
+func dividing(venues []venueRules, amount float64) []venueRules {
+	divided := []venueRules{}
+	for _, v := range venues {
+		if v.info == nil || v.info.LotSize <= 0 || roundDown(amount, v.info.LotSize) == amount {
+			divided = append(divided, v)
+		}
+	}
+	return divided
+}
+
+// accepting returns the venues whose size rules allow amount, or an error
+// naming the closest limit when none does
+func accepting(venues []venueRules, symbol string, amount float64) ([]venueRules, error) {
+	accepted := []venueRules{}
+	minSize, maxSize := math.Inf(1), 0.0
+	for _, v := range venues {
+		switch {
+		case v.info == nil:
+		case v.info.MinSize > 0 && amount < v.info.MinSize:
+			minSize = min(minSize, v.info.MinSize)
+			continue
+		case v.info.MaxSize > 0 && amount > v.info.MaxSize:
+			maxSize = max(maxSize, v.info.MaxSize)
+			continue
+		}
+		accepted = append(accepted, v)
+	}
+
+	switch {
+	case len(accepted) > 0:
+		return accepted, nil
+	case !math.IsInf(minSize, 1):
+		return nil, fmt.Errorf("%w: %g %s is below the minimum size of %g", ErrAmountTooSmall, amount, symbol, minSize)
+	default:
+		return nil, fmt.Errorf("%w: %g %s is above the maximum size of %g", ErrAmountTooLarge, amount, symbol, maxSize)
+	}
+}
+
+// symbolInfo returns the size rules of every exchange for the symbol,
+// fetching the ones that aren't cached concurrently. Venues that fail to
+// answer are given no rules rather than failing the request
+func (o *OrderService) symbolInfo(ctx context.Context, exchanges []exchange.Exchange, symbol string) []venueRules {
+	venues := make([]venueRules, len(exchanges))
+
+	var wg sync.WaitGroup
+	for i, ex := range exchanges {
+		venues[i].ex = ex
+		provider, ok := ex.(exchange.SymbolInfoProvider)
+		if !ok {
+			continue
+		}
+		if info, ok := o.rules.get(ex.GetName(), symbol); ok {
+			venues[i].info = info
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, provider exchange.SymbolInfoProvider) {
+			defer wg.Done()
+
+			info, err := provider.GetSymbolInfo(ctx, symbol)
+			switch {
+			case errors.Is(err, exchange.ErrPairNotFound):
+				o.rules.put(ex.GetName(), symbol, nil)
+			case err != nil:
+				o.logger.WarnContext(ctx, "failed to get symbol rules from exchange", "exchange", ex.GetName(), "symbol", symbol, "error", err)
+			default:
+				venues[i].info = info
+				o.rules.put(ex.GetName(), symbol, info)
+			}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	return venues
+}
+
+// get returns cached rules that haven't expired yet
+func (r *symbolRules) get(exchange, symbol string) (*exchange.SymbolInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := rulesKey{exchange, symbol}
+	entry, ok := r.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(r.entries, key)
+		return nil, false
+	}
+	return entry.info, true
+}
+
+// put caches the rules of a venue for a symbol
+func (r *symbolRules) put(exchange, symbol string, info *exchange.SymbolInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.entries == nil {
+		r.entries = map[rulesKey]rulesEntry{}
+	}
+	ttl := r.ttl
+	if ttl <= 0 {
+		ttl = defaultRulesTTL
+	}
+	r.entries[rulesKey{exchange, symbol}] = rulesEntry{info: info, expires: time.Now().Add(ttl)}
+}
+
+// roundDown rounds amount down to a multiple of lot, trimming the floating
+// point noise the division leaves behind
+func roundDown(amount, lot float64) float64 {
+	steps := math.Floor(amount/lot + 1e-9)
+	scale := math.Pow10(max(0, int(math.Ceil(-math.Log10(lot)-1e-9))))
+	return math.Round(steps*lot*scale) / scale
+}
+
+// allPairsNotFound reports whether every venue answered that it doesn't list
+// the symbol
+func allPairsNotFound(results []venueResult) bool {
+	for _, r := range results {
+		if !errors.Is(r.err, exchange.ErrPairNotFound) {
+			return false
+		}
+	}
+	return len(results) > 0
+}
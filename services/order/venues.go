@@ -0,0 +1,119 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+)
+
+// ErrLastVenue is returned when disabling the only venue still enabled
+var ErrLastVenue = errors.New("at least one venue must stay enabled")
+
+// VenueState is whether a venue is routed to
+type VenueState struct {
+	Name    string
+	Enabled bool
+}
+
+// venueSet holds the exchanges the service was created with and which of
+// them are disabled. Requests take a copy of the enabled exchanges, so they
+// keep using the ones they started with whatever changes meanwhile
+type venueSet struct {
+	mu       sync.RWMutex
+	all      []exchange.Exchange
+	disabled map[string]bool
+}
+
+// enabled returns the enabled exchanges, in the order the service was
+// created with
+func (o *OrderService) enabled() []exchange.Exchange {
+	o.venues.mu.RLock()
+	defer o.venues.mu.RUnlock()
+
+	enabled := make([]exchange.Exchange, 0, len(o.venues.all))
+	for _, ex := range o.venues.all {
+		if !o.venues.disabled[ex.GetName()] {
+			enabled = append(enabled, ex)
+		}
+	}
+	return enabled
+}
+
+// Venues returns every venue the service was created with, enabled or not
+func (o *OrderService) Venues() []VenueState {
+	o.venues.mu.RLock()
+	defer o.venues.mu.RUnlock()
+
+	venues := make([]VenueState, len(o.venues.all))
+	for i, ex := range o.venues.all {
+		venues[i] = VenueState{Name: ex.GetName(), Enabled: !o.venues.disabled[ex.GetName()]}
+	}
+	return venues
+}
+
+// SetVenueEnabled stops or resumes routing to the named venue. Requests
+// already querying a venue that gets disabled finish with it
+func (o *OrderService) SetVenueEnabled(name string, enabled bool) error {
+	o.venues.mu.Lock()
+	defer o.venues.mu.Unlock()
+
+	i := o.venues.index(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownVenue, name)
+	}
+	name = o.venues.all[i].GetName()
+
+	if !enabled && !o.venues.disabled[name] && o.venues.enabledCount() == 1 {
+		return ErrLastVenue
+	}
+
+	if o.venues.disabled == nil {
+		o.venues.disabled = map[string]bool{}
+	}
+	if enabled {
+		delete(o.venues.disabled, name)
+	} else {
+		o.venues.disabled[name] = true
+	}
+	return nil
+}
+
+// ReplaceExchange swaps the exchange with the same name for ex, e.g. to
+// change its settings. Requests already querying the old exchange finish
+// with it
+func (o *OrderService) ReplaceExchange(ex exchange.Exchange) error {
+	o.venues.mu.Lock()
+	defer o.venues.mu.Unlock()
+
+	i := o.venues.index(ex.GetName())
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownVenue, ex.GetName())
+	}
+
+	o.venues.all[i] = ex
+	return nil
+}
+
+// index returns the position of the named exchange, or -1
+func (v *venueSet) index(name string) int {
+	for i, ex := range v.all {
+		if strings.EqualFold(ex.GetName(), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// enabledCount returns how many exchanges are enabled
+func (v *venueSet) enabledCount() int {
+	count := 0
+	for _, ex := range v.all {
+		if !v.disabled[ex.GetName()] {
+			count++
+		}
+	}
+	return count
+}
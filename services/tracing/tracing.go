@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Name of the service in exported traces
+const serviceName = "triumph"
+
+// Supported exporters
+const (
+	// ExporterNone disables tracing, trace context is still propagated
+	ExporterNone = ""
+	// ExporterOTLP sends spans to an OTLP/HTTP collector configured through
+	// the standard OTEL_EXPORTER_OTLP_* environment variables
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans to stdout as JSON
+	ExporterStdout = "stdout"
+	// ExporterFile writes spans to a file as JSON
+	ExporterFile = "file"
+)
+
+// Setup installs the global tracer provider and W3C trace context propagator.
+// path is only used by the file exporter. The returned function flushes and
+// stops the exporter
+func Setup(ctx context.Context, exporter, path string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var spanExporter sdktrace.SpanExporter
+	closeFile := func() error { return nil }
+
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		e, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		spanExporter = e
+	case ExporterStdout:
+		e, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		spanExporter = e
+	case ExporterFile:
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		e, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		spanExporter, closeFile = e, f.Close
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if cerr := closeFile(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+// Middleware starts a server span for every request, continuing the trace
+// from the incoming headers. Handlers reach the span through c.UserContext()
+func Middleware(c *fiber.Ctx) error {
+	// fasthttp normalizes header names, the propagators expect them lowercase
+	headers := propagation.MapCarrier{}
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		headers[strings.ToLower(string(key))] = string(value)
+	})
+	ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headers)
+
+	ctx, span := otel.Tracer("github.com/SmMistry/triumph-project/services/tracing").Start(ctx,
+		c.Method()+" "+c.Path(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Method()),
+			semconv.URLPath(c.Path()),
+			semconv.HTTPRequestBodySize(len(c.Body())),
+		),
+	)
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+
+	// Name the span after the route pattern now that it has been matched
+	route := c.Route().Path
+	span.SetName(c.Method() + " " + route)
+
+	status := c.Response().StatusCode()
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+	} else if err != nil {
+		status = fiber.StatusInternalServerError
+	}
+
+	span.SetAttributes(
+		semconv.HTTPRoute(route),
+		semconv.HTTPResponseStatusCode(status),
+		semconv.HTTPResponseBodySize(len(c.Response().Body())),
+	)
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, utils.StatusMessage(status))
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
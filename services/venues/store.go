@@ -0,0 +1,69 @@
+package venues
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// venuesBucket holds the state of every changed venue keyed by venue name
+var venuesBucket = []byte("venues")
+
+// Store persists venue changes in a local bbolt database
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens, or creates, the store at the given path
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open venue store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(venuesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize venue store: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save stores the state of a venue
+func (s *Store) Save(name string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(venuesBucket).Put([]byte(name), data)
+	})
+}
+
+// Load returns the stored state of every changed venue by name
+func (s *Store) Load() (map[string]State, error) {
+	states := map[string]State{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(venuesBucket).ForEach(func(k, v []byte) error {
+			var state State
+			if err := json.Unmarshal(v, &state); err != nil {
+				return err
+			}
+			states[string(k)] = state
+			return nil
+		})
+	})
+	return states, err
+}
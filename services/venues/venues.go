@@ -0,0 +1,221 @@
+package venues
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+)
+
+var (
+	// ErrNotFound is returned for venues the service wasn't started with
+	ErrNotFound = errors.New("venue not found")
+	// ErrInvalidTimeout is returned when setting a timeout that isn't positive
+	ErrInvalidTimeout = errors.New("timeout must be positive")
+)
+
+// State is what can be changed about a venue at runtime, and who changed it
+// last
+type State struct {
+	Enabled   bool          `json:"enabled"`
+	Timeout   time.Duration `json:"timeout"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+	UpdatedBy string        `json:"updatedBy"`
+}
+
+// Venue is the runtime state of a venue as shown to admins
+type Venue struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Timeout is written as a Go duration, e.g. "3s"
+	Timeout   string     `json:"timeout"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string     `json:"updatedBy,omitempty"`
+}
+
+// Change updates a venue, nil fields are left as they are
+type Change struct {
+	Enabled *bool
+	Timeout *time.Duration
+}
+
+// Builder creates the named venue's exchange with the given timeout
+type Builder func(name string, timeout time.Duration) (exchange.Exchange, error)
+
+// Manager enables, disables and reconfigures the venues of an OrderService
+// while it serves requests
+type Manager struct {
+	orderService *order.OrderService
+	build        Builder
+	logger       *slog.Logger
+
+	// mu serializes changes so the service and the store agree
+	mu     sync.Mutex
+	names  []string
+	states map[string]State
+	store  *Store
+}
+
+// NewManager creates a Manager for the venues of orderService, configured
+// with the given timeouts by venue name. Timeout changes create a new
+// exchange with build
+func NewManager(orderService *order.OrderService, build Builder, timeouts map[string]time.Duration) *Manager {
+	m := &Manager{
+		orderService: orderService,
+		build:        build,
+		logger:       logging.Discard(),
+		states:       map[string]State{},
+	}
+	for _, venue := range orderService.Venues() {
+		m.names = append(m.names, venue.Name)
+		m.states[venue.Name] = State{Enabled: venue.Enabled, Timeout: timeouts[venue.Name]}
+	}
+	return m
+}
+
+// SetLogger sets the logger changes are audited to, nothing is logged by
+// default
+func (m *Manager) SetLogger(logger *slog.Logger) {
+	m.logger = logging.OrDiscard(logger)
+}
+
+// Restore applies the changes kept in store, then keeps every later change
+// there. Stored venues the service wasn't started with are ignored
+func (m *Manager) Restore(ctx context.Context, store *Store) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	saved, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load venue changes: %w", err)
+	}
+
+	for _, name := range m.names {
+		state, ok := saved[name]
+		if !ok {
+			continue
+		}
+		if err := m.apply(name, m.states[name], state); err != nil {
+			m.logger.WarnContext(ctx, "failed to restore venue", "venue", name, "error", err)
+			continue
+		}
+		m.states[name] = state
+		m.logger.InfoContext(ctx, "venue restored", "venue", name, "enabled", state.Enabled,
+			"timeout", state.Timeout.String(), "updated_by", state.UpdatedBy, "updated_at", state.UpdatedAt)
+	}
+
+	m.store = store
+	return nil
+}
+
+// List returns every venue in the order the service was started with
+func (m *Manager) List() []Venue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	venues := make([]Venue, len(m.names))
+	for i, name := range m.names {
+		venues[i] = venue(name, m.states[name])
+	}
+	return venues
+}
+
+// Update applies change to the named venue on behalf of actor, the ID of the
+// API key making it. Every change is logged, and kept when the manager has a
+// store
+func (m *Manager) Update(ctx context.Context, name string, change Change, actor string) (*Venue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, ok := m.find(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	prev := m.states[name]
+	next := prev
+	if change.Enabled != nil {
+		next.Enabled = *change.Enabled
+	}
+	if change.Timeout != nil {
+		if *change.Timeout <= 0 {
+			return nil, ErrInvalidTimeout
+		}
+		next.Timeout = *change.Timeout
+	}
+	next.UpdatedAt = time.Now().UTC()
+	next.UpdatedBy = actor
+
+	if err := m.apply(name, prev, next); err != nil {
+		return nil, err
+	}
+	if m.store != nil {
+		if err := m.store.Save(name, next); err != nil {
+			// Undo the change so the venue doesn't silently revert on restart
+			m.apply(name, next, prev)
+			return nil, fmt.Errorf("failed to save venue change: %w", err)
+		}
+	}
+	m.states[name] = next
+
+	m.logger.InfoContext(ctx, "venue updated",
+		"venue", name,
+		"updated_by", actor,
+		"enabled", next.Enabled,
+		"timeout", next.Timeout.String(),
+		"previous_enabled", prev.Enabled,
+		"previous_timeout", prev.Timeout.String(),
+	)
+
+	v := venue(name, next)
+	return &v, nil
+}
+
+// apply moves the named venue of the service from prev to next
+func (m *Manager) apply(name string, prev, next State) error {
+	if next.Enabled != prev.Enabled {
+		if err := m.orderService.SetVenueEnabled(name, next.Enabled); err != nil {
+			return err
+		}
+	}
+
+	if next.Timeout != prev.Timeout {
+		ex, err := m.build(name, next.Timeout)
+		if err == nil {
+			err = m.orderService.ReplaceExchange(ex)
+		}
+		if err != nil {
+			m.orderService.SetVenueEnabled(name, prev.Enabled)
+			return fmt.Errorf("failed to reconfigure %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// find returns the name of the venue matching name, ignoring case
+func (m *Manager) find(name string) (string, bool) {
+	for _, n := range m.names {
+		if strings.EqualFold(n, name) {
+			return n, true
+		}
+	}
+	return name, false
+}
+
+// venue returns the admin view of a venue's state
+func venue(name string, state State) Venue {
+	v := Venue{Name: name, Enabled: state.Enabled, Timeout: state.Timeout.String(), UpdatedBy: state.UpdatedBy}
+	if !state.UpdatedAt.IsZero() {
+		updatedAt := state.UpdatedAt
+		v.UpdatedAt = &updatedAt
+	}
+	return v
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockBookExchange is a mock exchange that also provides order book depth
+type MockBookExchange struct {
+	Name string
+	Book exchange.OrderBook
+	Err  error
+}
+
+func (m *MockBookExchange) GetPrices(ctx context.Context, symbol string) (float64, float64, error) {
+	if m.Err != nil {
+		return 0, 0, m.Err
+	}
+	return m.Book.Asks[0].Price, m.Book.Bids[0].Price, nil
+}
+
+func (m *MockBookExchange) GetOrderBook(ctx context.Context, symbol string, depth int) (*exchange.OrderBook, error) {
+	return &m.Book, m.Err
+}
+
+func (m *MockBookExchange) GetName() string {
+	return m.Name
+}
+
+func TestBuyHandlerSlippage(t *testing.T) {
+	book := &MockBookExchange{
+		Name: "coinbase",
+		Book: exchange.OrderBook{
+			Bids: []exchange.Level{{Price: 9900, Size: 1}},
+			Asks: []exchange.Level{{Price: 10000, Size: 1}, {Price: 10100, Size: 1}, {Price: 10300, Size: 2}},
+		},
+	}
+	flat := &MockExchange{Name: "kraken", BuyPrice: 10010, SellPrice: 9890}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Fill within the top level",
+			query:          "amount=0.5&symbol=BTC",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"amount":0.5,"coin":"BTC","exchange":["coinbase"],"usdAmount":5000,
+				"fill":{"avgPrice":10000,"filledAmount":0.5,"usdAmount":5000,"slippageBps":0,"priceImpactBps":50.25125628140704,"midPrice":9950,"partial":false}}`,
+		},
+		{
+			name:           "Fill walks the book",
+			query:          "amount=2&symbol=BTC",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"amount":2,"coin":"BTC","exchange":["coinbase"],"usdAmount":20000,
+				"fill":{"avgPrice":10050,"filledAmount":2,"usdAmount":20100,"slippageBps":50,"priceImpactBps":100.50251256281408,"midPrice":9950,"partial":false}}`,
+		},
+		{
+			name:           "Max slippage is rejected",
+			query:          "amount=4&symbol=BTC&maxSlippageBps=150",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"error":"insufficient liquidity: coinbase can fill 2 of 4 BTC within 150 bps","code":"insufficient_liquidity"}`,
+		},
+		{
+			name:           "Max slippage is capped",
+			query:          "amount=4&symbol=BTC&maxSlippageBps=150&allowPartial=true",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"amount":2,"coin":"BTC","exchange":["coinbase"],"usdAmount":20100,
+				"fill":{"avgPrice":10050,"filledAmount":2,"usdAmount":20100,"slippageBps":50,"priceImpactBps":100.50251256281408,"midPrice":9950,"partial":true}}`,
+		},
+		{
+			name:           "Negative max slippage",
+			query:          "amount=1&symbol=BTC&maxSlippageBps=-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid maxSlippageBps","code":"invalid_max_slippage"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			orderController := orders.NewOrderController(order.NewOrderService(book, flat))
+			app.Get("/buy", orderController.BuyHandler)
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/buy?%s", tt.query), nil)
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+		})
+	}
+}
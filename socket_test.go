@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/socket"
+	"github.com/SmMistry/triumph-project/services/alerts"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialSocket serves the WebSocket endpoint on a local port and connects to it
+func dialSocket(t *testing.T, socketController *socket.SocketController) *websocket.Conn {
+	return dialSocketAs(t, socketController, nil, "")
+}
+
+// dialSocketAs is dialSocket behind an Authenticator, connecting with token
+func dialSocketAs(t *testing.T, socketController *socket.SocketController, authenticator *auth.Authenticator, token string) *websocket.Conn {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	if authenticator != nil {
+		app.Use(authenticator.RequireStream(auth.ScopeQuote))
+	}
+	app.Get("/v1/ws", socketController.Handler)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(ln)
+	t.Cleanup(func() { app.Shutdown() })
+
+	url := "ws://" + ln.Addr().String() + "/v1/ws"
+	if token != "" {
+		url += "?" + auth.KeyQuery + "=" + token
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readResponse reads the next message of the given type, skipping others
+func readResponse(t *testing.T, conn *websocket.Conn, messageType string, data any) socket.Response {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var envelope struct {
+			socket.Response
+			Data any `json:"data"`
+		}
+		envelope.Data = data
+		require.NoError(t, conn.ReadJSON(&envelope))
+		if envelope.Type == messageType {
+			assert.Equal(t, socket.Version, envelope.V)
+			return envelope.Response
+		}
+	}
+}
+
+func TestSocket(t *testing.T) {
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990}
+	orderService := order.NewOrderService(coinbase)
+	poller := bbo.NewPoller(orderService, time.Hour)
+	conn := dialSocket(t, socket.NewSocketController(orderService, poller, 2))
+
+	var welcome socket.Welcome
+	readResponse(t, conn, socket.TypeWelcome, &welcome)
+	assert.Equal(t, socket.Version, welcome.Version)
+	assert.Equal(t, 2, welcome.MaxSymbols)
+
+	// Pings are answered with the same id
+	require.NoError(t, conn.WriteJSON(socket.Request{V: 1, Type: socket.TypePing, ID: "p"}))
+	assert.Equal(t, "p", readResponse(t, conn, socket.TypePong, nil).ID)
+
+	// Unknown versions and types are rejected
+	require.NoError(t, conn.WriteJSON(socket.Request{V: 2, Type: socket.TypePing, ID: "v"}))
+	assert.Contains(t, readResponse(t, conn, socket.TypeError, nil).Error, "unsupported version 2")
+	require.NoError(t, conn.WriteJSON(socket.Request{Type: "nope", ID: "t"}))
+	assert.Equal(t, "t", readResponse(t, conn, socket.TypeError, nil).ID)
+
+	// One-off quotes share the socket
+	require.NoError(t, conn.WriteJSON(socket.Request{Type: socket.TypeQuote, ID: "q", Quote: &order.QuoteRequest{
+		Side: order.SideBuy, Symbol: "BTC", Amount: 2,
+	}}))
+	var quote socket.QuoteResult
+	assert.Equal(t, "q", readResponse(t, conn, socket.TypeQuote, &quote).ID)
+	assert.Equal(t, 20000.0, quote.USDAmount)
+	assert.Equal(t, []string{"coinbase"}, quote.Exchanges)
+
+	// Subscriptions are capped per connection
+	require.NoError(t, conn.WriteJSON(socket.Request{Type: socket.TypeSubscribe, ID: "s", Symbols: []string{"BTC", "ETH", "SOL"}}))
+	assert.Contains(t, readResponse(t, conn, socket.TypeError, nil).Error, "at most 2 symbols")
+
+	// Invalid symbols are rejected with the REST code
+	require.NoError(t, conn.WriteJSON(socket.Request{Type: socket.TypeSubscribe, ID: "i", Symbols: []string{"BTC", "$$$"}}))
+	assert.Equal(t, "invalid_symbol", readResponse(t, conn, socket.TypeError, nil).Code)
+	assert.Empty(t, poller.Symbols())
+
+	// Symbols are normalized, so both spellings share one feed
+	var subscriptions socket.Subscriptions
+	require.NoError(t, conn.WriteJSON(socket.Request{Type: socket.TypeSubscribe, ID: "s", Symbols: []string{"btc", "BTC-USD"}}))
+	readResponse(t, conn, socket.TypeSubscribed, &subscriptions)
+	assert.Equal(t, []string{"BTC"}, subscriptions.Symbols)
+	assert.Eventually(t, func() bool { return len(poller.Symbols()) == 1 }, time.Second, 10*time.Millisecond)
+
+	poller.Poll(context.Background())
+	var event bbo.Event
+	readResponse(t, conn, socket.TypeBBO, &event)
+	assert.Equal(t, uint64(1), event.Sequence)
+	assert.Equal(t, 10000.0, event.BestAsk.Price)
+
+	// Alerts trigger once their condition starts holding
+	require.NoError(t, conn.WriteJSON(socket.Request{Type: socket.TypeAddAlert, ID: "a", Alert: &socket.Alert{Condition: alerts.Condition{
+		Symbol: "btc", Metric: alerts.MetricBestAsk, Operator: alerts.OperatorBelow, Threshold: 9000,
+	}}}))
+	var added socket.Alert
+	readResponse(t, conn, socket.TypeAlertAdded, &added)
+	assert.NotEmpty(t, added.ID)
+	assert.Equal(t, "BTC", added.Symbol)
+
+	coinbase.BuyPrice = 8900
+	poller.Poll(context.Background())
+	var trigger socket.AlertTrigger
+	readResponse(t, conn, socket.TypeAlertTriggered, &trigger)
+	assert.Equal(t, added.ID, trigger.Alert.ID)
+	assert.Equal(t, 8900.0, trigger.Value)
+	assert.Equal(t, uint64(2), trigger.Sequence)
+
+	// The alert keeps BTC polled after unsubscribing, until it is removed
+	require.NoError(t, conn.WriteJSON(socket.Request{Type: socket.TypeUnsubscribe, ID: "u", Symbols: []string{"btc"}}))
+	readResponse(t, conn, socket.TypeUnsubscribed, &subscriptions)
+	assert.Empty(t, subscriptions.Symbols)
+	assert.Equal(t, []string{"BTC"}, poller.Symbols())
+
+	require.NoError(t, conn.WriteJSON(socket.Request{Type: socket.TypeRemoveAlert, ID: "r", AlertID: added.ID}))
+	readResponse(t, conn, socket.TypeAlertRemoved, nil)
+	assert.Empty(t, poller.Symbols())
+}
+
+func TestSocketQuoteLimits(t *testing.T) {
+	store, err := auth.Open(filepath.Join(t.TempDir(), "keys.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	// Connecting counts as one request, leaving one quote for each key
+	_, rateToken, err := store.Create("rate", []auth.Scope{auth.ScopeQuote}, 2, 0)
+	require.NoError(t, err)
+	_, quotaToken, err := store.Create("quota", []auth.Scope{auth.ScopeQuote}, 0, 2)
+	require.NoError(t, err)
+
+	orderService := order.NewOrderService(&MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990})
+	authenticator := auth.NewAuthenticator(store)
+	socketController := socket.NewSocketController(orderService, bbo.NewPoller(orderService, time.Hour), 2)
+	socketController.SetAuthenticator(authenticator)
+
+	for token, code := range map[string]string{rateToken: "rate_limited", quotaToken: "quota_exceeded"} {
+		conn := dialSocketAs(t, socketController, authenticator, token)
+		readResponse(t, conn, socket.TypeWelcome, nil)
+
+		quote := socket.Request{Type: socket.TypeQuote, ID: "q", Quote: &order.QuoteRequest{Side: order.SideBuy, Symbol: "BTC", Amount: 1}}
+		require.NoError(t, conn.WriteJSON(quote))
+		readResponse(t, conn, socket.TypeQuote, nil)
+
+		require.NoError(t, conn.WriteJSON(quote))
+		failed := readResponse(t, conn, socket.TypeError, nil)
+		assert.Equal(t, "q", failed.ID)
+		assert.Equal(t, code, failed.Code)
+	}
+}
+
+func TestSocketShutdown(t *testing.T) {
+	orderService := order.NewOrderService(&MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990})
+	socketController := socket.NewSocketController(orderService, bbo.NewPoller(orderService, time.Hour), 2)
+	conn := dialSocket(t, socketController)
+	readResponse(t, conn, socket.TypeWelcome, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, socketController.Shutdown(ctx))
+
+	// Open connections are told the server is going away
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+
+	// and so are connections opened afterwards
+	late := dialSocket(t, socketController)
+	late.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = late.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+}
+
+func TestSocketAlertIDs(t *testing.T) {
+	orderService := order.NewOrderService(&MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990})
+	conn := dialSocket(t, socket.NewSocketController(orderService, bbo.NewPoller(orderService, time.Hour), 2))
+	readResponse(t, conn, socket.TypeWelcome, nil)
+
+	condition := alerts.Condition{Symbol: "BTC", Metric: alerts.MetricBestAsk, Operator: alerts.OperatorBelow, Threshold: 9000}
+	add := func(id string) (socket.Alert, socket.Response) {
+		require.NoError(t, conn.WriteJSON(socket.Request{Type: socket.TypeAddAlert, ID: "a", Alert: &socket.Alert{ID: id, Condition: condition}}))
+		var added socket.Alert
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var envelope struct {
+			socket.Response
+			Data *socket.Alert `json:"data"`
+		}
+		envelope.Data = &added
+		require.NoError(t, conn.ReadJSON(&envelope))
+		return added, envelope.Response
+	}
+
+	// Generated IDs skip the ones clients chose
+	added, _ := add("1")
+	assert.Equal(t, "1", added.ID)
+	added, _ = add("")
+	assert.Equal(t, "2", added.ID)
+
+	// Clients can't reuse an ID, generated or not
+	_, failed := add("2")
+	assert.Equal(t, socket.TypeError, failed.Type)
+	assert.Equal(t, "alert 2 already exists", failed.Error)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockPairExchange is a mock exchange listing books for arbitrary pairs,
+// keyed by BASE-QUOTE
+type MockPairExchange struct {
+	Name  string
+	Books map[string]exchange.OrderBook
+}
+
+func (m *MockPairExchange) GetPrices(ctx context.Context, symbol string) (float64, float64, error) {
+	book, err := m.GetOrderBook(ctx, symbol, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	return book.Asks[0].Price, book.Bids[0].Price, nil
+}
+
+func (m *MockPairExchange) GetOrderBook(ctx context.Context, symbol string, depth int) (*exchange.OrderBook, error) {
+	return m.GetPairOrderBook(ctx, symbol, "USD", depth)
+}
+
+func (m *MockPairExchange) GetPairOrderBook(ctx context.Context, base, quote string, depth int) (*exchange.OrderBook, error) {
+	book, ok := m.Books[base+"-"+quote]
+	if !ok {
+		return nil, fmt.Errorf("%s %s-%s: %w", m.Name, base, quote, exchange.ErrPairNotFound)
+	}
+	return &book, nil
+}
+
+func (m *MockPairExchange) GetName() string {
+	return m.Name
+}
+
+func TestBuyHandlerSyntheticRoute(t *testing.T) {
+	// Kraken only lists XYZ against BTC, Coinbase doesn't list it at all
+	kraken := &MockPairExchange{Name: "kraken", Books: map[string]exchange.OrderBook{
+		"XYZ-BTC": {
+			Bids: []exchange.Level{{Price: 0.0001, Size: 1000}},
+			Asks: []exchange.Level{{Price: 0.0002, Size: 1000}, {Price: 0.0003, Size: 1000}},
+		},
+		"BTC-USD": {
+			Bids: []exchange.Level{{Price: 10000, Size: 1}},
+			Asks: []exchange.Level{{Price: 20000, Size: 0.1}, {Price: 40000, Size: 1}},
+		},
+	}}
+	coinbase := &MockPairExchange{Name: "coinbase", Books: map[string]exchange.OrderBook{}}
+
+	orderService := order.NewOrderService(coinbase, kraken)
+	orderService.SetIntermediates("USDT", "BTC")
+
+	app := fiber.New()
+	orderController := orders.NewOrderController(orderService)
+	app.Get("/buy", orderController.BuyHandler)
+
+	// The first 500 XYZ need 0.1 BTC bought at 20000, the next 500 need
+	// another 0.1 BTC bought at 40000
+	req := httptest.NewRequest(http.MethodGet, "/buy?amount=1000&symbol=XYZ", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":1000,"coin":"XYZ","exchange":["kraken"],"usdAmount":4000,
+		"fill":{"avgPrice":6,"filledAmount":1000,"usdAmount":6000,"slippageBps":5000,"priceImpactBps":14000,"midPrice":2.5,"partial":false},
+		"route":{"synthetic":true,"via":"BTC","legs":[
+			{"pair":"XYZ-BTC","bid":0.0001,"ask":0.0002},
+			{"pair":"BTC-USD","bid":10000,"ask":20000}
+		]}}`, string(body))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/SmMistry/triumph-project/services/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	orderService := order.NewOrderService(
+		&MockBookExchange{Name: "coinbase", Book: exchange.OrderBook{
+			Bids: []exchange.Level{{Price: 9990, Size: 2}},
+			Asks: []exchange.Level{{Price: 10010, Size: 3}},
+		}},
+		&MockExchange{Name: "kraken", BuyPrice: 10005, SellPrice: 9985},
+	)
+
+	app := fiber.New()
+	app.Use(tracing.Middleware)
+	app.Get("/buy", orders.NewOrderController(orderService).BuyHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/buy?symbol=BTC&amount=1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	spans := map[string][]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	require.Len(t, spans["GET /buy"], 1)
+	require.Len(t, spans["OrderService.Quote"], 1)
+	require.Len(t, spans["exchange.GetPrices"], 2)
+
+	// The request continues the incoming trace
+	server := spans["GET /buy"][0]
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Contains(t, server.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+
+	// Selection is a child of the request and every venue a child of selection
+	quote := spans["OrderService.Quote"][0]
+	assert.Equal(t, server.SpanContext().SpanID(), quote.Parent().SpanID())
+	assert.Contains(t, quote.Attributes(), attribute.String("symbol", "BTC"))
+	assert.Contains(t, quote.Attributes(), attribute.StringSlice("venues.selected", []string{"kraken"}))
+
+	venues := []string{}
+	for _, span := range spans["exchange.GetPrices"] {
+		assert.Equal(t, quote.SpanContext().SpanID(), span.Parent().SpanID())
+		for _, attr := range span.Attributes() {
+			if attr.Key == "venue" {
+				venues = append(venues, attr.Value.AsString())
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"coinbase", "kraken"}, venues)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/services/exchange"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// krakenStandIn is a local Kraken private API that checks API-Sign the way
+// Kraken documents it and that nonces keep increasing
+func krakenStandIn(t *testing.T, key string, secret []byte) *httptest.Server {
+	lastNonce := int64(0)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+
+		digest := sha256.Sum256([]byte(form.Get("nonce") + string(body)))
+		mac := hmac.New(sha512.New, secret)
+		mac.Write([]byte(r.URL.Path))
+		mac.Write(digest[:])
+		expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+		nonce, _ := strconv.ParseInt(form.Get("nonce"), 10, 64)
+		if r.Header.Get("API-Key") != key || r.Header.Get("API-Sign") != expected {
+			json.NewEncoder(w).Encode(map[string]any{"error": []string{"EAPI:Invalid signature"}})
+			return
+		}
+		if nonce <= lastNonce {
+			json.NewEncoder(w).Encode(map[string]any{"error": []string{"EAPI:Invalid nonce"}})
+			return
+		}
+		lastNonce = nonce
+
+		switch r.URL.Path {
+		case "/0/private/AddOrder":
+			result := map[string]any{"descr": map[string]string{"order": "buy 0.5 BTCUSD @ market"}}
+			if form.Get("validate") != "true" {
+				result["txid"] = []string{"OABC12-DEF34-GHI56"}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"error": []string{}, "result": result})
+		case "/0/private/QueryOrders":
+			json.NewEncoder(w).Encode(map[string]any{"error": []string{}, "result": map[string]any{
+				form.Get("txid"): map[string]any{
+					"status": "closed", "vol_exec": "0.5", "price": "10000.5",
+					"descr": map[string]string{"pair": "BTCUSD", "type": "buy"},
+				},
+			}})
+		case "/0/private/CancelOrder":
+			json.NewEncoder(w).Encode(map[string]any{"error": []string{}, "result": map[string]int{"count": 1}})
+		}
+	}))
+}
+
+func TestKrakenTrader(t *testing.T) {
+	secret := []byte("kraken test secret")
+	server := krakenStandIn(t, "kraken-key", secret)
+	defer server.Close()
+
+	trader := &exchange.KrakenTrader{BaseURL: server.URL, Key: "kraken-key", Secret: base64.StdEncoding.EncodeToString(secret)}
+	order := exchange.OrderRequest{Symbol: "BTC", Side: "buy", Type: exchange.OrderTypeMarket, Amount: 0.5}
+
+	// Orders are only validated until the trader is switched to live
+	status, err := trader.PlaceOrder(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateValidated, status.State)
+	assert.Empty(t, status.ID)
+
+	trader.Live = true
+	status, err = trader.PlaceOrder(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, exchange.OrderStatePending, status.State)
+	assert.Equal(t, "OABC12-DEF34-GHI56", status.ID)
+
+	status, err = trader.GetOrder(context.Background(), status.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &exchange.OrderStatus{
+		ID: "OABC12-DEF34-GHI56", Exchange: "kraken", Symbol: "BTC", Side: "buy",
+		State: exchange.OrderStateFilled, FilledAmount: 0.5, AvgPrice: 10000.5,
+	}, status)
+
+	assert.NoError(t, trader.CancelOrder(context.Background(), status.ID))
+
+	// A wrong secret is rejected by the stand-in
+	trader.Secret = base64.StdEncoding.EncodeToString([]byte("wrong"))
+	_, err = trader.PlaceOrder(context.Background(), order)
+	assert.ErrorIs(t, err, exchange.ErrOrderRejected)
+	assert.ErrorContains(t, err, "EAPI:Invalid signature")
+}
+
+// coinbaseStandIn is a local Coinbase Advanced Trade API that accepts
+// requests carrying either a JWT signed by publicKey or a legacy HMAC
+// signature made with hmacSecret
+func coinbaseStandIn(t *testing.T, publicKey *ecdsa.PublicKey, hmacSecret string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		host := strings.TrimPrefix(server.URL, "http://")
+
+		authorized := false
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			parts := append(strings.Split(token, "."), "", "")
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			signature = append(signature, make([]byte, 64)...)
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			rs, ss := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:64])
+
+			var claims struct {
+				Sub string `json:"sub"`
+				URI string `json:"uri"`
+			}
+			payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			json.Unmarshal(payload, &claims)
+
+			authorized = ecdsa.Verify(publicKey, digest[:], rs, ss) &&
+				claims.Sub == "organizations/test/apiKeys/key" &&
+				claims.URI == r.Method+" "+host+r.URL.Path
+		} else {
+			mac := hmac.New(sha256.New, []byte(hmacSecret))
+			mac.Write([]byte(r.Header.Get("CB-ACCESS-TIMESTAMP") + r.Method + r.URL.Path + string(body)))
+			authorized = r.Header.Get("CB-ACCESS-KEY") == "legacy-key" &&
+				r.Header.Get("CB-ACCESS-SIGN") == hex.EncodeToString(mac.Sum(nil))
+		}
+		if !authorized {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "UNAUTHENTICATED", "message": "invalid signature"})
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/api/v3/brokerage/orders":
+			var order struct {
+				ClientOrderID string `json:"client_order_id"`
+				ProductID     string `json:"product_id"`
+			}
+			json.Unmarshal(body, &order)
+			json.NewEncoder(w).Encode(map[string]any{"success": true, "success_response": map[string]string{
+				"order_id": "cb-order-1", "client_order_id": order.ClientOrderID, "product_id": order.ProductID,
+			}})
+		case r.URL.Path == "/api/v3/brokerage/orders/batch_cancel":
+			json.NewEncoder(w).Encode(map[string]any{"results": []map[string]any{{"success": true, "order_id": "cb-order-1"}}})
+		case strings.HasPrefix(r.URL.Path, "/api/v3/brokerage/orders/historical/"):
+			json.NewEncoder(w).Encode(map[string]any{"order": map[string]string{
+				"order_id": "cb-order-1", "client_order_id": "client-1", "product_id": "ETH-USD", "side": "SELL",
+				"status": "OPEN", "filled_size": "0.25", "average_filled_price": "3000",
+			}})
+		}
+	}))
+	return server
+}
+
+func TestCoinbaseTrader(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+
+	server := coinbaseStandIn(t, &key.PublicKey, "legacy-secret")
+	defer server.Close()
+
+	order := exchange.OrderRequest{ClientOrderID: "client-1", Symbol: "ETH", Side: "sell", Type: exchange.OrderTypeLimit, Amount: 1, LimitPrice: 3000}
+
+	for _, trader := range []*exchange.CoinbaseTrader{
+		// CDP keys are signed with a JWT, env vars usually escape the newlines
+		{BaseURL: server.URL, KeyName: "organizations/test/apiKeys/key", Secret: strings.ReplaceAll(keyPEM, "\n", `\n`)},
+		// Legacy keys are signed with HMAC
+		{BaseURL: server.URL, KeyName: "legacy-key", Secret: "legacy-secret"},
+	} {
+		status, err := trader.PlaceOrder(context.Background(), order)
+		require.NoError(t, err)
+		assert.Equal(t, "cb-order-1", status.ID)
+		assert.Equal(t, "client-1", status.ClientOrderID)
+
+		status, err = trader.GetOrder(context.Background(), status.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &exchange.OrderStatus{
+			ID: "cb-order-1", ClientOrderID: "client-1", Exchange: "coinbase", Symbol: "ETH", Side: "sell",
+			State: exchange.OrderStateOpen, FilledAmount: 0.25, AvgPrice: 3000,
+		}, status)
+
+		assert.NoError(t, trader.CancelOrder(context.Background(), status.ID))
+	}
+
+	// A key the stand-in doesn't know is rejected
+	trader := &exchange.CoinbaseTrader{BaseURL: server.URL, KeyName: "legacy-key", Secret: "wrong"}
+	_, err = trader.PlaceOrder(context.Background(), order)
+	assert.ErrorContains(t, err, "failed with 401: UNAUTHENTICATED invalid signature")
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/markets"
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/controllers/rpc"
+	quotev1 "github.com/SmMistry/triumph-project/proto/quote/v1"
+	"github.com/SmMistry/triumph-project/services/bbo"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MockRulesExchange is a MockExchange publishing size rules, Info is nil for
+// symbols it doesn't list
+type MockRulesExchange struct {
+	MockExchange
+	Info  *exchange.SymbolInfo
+	Calls atomic.Int32
+}
+
+func (m *MockRulesExchange) GetSymbolInfo(ctx context.Context, symbol string) (*exchange.SymbolInfo, error) {
+	m.Calls.Add(1)
+	if m.Info == nil {
+		return nil, exchange.ErrPairNotFound
+	}
+	return m.Info, nil
+}
+
+func TestQuoteValidation(t *testing.T) {
+	coinbase := &MockRulesExchange{
+		MockExchange: MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990},
+		Info:         &exchange.SymbolInfo{MinSize: 0.5, MaxSize: 100, LotSize: 0.001},
+	}
+	kraken := &MockRulesExchange{
+		MockExchange: MockExchange{Name: "kraken", BuyPrice: 10010, SellPrice: 9995},
+		Info:         &exchange.SymbolInfo{MinSize: 0.01, LotSize: 0.01},
+	}
+	orderService := order.NewOrderService(coinbase, kraken)
+	orderService.SetSymbolAliases(map[string]string{"xbt": "btc"})
+	orderService.SetMaxAmount(1000)
+
+	app := fiber.New()
+	app.Get("/buy", orders.NewOrderController(orderService).BuyHandler)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Lowercase symbol",
+			query:          "amount=2&symbol=btc",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":2,"coin":"BTC","exchange":["coinbase"],"usdAmount":20000}`,
+		},
+		{
+			name:           "Alias with a USD suffix",
+			query:          "amount=2&symbol=xbt-usd",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":2,"coin":"BTC","exchange":["coinbase"],"usdAmount":20000}`,
+		},
+		{
+			name:           "Rounded down to the coarsest lot",
+			query:          "amount=1.23456&symbol=BTC",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":1.23,"coin":"BTC","exchange":["coinbase"],"usdAmount":12300}`,
+		},
+		{
+			name:           "Below one venue's minimum",
+			query:          "amount=0.256&symbol=BTC",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":0.25,"coin":"BTC","exchange":["kraken"],"usdAmount":2502.5}`,
+		},
+		{
+			name:           "Below every venue's minimum",
+			query:          "amount=0.001&symbol=BTC",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"amount too small: 0.001 BTC is below the minimum size of 0.01","code":"amount_too_small"}`,
+		},
+		{
+			name:           "Above the service limit",
+			query:          "amount=1e300&symbol=BTC",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"amount too large: 1e+300 BTC is above the limit of 1000","code":"amount_too_large"}`,
+		},
+		{
+			name:           "Negative amount",
+			query:          "amount=-1&symbol=BTC",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid amount: must be a finite number greater than 0","code":"invalid_amount"}`,
+		},
+		{
+			name:           "NaN amount",
+			query:          "amount=NaN&symbol=BTC",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid amount: must be a finite number greater than 0","code":"invalid_amount"}`,
+		},
+		{
+			name:           "Infinite amount",
+			query:          "amount=Inf&symbol=BTC",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid amount: must be a finite number greater than 0","code":"invalid_amount"}`,
+		},
+		{
+			name:           "Missing symbol",
+			query:          "amount=1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid symbol: symbol is required","code":"invalid_symbol"}`,
+		},
+		{
+			name:           "Path characters in the symbol",
+			query:          "amount=1&symbol=" + url.QueryEscape("BTC/../x"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid symbol: \"BTC/../x\" must be 1 to 16 letters or digits","code":"invalid_symbol"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/buy?"+tt.query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+		})
+	}
+
+	// Rules are fetched once per venue and symbol
+	assert.Equal(t, int32(1), coinbase.Calls.Load())
+	assert.Equal(t, int32(1), kraken.Calls.Load())
+}
+
+func TestQuoteLotsThatDontDivide(t *testing.T) {
+	coinbase := &MockRulesExchange{
+		MockExchange: MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9990},
+		Info:         &exchange.SymbolInfo{MinSize: 0.604, LotSize: 0.003},
+	}
+	kraken := &MockRulesExchange{
+		MockExchange: MockExchange{Name: "kraken", BuyPrice: 9990, SellPrice: 9980},
+		Info:         &exchange.SymbolInfo{LotSize: 0.002},
+	}
+	orderService := order.NewOrderService(coinbase, kraken)
+
+	app := fiber.New()
+	app.Get("/buy", orders.NewOrderController(orderService).BuyHandler)
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedBody string
+	}{
+		{
+			name:         "Multiple of both lots",
+			query:        "amount=1.2&symbol=BTC",
+			expectedBody: `{"amount":1.2,"coin":"BTC","exchange":["kraken"],"usdAmount":11988}`,
+		},
+		{
+			name:         "Venue whose lot doesn't divide the rounded amount is dropped",
+			query:        "amount=0.904&symbol=BTC",
+			expectedBody: `{"amount":0.903,"coin":"BTC","exchange":["coinbase"],"usdAmount":9030}`,
+		},
+		{
+			name:         "Finer lot when the coarser rounding fits no venue",
+			query:        "amount=0.604&symbol=BTC",
+			expectedBody: `{"amount":0.604,"coin":"BTC","exchange":["kraken"],"usdAmount":6033.96}`,
+		},
+		{
+			name:         "Below every lot",
+			query:        "amount=0.001&symbol=BTC",
+			expectedBody: `{"error":"amount too small: rounds down to nothing with a lot size of 0.002 BTC","code":"amount_too_small"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/buy?"+tt.query, nil))
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+		})
+	}
+}
+
+func TestUnknownSymbol(t *testing.T) {
+	orderService := order.NewOrderService(
+		&MockExchange{Name: "coinbase", Err: exchange.ErrPairNotFound},
+		&MockRulesExchange{MockExchange: MockExchange{Name: "kraken", Err: exchange.ErrPairNotFound}},
+	)
+
+	app := fiber.New()
+	app.Get("/buy", orders.NewOrderController(orderService).BuyHandler)
+	app.Get("/v1/markets/:symbol", markets.NewMarketController(orderService).MarketHandler)
+
+	for _, path := range []string{"/buy?amount=1&symbol=nope", "/v1/markets/nope"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "unknown_symbol", body["code"], path)
+		assert.Equal(t, "unknown symbol: NOPE", body["error"], path)
+	}
+}
+
+func TestGRPCErrorCodes(t *testing.T) {
+	orderService := order.NewOrderService(&MockExchange{Name: "kraken", BuyPrice: 10005, SellPrice: 9985})
+	conn := dialGRPC(t, rpc.NewQuoteServer(orderService, bbo.NewPoller(orderService, time.Hour), 2))
+	client := quotev1.NewQuoteServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reason := func(err error) string {
+		st := status.Convert(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		require.Len(t, st.Details(), 1)
+		return st.Details()[0].(*errdetails.ErrorInfo).Reason
+	}
+
+	for code, req := range map[string]*quotev1.GetQuoteRequest{
+		"invalid_symbol":            {Side: quotev1.Side_SIDE_BUY, Symbol: "B T C", Amount: 1},
+		"invalid_side":              {Symbol: "BTC", Amount: 1},
+		"invalid_max_slippage":      {Side: quotev1.Side_SIDE_BUY, Symbol: "BTC", Amount: 1, MaxSlippageBps: -1},
+		"invalid_price_improvement": {Side: quotev1.Side_SIDE_BUY, Symbol: "BTC", Amount: 1, PriceImprovementBps: -1},
+	} {
+		_, err := client.GetQuote(ctx, req)
+		assert.Equal(t, code, reason(err))
+	}
+
+	// Streamed symbols are normalized, invalid ones are rejected on the
+	// first receive
+	stream, err := client.WatchBBO(ctx, &quotev1.WatchBBORequest{Symbols: []string{"btc", "$$$"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, "invalid_symbol", reason(err))
+
+	stream, err = client.WatchBBO(ctx, &quotev1.WatchBBORequest{Symbols: []string{"btc", "BTC-USD", "eth", "sol"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, "too_many_symbols", reason(err))
+}
+
+func TestSymbolInfo(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+		switch r.URL.Path {
+		case "/products/BTC-USD":
+			w.Write([]byte(`{"id":"BTC-USD","base_min_size":"0.0001","base_max_size":"200","base_increment":"0.00000001"}`))
+		case "/products/ETH-USD":
+			w.Write([]byte(`{"id":"ETH-USD","base_increment":"0.0001"}`))
+		case "/0/public/AssetPairs":
+			if r.URL.Query().Get("pair") != "BTCUSD" {
+				w.Write([]byte(`{"error":["EQuery:Unknown asset pair"]}`))
+				return
+			}
+			w.Write([]byte(`{"error":[],"result":{"XXBTZUSD":{"lot_decimals":8,"ordermin":"0.0001"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	coinbase := &exchange.CoinbaseExchange{BaseURL: server.URL}
+	kraken := &exchange.KrakenExchange{BaseURL: server.URL}
+
+	info, err := coinbase.GetSymbolInfo(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, exchange.SymbolInfo{MinSize: 0.0001, MaxSize: 200, LotSize: 0.00000001}, *info)
+
+	info, err = coinbase.GetSymbolInfo(ctx, "ETH")
+	require.NoError(t, err)
+	assert.Equal(t, exchange.SymbolInfo{LotSize: 0.0001}, *info)
+
+	_, err = coinbase.GetSymbolInfo(ctx, "NOPE")
+	assert.ErrorIs(t, err, exchange.ErrPairNotFound)
+
+	info, err = kraken.GetSymbolInfo(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, exchange.SymbolInfo{MinSize: 0.0001, LotSize: 1e-8}, *info)
+
+	_, err = kraken.GetSymbolInfo(ctx, "NOPE")
+	assert.ErrorIs(t, err, exchange.ErrPairNotFound)
+
+	// Symbols are escaped rather than interpolated into the upstream URLs
+	paths = nil
+	coinbase.GetOrderBook(ctx, "BTC/../x", 1)
+	kraken.GetOrderBook(ctx, "BTC&count=1000", 1)
+	assert.Equal(t, []string{
+		"/products/BTC%2F..%2Fx-USD/book?level=1",
+		"/0/public/Depth?pair=BTC%26count%3D1000USD&count=1",
+	}, paths)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	venuecontroller "github.com/SmMistry/triumph-project/controllers/venues"
+	"github.com/SmMistry/triumph-project/services/auth"
+	"github.com/SmMistry/triumph-project/services/exchange"
+	"github.com/SmMistry/triumph-project/services/logging"
+	"github.com/SmMistry/triumph-project/services/order"
+	"github.com/SmMistry/triumph-project/services/venues"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// venueBuilder returns a Builder creating mock exchanges priced by venue,
+// recording the timeouts it was asked for
+func venueBuilder(timeouts map[string]time.Duration) venues.Builder {
+	var mu sync.Mutex
+	return func(name string, timeout time.Duration) (exchange.Exchange, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		timeouts[name] = timeout
+		return &MockExchange{Name: name, BuyPrice: 9000, SellPrice: 8990}, nil
+	}
+}
+
+func TestVenueAdmin(t *testing.T) {
+	dir := t.TempDir()
+	keyStore, err := auth.Open(filepath.Join(dir, "keys.db"))
+	require.NoError(t, err)
+	defer keyStore.Close()
+	adminKey, adminToken, err := keyStore.Create("ops", []auth.Scope{auth.ScopeAdmin}, 0, 0)
+	require.NoError(t, err)
+	_, quoteToken, err := keyStore.Create("acme", []auth.Scope{auth.ScopeQuote}, 0, 0)
+	require.NoError(t, err)
+
+	venueStore, err := venues.Open(filepath.Join(dir, "venues.db"))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "info")
+	require.NoError(t, err)
+
+	newService := func() *order.OrderService {
+		return order.NewOrderService(
+			&MockExchange{Name: "coinbase", BuyPrice: 10010, SellPrice: 9990},
+			&MockExchange{Name: "kraken", BuyPrice: 10000, SellPrice: 9980},
+		)
+	}
+	configured := map[string]time.Duration{"coinbase": 10 * time.Second, "kraken": 10 * time.Second}
+	built := map[string]time.Duration{}
+
+	orderService := newService()
+	manager := venues.NewManager(orderService, venueBuilder(built), configured)
+	manager.SetLogger(logger)
+	require.NoError(t, manager.Restore(context.Background(), venueStore))
+
+	authenticator := auth.NewAuthenticator(keyStore)
+	venueController := venuecontroller.NewVenueController(manager)
+	app := fiber.New()
+	app.Use(logging.Middleware(logging.Discard()))
+	app.Get("/buy", authenticator.Require(auth.ScopeQuote), orders.NewOrderController(orderService).BuyHandler)
+	app.Get("/v1/admin/venues", authenticator.Require(auth.ScopeAdmin), venueController.ListHandler)
+	app.Patch("/v1/admin/venues/:name", authenticator.Require(auth.ScopeAdmin), venueController.UpdateHandler)
+
+	request := func(method, path, token, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(auth.KeyHeader, token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+	exchanges := func() []string {
+		status, body := request(http.MethodGet, "/buy?symbol=BTC&amount=1", quoteToken, "")
+		require.Equal(t, http.StatusOK, status, body)
+		var quote struct{ Exchange []string }
+		require.NoError(t, json.Unmarshal([]byte(body), &quote))
+		return quote.Exchange
+	}
+
+	// Only admins can change venues
+	status, _ := request(http.MethodPatch, "/v1/admin/venues/kraken", quoteToken, `{"enabled":false}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, []string{"kraken"}, exchanges())
+
+	// A disabled venue is no longer routed to, and the change is audited
+	status, body := request(http.MethodPatch, "/v1/admin/venues/Kraken", adminToken, `{"enabled":false}`)
+	require.Equal(t, http.StatusOK, status, body)
+	var updated struct{ Venue venues.Venue }
+	require.NoError(t, json.Unmarshal([]byte(body), &updated))
+	assert.Equal(t, "kraken", updated.Venue.Name)
+	assert.False(t, updated.Venue.Enabled)
+	assert.Equal(t, adminKey.ID, updated.Venue.UpdatedBy)
+	assert.Equal(t, []string{"coinbase"}, exchanges())
+
+	records := readRecords(t, &out)
+	require.Len(t, records, 1)
+	assert.Equal(t, "venue updated", records[0]["msg"])
+	assert.Equal(t, "kraken", records[0]["venue"])
+	assert.Equal(t, adminKey.ID, records[0]["updated_by"])
+	assert.Equal(t, false, records[0]["enabled"])
+	assert.Equal(t, true, records[0]["previous_enabled"])
+	assert.NotEmpty(t, records[0]["request_id"])
+
+	// The last enabled venue can't be disabled
+	status, body = request(http.MethodPatch, "/v1/admin/venues/coinbase", adminToken, `{"enabled":false}`)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.JSONEq(t, `{"error":"at least one venue must stay enabled","code":"last_venue"}`, body)
+
+	// A new timeout recreates the venue
+	status, body = request(http.MethodPatch, "/v1/admin/venues/kraken", adminToken, `{"enabled":true,"timeout":"1.5s"}`)
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, map[string]time.Duration{"kraken": 1500 * time.Millisecond}, built)
+	assert.Equal(t, []string{"kraken"}, exchanges())
+	status, body = request(http.MethodGet, "/buy?symbol=BTC&amount=1", quoteToken, "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"usdAmount":9000`)
+
+	for _, tc := range []struct {
+		name, body string
+		status     int
+		want       string
+	}{
+		{"binance", `{"enabled":true}`, http.StatusNotFound, `{"error":"venue not found: binance","code":"venue_not_found"}`},
+		{"kraken", `{"timeout":"soon"}`, http.StatusBadRequest, `{"error":"invalid timeout, expected a duration such as 3s","code":"invalid_timeout"}`},
+		{"kraken", `{"timeout":"-1s"}`, http.StatusBadRequest, `{"error":"timeout must be positive","code":"invalid_timeout"}`},
+	} {
+		status, body = request(http.MethodPatch, "/v1/admin/venues/"+tc.name, adminToken, tc.body)
+		assert.Equal(t, tc.status, status, tc.body)
+		assert.JSONEq(t, tc.want, body)
+	}
+
+	status, body = request(http.MethodGet, "/v1/admin/venues", adminToken, "")
+	require.Equal(t, http.StatusOK, status)
+	var list struct{ Venues []venues.Venue }
+	require.NoError(t, json.Unmarshal([]byte(body), &list))
+	require.Len(t, list.Venues, 2)
+	assert.Equal(t, venues.Venue{Name: "coinbase", Enabled: true, Timeout: "10s"}, list.Venues[0])
+	assert.Equal(t, "1.5s", list.Venues[1].Timeout)
+	assert.NotNil(t, list.Venues[1].UpdatedAt)
+
+	// Changes are restored after a restart
+	status, _ = request(http.MethodPatch, "/v1/admin/venues/kraken", adminToken, `{"enabled":false}`)
+	require.Equal(t, http.StatusOK, status)
+	require.NoError(t, venueStore.Close())
+
+	venueStore, err = venues.Open(filepath.Join(dir, "venues.db"))
+	require.NoError(t, err)
+	defer venueStore.Close()
+	restarted := map[string]time.Duration{}
+	orderService = newService()
+	manager = venues.NewManager(orderService, venueBuilder(restarted), configured)
+	require.NoError(t, manager.Restore(context.Background(), venueStore))
+
+	assert.Equal(t, map[string]time.Duration{"kraken": 1500 * time.Millisecond}, restarted)
+	assert.Equal(t, []order.VenueState{{Name: "coinbase", Enabled: true}, {Name: "kraken", Enabled: false}}, orderService.Venues())
+	assert.Equal(t, "1.5s", manager.List()[1].Timeout)
+}
+
+func TestVenueToggleDuringQuotes(t *testing.T) {
+	orderService := order.NewOrderService(
+		&MockExchange{Name: "coinbase", BuyPrice: 10010, SellPrice: 9990},
+		&MockExchange{Name: "kraken", BuyPrice: 10000, SellPrice: 9980},
+	)
+	manager := venues.NewManager(orderService, venueBuilder(map[string]time.Duration{}), map[string]time.Duration{})
+	ctx := context.Background()
+
+	// Quotes keep being served from whichever venues are enabled while kraken
+	// is toggled and reconfigured
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				quote, err := orderService.Quote(ctx, order.QuoteRequest{Side: order.SideBuy, Symbol: "BTC", Amount: 1})
+				if assert.NoError(t, err) {
+					assert.Len(t, quote.Exchanges, 1)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		enabled := i%2 == 1
+		timeout := time.Duration(i+1) * time.Millisecond
+		_, err := manager.Update(ctx, "kraken", venues.Change{Enabled: &enabled, Timeout: &timeout}, "test")
+		require.NoError(t, err)
+	}
+	close(stop)
+	wg.Wait()
+
+	assert.Equal(t, []order.VenueState{{Name: "coinbase", Enabled: true}, {Name: "kraken", Enabled: true}}, orderService.Venues())
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SmMistry/triumph-project/controllers/orders"
+	"github.com/SmMistry/triumph-project/services/order"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockCountingExchange is a mock exchange recording how often it was queried
+type MockCountingExchange struct {
+	MockExchange
+	Calls int
+}
+
+func (m *MockCountingExchange) GetPrices(ctx context.Context, symbol string) (float64, float64, error) {
+	m.Calls++
+	return m.MockExchange.GetPrices(ctx, symbol)
+}
+
+func TestBuyHandlerVenueRouting(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+		krakenQueried  bool
+	}{
+		{
+			name:           "Best price wins by default",
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":1,"coin":"BTC","exchange":["kraken"],"usdAmount":9990}`,
+			krakenQueried:  true,
+		},
+		{
+			name:           "Excluded venue is never queried",
+			query:          "&excludeVenues=kraken",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":1,"coin":"BTC","exchange":["coinbase"],"usdAmount":10000}`,
+		},
+		{
+			name:           "Included venues only",
+			query:          "&venues=Coinbase",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":1,"coin":"BTC","exchange":["coinbase"],"usdAmount":10000}`,
+		},
+		{
+			name:           "Preferred venue within the improvement threshold",
+			query:          "&preferredVenue=coinbase&priceImprovementBps=20",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":1,"coin":"BTC","exchange":["coinbase"],"usdAmount":10000}`,
+			krakenQueried:  true,
+		},
+		{
+			name:           "Preferred venue beaten by the improvement threshold",
+			query:          "&preferredVenue=coinbase",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"amount":1,"coin":"BTC","exchange":["kraken"],"usdAmount":9990}`,
+			krakenQueried:  true,
+		},
+		{
+			name:           "Unknown venue",
+			query:          "&venues=binance",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"unknown venue: binance","code":"unknown_venue"}`,
+		},
+		{
+			name:           "Every venue excluded",
+			query:          "&venues=kraken&excludeVenues=kraken",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"no venues left to route to","code":"no_venues"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coinbase := &MockCountingExchange{MockExchange: MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9980}}
+			kraken := &MockCountingExchange{MockExchange: MockExchange{Name: "kraken", BuyPrice: 9990, SellPrice: 9970}}
+
+			// Kraken is 10 bps better, enough to beat the 5 bps default
+			orderService := order.NewOrderService(coinbase, kraken)
+			orderService.SetPriceImprovementBps(5)
+
+			app := fiber.New()
+			orderController := orders.NewOrderController(orderService)
+			app.Get("/buy", orderController.BuyHandler)
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/buy?amount=1&symbol=BTC%s", tt.query), nil)
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+			assert.Equal(t, tt.krakenQueried, kraken.Calls > 0)
+		})
+	}
+}
+
+func TestBuyHandlerPreferredVenueTie(t *testing.T) {
+	coinbase := &MockExchange{Name: "coinbase", BuyPrice: 10000, SellPrice: 9980}
+	kraken := &MockExchange{Name: "kraken", BuyPrice: 10000, SellPrice: 9980}
+
+	// Without a threshold the preferred venue still wins a tie
+	orderService := order.NewOrderService(coinbase, kraken)
+	orderService.SetPriceImprovementBps(0)
+
+	app := fiber.New()
+	orderController := orders.NewOrderController(orderService)
+	app.Get("/buy", orderController.BuyHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/buy?amount=1&symbol=BTC&preferredVenue=kraken", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":1,"coin":"BTC","exchange":["kraken"],"usdAmount":10000}`, string(body))
+}